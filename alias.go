@@ -126,6 +126,10 @@ var (
 	// is terminated with exit code 1.
 	SetupSignalHandler = signals.SetupSignalHandler
 
+	// SetupSignalHandlerWithOptions is like SetupSignalHandler, with custom signals,
+	// additional shutdown sources and a pre-stop callback.
+	SetupSignalHandlerWithOptions = signals.SetupSignalHandlerWithOptions
+
 	// Log is the base logger used by controller-runtime.  It delegates
 	// to another logr.Logger.  You *must* call SetLogger to
 	// get any actual logging.
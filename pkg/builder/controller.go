@@ -17,11 +17,13 @@ limitations under the License.
 package builder
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
 	"github.com/go-logr/logr"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -52,13 +54,22 @@ const (
 // Builder builds a Controller.
 type Builder struct {
 	forInput         ForInput
+	alsoForInputs    []ForInput
 	ownsInput        []OwnsInput
 	watchesInput     []WatchesInput
+	rawSources       []rawSourceInput
 	mgr              manager.Manager
 	globalPredicates []predicate.Predicate
+	labelSelector    *metav1.LabelSelector
+	scopePredicates  []predicate.Predicate
 	ctrl             controller.Controller
 	ctrlOptions      controller.Options
 	name             string
+	finalizer        string
+	finalize         FinalizeFunc
+
+	// kinds records the kinds of the objects to reconcile, when AlsoFor is used
+	kinds *kindTracker
 }
 
 // ControllerManagedBy returns a new controller builder that will be started by the provided Manager.
@@ -76,6 +87,8 @@ type ForInput struct {
 
 // For defines the type of Object being *reconciled*, and configures the ControllerManagedBy to respond to create / delete /
 // update events by *reconciling the object*.
+// The object may be an *unstructured.Unstructured with its apiVersion and kind set, e.g. for a CRD unknown at
+// compile time; the reconciler then gets the objects as unstructured too.
 // This is the equivalent of calling
 // Watches(&source.Kind{Type: apiType}, &handler.EnqueueRequestForObject{}).
 func (blder *Builder) For(object client.Object, opts ...ForOption) *Builder {
@@ -92,16 +105,44 @@ func (blder *Builder) For(object client.Object, opts ...ForOption) *Builder {
 	return blder
 }
 
+// AlsoFor defines another type of Object being *reconciled* by the ControllerManagedBy along with the one
+// given to For, so that a single reconciler serves several related kinds, e.g. the versions of a CRD:
+//
+//  ControllerManagedBy(mgr).
+//    For(&v1.Database{}).
+//    AlsoFor(&v1alpha1.Database{}).
+//    Complete(r)
+//
+// The objects of every For type, and the objects they own, are reconciled by the same controller, and the
+// reconciler tells their kinds apart with GroupVersionKindFromContext. A Request only names an object, so
+// objects of different kinds with the same name are reconciled one after the other for the same Request.
+// The Requests enqueued by the handlers given to Watches and WatchesRawSource don't tell a kind, they are
+// reconciled once for each For type.
+func (blder *Builder) AlsoFor(object client.Object, opts ...ForOption) *Builder {
+	input := ForInput{object: object}
+	for _, opt := range opts {
+		opt.ApplyToFor(&input)
+	}
+
+	blder.alsoForInputs = append(blder.alsoForInputs, input)
+	return blder
+}
+
 // OwnsInput represents the information set by Owns method.
 type OwnsInput struct {
 	object           client.Object
 	predicates       []predicate.Predicate
 	objectProjection objectProjection
+	matchEveryOwner  bool
+	ownerAnnotation  string
+	indexOwners      bool
 }
 
 // Owns defines types of Objects being *generated* by the ControllerManagedBy, and configures the ControllerManagedBy to respond to
 // create / delete / update events by *reconciling the owner object*.  This is the equivalent of calling
 // Watches(&source.Kind{Type: <ForType-forInput>}, &handler.EnqueueRequestForOwner{OwnerType: apiType, IsController: true}).
+// Use the MatchEveryOwner option to reconcile every owner of the For type, not only the controller, or the
+// OwnedByAnnotation option for the owned objects referring to their owner with an annotation.
 func (blder *Builder) Owns(object client.Object, opts ...OwnsOption) *Builder {
 	input := OwnsInput{object: object}
 	for _, opt := range opts {
@@ -112,6 +153,29 @@ func (blder *Builder) Owns(object client.Object, opts ...OwnsOption) *Builder {
 	return blder
 }
 
+// OwnsAcrossNamespaces defines types of Objects being *generated* by the ControllerManagedBy in other namespaces
+// than their owner, like Owns. The owned objects refer to their owner with the annotation of the given key,
+// set by controllerutil.SetOwnerAnnotation, since an OwnerReference can't refer to another namespace.
+//
+// The owned objects are indexed in the cache of the manager by their owner, so that the reconciler lists the
+// objects a Request owns with:
+//
+//  c.List(ctx, &list, client.MatchingFields{builder.OwnerAnnotationIndexField(key): req.String()})
+//
+// The index is registered by Build, so a type and key can only be given to one builder of a manager.
+func (blder *Builder) OwnsAcrossNamespaces(object client.Object, key string, opts ...OwnsOption) *Builder {
+	blder.Owns(object, append(opts, OwnedByAnnotation(key))...)
+	blder.ownsInput[len(blder.ownsInput)-1].indexOwners = true
+	return blder
+}
+
+// OwnerAnnotationIndexField returns the field of the index, in the cache of the manager, of the objects given
+// to OwnsAcrossNamespaces with the given annotation key. The values of the index are the "<namespace>/<name>"
+// of the owners.
+func OwnerAnnotationIndexField(key string) string {
+	return "metadata.annotations.owner:" + key
+}
+
 // WatchesInput represents the information set by Watches method.
 type WatchesInput struct {
 	src              source.Source
@@ -133,6 +197,28 @@ func (blder *Builder) Watches(src source.Source, eventhandler handler.EventHandl
 	return blder
 }
 
+// rawSourceInput represents the information set by WatchesRawSource method.
+type rawSourceInput struct {
+	src          source.Source
+	eventhandler handler.EventHandler
+	predicates   []predicate.Predicate
+}
+
+// WatchesRawSource watches src as given, e.g. a source.Channel triggering reconciles
+// from an external system:
+//
+//  ControllerManagedBy(mgr).
+//    For(&appsv1.Deployment{}).
+//    WatchesRawSource(&source.Channel{Source: events}, &handler.EnqueueRequestForObject{}).
+//    Complete(r)
+//
+// Unlike Watches, it doesn't project a source.Kind, and neither the predicates set by
+// WithEventFilter nor WatchesOptions apply to src, only the given predicates.
+func (blder *Builder) WatchesRawSource(src source.Source, eventhandler handler.EventHandler, predicates ...predicate.Predicate) *Builder {
+	blder.rawSources = append(blder.rawSources, rawSourceInput{src: src, eventhandler: eventhandler, predicates: predicates})
+	return blder
+}
+
 // WithEventFilter sets the event filters, to filter which create/update/delete/generic events eventually
 // trigger reconciliations.  For example, filtering on whether the resource version has changed.
 // Given predicate is added for all watched objects.
@@ -142,6 +228,14 @@ func (blder *Builder) WithEventFilter(p predicate.Predicate) *Builder {
 	return blder
 }
 
+// WithLabelSelector scopes the controller to the objects matching selector, e.g. the objects of
+// a shard or of a tenant: the events of the objects of For and Owns which don't match selector
+// are ignored. The sources given to Watches and WatchesRawSource aren't filtered.
+func (blder *Builder) WithLabelSelector(selector metav1.LabelSelector) *Builder {
+	blder.labelSelector = &selector
+	return blder
+}
+
 // WithOptions overrides the controller options use in doController. Defaults to empty.
 func (blder *Builder) WithOptions(options controller.Options) *Builder {
 	blder.ctrlOptions = options
@@ -159,6 +253,8 @@ func (blder *Builder) WithLogger(log logr.Logger) *Builder {
 // (underscores and alphanumeric characters only).
 //
 // By default, controllers are named using the lowercase version of their kind.
+// Since the names of the controllers of a manager must be unique, the controllers
+// reconciling the same kind in a manager must be given distinct names with Named.
 func (blder *Builder) Named(name string) *Builder {
 	blder.name = name
 	return blder
@@ -185,6 +281,22 @@ func (blder *Builder) Build(r reconcile.Reconciler) (controller.Controller, erro
 	if blder.forInput.object == nil {
 		return nil, fmt.Errorf("must provide an object for reconciliation")
 	}
+	if err := blder.validateUnstructured(); err != nil {
+		return nil, err
+	}
+
+	if blder.labelSelector != nil {
+		selectorPredicate, err := predicate.LabelSelectorPredicate(*blder.labelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid label selector: %w", err)
+		}
+		blder.scopePredicates = []predicate.Predicate{selectorPredicate}
+	}
+
+	// Index the objects owned across namespaces
+	if err := blder.doIndex(); err != nil {
+		return nil, err
+	}
 
 	// Set the ControllerManagedBy
 	if err := blder.doController(r); err != nil {
@@ -199,6 +311,66 @@ func (blder *Builder) Build(r reconcile.Reconciler) (controller.Controller, erro
 	return blder.ctrl, nil
 }
 
+// validateUnstructured checks that the unstructured objects given to For, Owns and
+// Watches have a GVK, since it is the only way to know their kind.
+func (blder *Builder) validateUnstructured() error {
+	if err := validateUnstructured("For", blder.forInput.object); err != nil {
+		return err
+	}
+	for _, also := range blder.alsoForInputs {
+		if err := validateUnstructured("AlsoFor", also.object); err != nil {
+			return err
+		}
+	}
+	for _, own := range blder.ownsInput {
+		if err := validateUnstructured("Owns", own.object); err != nil {
+			return err
+		}
+	}
+	for _, w := range blder.watchesInput {
+		if srckind, ok := w.src.(*source.Kind); ok {
+			if err := validateUnstructured("Watches", srckind.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func validateUnstructured(method string, obj client.Object) error {
+	if _, ok := obj.(runtime.Unstructured); !ok {
+		return nil
+	}
+	gvk := obj.GetObjectKind().GroupVersionKind()
+	if gvk.Kind == "" || gvk.Version == "" {
+		return fmt.Errorf("the unstructured object passed to %s(...) must have its apiVersion and kind set, got %q", method, gvk.String())
+	}
+	return nil
+}
+
+func (blder *Builder) doIndex() error {
+	for _, own := range blder.ownsInput {
+		if !own.indexOwners {
+			continue
+		}
+		obj, err := blder.project(own.object, own.objectProjection)
+		if err != nil {
+			return err
+		}
+		key := own.ownerAnnotation
+		if err := blder.mgr.GetFieldIndexer().IndexField(context.Background(), obj, OwnerAnnotationIndexField(key), func(o client.Object) []string {
+			owner, ok := handler.OwnerFromAnnotation(o, key)
+			if !ok {
+				return nil
+			}
+			return []string{owner.String()}
+		}); err != nil {
+			return fmt.Errorf("failed to index the objects of type %T by their owner: %w", own.object, err)
+		}
+	}
+	return nil
+}
+
 func (blder *Builder) project(obj client.Object, proj objectProjection) (client.Object, error) {
 	switch proj {
 	case projectAsNormal:
@@ -217,36 +389,76 @@ func (blder *Builder) project(obj client.Object, proj objectProjection) (client.
 }
 
 func (blder *Builder) doWatch() error {
-	// Reconcile type
-	typeForSrc, err := blder.project(blder.forInput.object, blder.forInput.objectProjection)
+	for _, input := range blder.forInputs() {
+		// Reconcile type
+		if err := blder.watchFor(input); err != nil {
+			return err
+		}
+
+		// Watches the managed types
+		for _, own := range blder.ownsInput {
+			if err := blder.watchOwned(own, input); err != nil {
+				return err
+			}
+		}
+	}
+
+	return blder.doWatches()
+}
+
+// forInputs returns the inputs of For and AlsoFor.
+func (blder *Builder) forInputs() []ForInput {
+	return append([]ForInput{blder.forInput}, blder.alsoForInputs...)
+}
+
+// resourcePredicates returns the predicates of the events of the objects of For and Owns.
+func (blder *Builder) resourcePredicates() []predicate.Predicate {
+	allPredicates := append([]predicate.Predicate(nil), blder.globalPredicates...)
+	return append(allPredicates, blder.scopePredicates...)
+}
+
+// watchFor watches the objects of a For or AlsoFor call.
+func (blder *Builder) watchFor(input ForInput) error {
+	typeForSrc, err := blder.project(input.object, input.objectProjection)
 	if err != nil {
 		return err
 	}
 	src := &source.Kind{Type: typeForSrc}
-	hdler := &handler.EnqueueRequestForObject{}
-	allPredicates := append(blder.globalPredicates, blder.forInput.predicates...)
-	if err := blder.ctrl.Watch(src, hdler, allPredicates...); err != nil {
+	hdler, err := blder.withKind(&handler.EnqueueRequestForObject{}, input)
+	if err != nil {
 		return err
 	}
+	allPredicates := append(blder.resourcePredicates(), input.predicates...)
+	return blder.ctrl.Watch(src, hdler, allPredicates...)
+}
 
-	// Watches the managed types
-	for _, own := range blder.ownsInput {
-		typeForSrc, err := blder.project(own.object, own.objectProjection)
-		if err != nil {
-			return err
-		}
-		src := &source.Kind{Type: typeForSrc}
-		hdler := &handler.EnqueueRequestForOwner{
-			OwnerType:    blder.forInput.object,
-			IsController: true,
-		}
-		allPredicates := append([]predicate.Predicate(nil), blder.globalPredicates...)
-		allPredicates = append(allPredicates, own.predicates...)
-		if err := blder.ctrl.Watch(src, hdler, allPredicates...); err != nil {
-			return err
-		}
+// watchOwned watches the objects of an Owns call, enqueueing their owners of the type of the For or AlsoFor input.
+func (blder *Builder) watchOwned(own OwnsInput, owner ForInput) error {
+	if own.ownerAnnotation != "" && len(blder.alsoForInputs) > 0 {
+		return fmt.Errorf("the owner annotation of the owned objects of type %T doesn't tell the kind of their owner, it can't be used along with AlsoFor", own.object)
+	}
+	typeForSrc, err := blder.project(own.object, own.objectProjection)
+	if err != nil {
+		return err
+	}
+	src := &source.Kind{Type: typeForSrc}
+	var ownerOpts []handler.OwnerOption
+	if !own.matchEveryOwner {
+		ownerOpts = append(ownerOpts, handler.OnlyControllerOwner())
+	}
+	var hdler handler.EventHandler = handler.NewEnqueueRequestForOwner(owner.object, ownerOpts...)
+	if own.ownerAnnotation != "" {
+		hdler = handler.EnqueueRequestForAnnotationOwner(own.ownerAnnotation)
+	}
+	hdler, err = blder.withKind(hdler, owner)
+	if err != nil {
+		return err
 	}
+	allPredicates := append(blder.resourcePredicates(), own.predicates...)
+	return blder.ctrl.Watch(src, hdler, allPredicates...)
+}
 
+func (blder *Builder) doWatches() error {
 	// Do the watch requests
 	for _, w := range blder.watchesInput {
 		allPredicates := append([]predicate.Predicate(nil), blder.globalPredicates...)
@@ -259,12 +471,21 @@ func (blder *Builder) doWatch() error {
 				return err
 			}
 			srckind.Type = typeForSrc
+		} else if w.objectProjection != projectAsNormal {
+			return fmt.Errorf("cannot project the objects of the source %v of type %T: only a *source.Kind can be watched as metadata", w.src, w.src)
 		}
 
 		if err := blder.ctrl.Watch(w.src, w.eventhandler, allPredicates...); err != nil {
 			return err
 		}
 	}
+
+	// Watch the raw sources as given
+	for _, raw := range blder.rawSources {
+		if err := blder.ctrl.Watch(raw.src, raw.eventhandler, raw.predicates...); err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -282,6 +503,20 @@ func (blder *Builder) doController(r reconcile.Reconciler) error {
 	if ctrlOptions.Reconciler == nil {
 		ctrlOptions.Reconciler = r
 	}
+	if len(blder.alsoForInputs) > 0 {
+		blder.kinds = newKindTracker()
+		kindR, err := blder.newKindReconciler(ctrlOptions.Reconciler)
+		if err != nil {
+			return err
+		}
+		ctrlOptions.Reconciler = kindR
+	} else {
+		forR, err := blder.reconcilerFor(ctrlOptions.Reconciler, blder.forInput)
+		if err != nil {
+			return err
+		}
+		ctrlOptions.Reconciler = forR
+	}
 
 	// Retrieve the GVK from the object we're reconciling
 	// to prepopulate logger information, and to optionally generate a default name.
@@ -314,3 +549,12 @@ func (blder *Builder) doController(r reconcile.Reconciler) error {
 	blder.ctrl, err = newController(blder.getControllerName(gvk), blder.mgr, ctrlOptions)
 	return err
 }
+
+// reconcilerFor returns r managing the finalizer of the builder, if any, for the objects of the
+// given For or AlsoFor input.
+func (blder *Builder) reconcilerFor(r reconcile.Reconciler, input ForInput) (reconcile.Reconciler, error) {
+	if blder.finalizer == "" {
+		return r, nil
+	}
+	return blder.newFinalizingReconciler(r, input)
+}
@@ -28,6 +28,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
@@ -131,6 +132,39 @@ var _ = Describe("application", func() {
 			// manifest when we try to default the controller name, which is good to double check.
 		})
 
+		It("should return an error if an unstructured object has no GVK", func() {
+			By("creating a controller manager")
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			owned := &unstructured.Unstructured{}
+			owned.SetKind("ReplicaSet")
+			instance, err := ControllerManagedBy(m).
+				For(&appsv1.Deployment{}).
+				Owns(owned).
+				Build(noop)
+			Expect(err).To(MatchError(ContainSubstring("the unstructured object passed to Owns(...) must have its apiVersion and kind set")))
+			Expect(instance).To(BeNil())
+		})
+
+		It("should build a controller for an unstructured object with a GVK", func() {
+			By("creating a controller manager")
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			deploy := &unstructured.Unstructured{}
+			deploy.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"})
+			owned := &unstructured.Unstructured{}
+			owned.SetGroupVersionKind(schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "ReplicaSet"})
+			instance, err := ControllerManagedBy(m).
+				Named("unstructured-deployment").
+				For(deploy).
+				Owns(owned).
+				Build(noop)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance).NotTo(BeNil())
+		})
+
 		It("should return an error if it cannot create the controller", func() {
 			newController = func(name string, mgr manager.Manager, options controller.Options) (
 				controller.Controller, error) {
@@ -288,11 +322,61 @@ var _ = Describe("application", func() {
 
 			By("creating the 2nd controller")
 			ctrl2, err := ControllerManagedBy(m).
+				Named("testdefaultvalidator-2").
 				For(&TestDefaultValidator{}).
 				Owns(&appsv1.ReplicaSet{}).
 				Build(noop)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(ctrl2).NotTo(BeNil())
+
+			By("failing to create a 3rd controller with the name of the 1st one")
+			_, err = ControllerManagedBy(m).
+				For(&TestDefaultValidator{}).
+				Owns(&appsv1.ReplicaSet{}).
+				Build(noop)
+			Expect(err).To(MatchError(ContainSubstring("was already added to the manager")))
+		})
+	})
+
+	Describe("WithLabelSelector", func() {
+		It("should filter the events of the For and Owns objects by their labels", func() {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			recorder := &watchRecorder{}
+			newController = func(name string, mgr manager.Manager, options controller.Options) (controller.Controller, error) {
+				recorder.Controller, err = controller.NewUnmanaged(name, mgr, options)
+				return recorder, err
+			}
+
+			_, err = ControllerManagedBy(m).
+				Named("label-selector").
+				For(&appsv1.Deployment{}).
+				Owns(&appsv1.ReplicaSet{}).
+				Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestForObject{}).
+				WithLabelSelector(metav1.LabelSelector{MatchLabels: map[string]string{"shard": "a"}}).
+				Build(noop)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.predicates).To(HaveLen(3))
+
+			inShard := event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"shard": "a"}}}}
+			outOfShard := event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"shard": "b"}}}}
+			for i, expectFiltered := range []bool{true, true, false} {
+				Expect(predicatesAccept(recorder.predicates[i], inShard)).To(BeTrue())
+				Expect(predicatesAccept(recorder.predicates[i], outOfShard)).To(Equal(!expectFiltered))
+			}
+		})
+
+		It("should return an error for an invalid selector", func() {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = ControllerManagedBy(m).
+				Named("invalid-label-selector").
+				For(&appsv1.Deployment{}).
+				WithLabelSelector(metav1.LabelSelector{MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "shard", Operator: "Bogus"}}}).
+				Build(noop)
+			Expect(err).To(MatchError(ContainSubstring("invalid label selector")))
 		})
 	})
 
@@ -328,6 +412,178 @@ var _ = Describe("application", func() {
 		}, 10)
 	})
 
+	Describe("AlsoFor", func() {
+		It("should Reconcile the objects of every For type with their GroupVersionKind in the context", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ch := make(chan schema.GroupVersionKind)
+			Expect(ControllerManagedBy(m).
+				Named("also-for").
+				For(&appsv1.Deployment{}).
+				AlsoFor(&corev1.ConfigMap{}).
+				Complete(reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+					if req.Name == "also-for-11" {
+						gvk, ok := GroupVersionKindFromContext(ctx)
+						Expect(ok).To(BeTrue())
+						ch <- gvk
+					}
+					return reconcile.Result{}, nil
+				}))).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).To(Succeed())
+			}()
+
+			By("Creating a ConfigMap")
+			cm := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "also-for-11"}}
+			Expect(m.GetClient().Create(ctx, cm)).To(Succeed())
+
+			By("Waiting for the ConfigMap Reconcile")
+			Eventually(ch).Should(Receive(Equal(schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"})))
+			close(done)
+		}, 10)
+	})
+
+	Describe("Owns with MatchEveryOwner", func() {
+		It("should Reconcile the owners which are not the controller", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			ch := make(chan reconcile.Request)
+			Expect(ControllerManagedBy(m).
+				Named("match-every-owner").
+				For(&appsv1.Deployment{}).
+				Owns(&appsv1.ReplicaSet{}, MatchEveryOwner).
+				Complete(reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+					if req.Name == "owner-10" {
+						ch <- req
+					}
+					return reconcile.Result{}, nil
+				}))).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).To(Succeed())
+			}()
+
+			By("Creating a ReplicaSet with a plain owner reference")
+			rs := &appsv1.ReplicaSet{
+				ObjectMeta: metav1.ObjectMeta{
+					Namespace: "default",
+					Name:      "rs-name-10",
+					Labels:    map[string]string{"foo": "bar"},
+					OwnerReferences: []metav1.OwnerReference{{
+						Name:       "owner-10",
+						Kind:       "Deployment",
+						APIVersion: "apps/v1",
+						UID:        "a4f7d5c2-0a0c-4b3a-9f9e-1b2c3d4e5f60",
+					}},
+				},
+				Spec: appsv1.ReplicaSetSpec{
+					Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"foo": "bar"}},
+					Template: corev1.PodTemplateSpec{
+						ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{"foo": "bar"}},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{{Name: "nginx", Image: "nginx"}},
+						},
+					},
+				},
+			}
+			Expect(m.GetClient().Create(ctx, rs)).To(Succeed())
+
+			By("Waiting for the owner Reconcile")
+			Eventually(ch).Should(Receive(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "default", Name: "owner-10"}})))
+			close(done)
+		}, 10)
+	})
+
+	Describe("OwnsAcrossNamespaces", func() {
+		It("should Reconcile the owners of the objects in other namespaces and index them", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			const key = "example.com/owner"
+			ch := make(chan reconcile.Request)
+			Expect(ControllerManagedBy(m).
+				Named("owns-across-namespaces").
+				For(&appsv1.Deployment{}).
+				OwnsAcrossNamespaces(&corev1.ConfigMap{}, key).
+				Complete(reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+					if req.Name == "owner-12" {
+						ch <- req
+					}
+					return reconcile.Result{}, nil
+				}))).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).To(Succeed())
+			}()
+
+			By("Creating a ConfigMap owned by a Deployment of another namespace")
+			owned := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{
+				Namespace:   "kube-public",
+				Name:        "owned-12",
+				Annotations: map[string]string{key: "default/owner-12"},
+			}}
+			Expect(m.GetClient().Create(ctx, owned)).To(Succeed())
+
+			By("Waiting for the owner Reconcile")
+			owner := types.NamespacedName{Namespace: "default", Name: "owner-12"}
+			Eventually(ch).Should(Receive(Equal(reconcile.Request{NamespacedName: owner})))
+
+			By("Listing the objects of the owner with the index")
+			list := &corev1.ConfigMapList{}
+			Expect(m.GetClient().List(ctx, list, client.MatchingFields{OwnerAnnotationIndexField(key): owner.String()})).To(Succeed())
+			Expect(list.Items).To(HaveLen(1))
+			Expect(list.Items[0].Name).To(Equal("owned-12"))
+			close(done)
+		}, 10)
+	})
+
+	Describe("WatchesRawSource", func() {
+		It("should Reconcile the requests of a raw source, without the event filters", func(done Done) {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			events := make(chan event.GenericEvent)
+			bldr := ControllerManagedBy(m).
+				Named("raw-source").
+				For(&appsv1.Deployment{}).
+				WithEventFilter(predicate.Funcs{GenericFunc: func(event.GenericEvent) bool { return false }}).
+				WatchesRawSource(&source.Channel{Source: events}, &handler.EnqueueRequestForObject{})
+
+			ch := make(chan reconcile.Request)
+			Expect(bldr.Complete(reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				if req.Name == "raw-9" {
+					ch <- req
+				}
+				return reconcile.Result{}, nil
+			}))).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).To(Succeed())
+			}()
+
+			events <- event.GenericEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "raw-9"}}}
+			Eventually(ch).Should(Receive(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "default", Name: "raw-9"}})))
+			close(done)
+		}, 10)
+	})
+
 	Describe("Set custom predicates", func() {
 		It("should execute registered predicates only for assigned kind", func(done Done) {
 			m, err := manager.New(cfg, manager.Options{})
@@ -400,6 +656,16 @@ var _ = Describe("application", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 
+		It("should return an error for a metadata-only watch of a source other than a Kind", func() {
+			instance, err := ControllerManagedBy(mgr).
+				Named("metadata-channel").
+				For(&appsv1.Deployment{}, OnlyMetadata).
+				Watches(&source.Channel{Source: make(chan event.GenericEvent)}, &handler.EnqueueRequestForObject{}, OnlyMetadata).
+				Build(noop)
+			Expect(err).To(MatchError(ContainSubstring("only a *source.Kind can be watched as metadata")))
+			Expect(instance).To(BeNil())
+		})
+
 		It("should support watching For, Owns, and Watch as metadata", func() {
 			statefulSetMaps := make(chan *metav1.PartialObjectMetadata)
 
@@ -605,3 +871,23 @@ type fakeType struct {
 
 func (*fakeType) GetObjectKind() schema.ObjectKind { return nil }
 func (*fakeType) DeepCopyObject() runtime.Object   { return nil }
+
+// watchRecorder records the predicates of the watches of a controller.
+type watchRecorder struct {
+	controller.Controller
+	predicates [][]predicate.Predicate
+}
+
+func (w *watchRecorder) Watch(src source.Source, eventhandler handler.EventHandler, predicates ...predicate.Predicate) error {
+	w.predicates = append(w.predicates, predicates)
+	return w.Controller.Watch(src, eventhandler, predicates...)
+}
+
+func predicatesAccept(predicates []predicate.Predicate, evt event.CreateEvent) bool {
+	for _, p := range predicates {
+		if !p.Create(evt) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// FinalizeFunc cleans up what an object being deleted holds, e.g. external resources, before
+// its finalizer is removed.
+type FinalizeFunc func(ctx context.Context, obj client.Object) error
+
+// WithFinalizer makes the controller manage the given finalizer on the objects of the For types:
+// the finalizer is added to the objects before they are reconciled, and once an object is being
+// deleted, finalize is called instead of the reconciler, and the finalizer is removed when it
+// succeeds. An error of finalize is retried with backoff, like the errors of the reconciler.
+func (blder *Builder) WithFinalizer(finalizer string, finalize FinalizeFunc) *Builder {
+	blder.finalizer = finalizer
+	blder.finalize = finalize
+	return blder
+}
+
+// newFinalizingReconciler returns a reconciler managing the finalizer of the builder around r,
+// for the objects of the given For or AlsoFor input.
+func (blder *Builder) newFinalizingReconciler(r reconcile.Reconciler, input ForInput) (reconcile.Reconciler, error) {
+	if blder.finalize == nil {
+		return nil, fmt.Errorf("must provide a non-nil FinalizeFunc for the finalizer %q", blder.finalizer)
+	}
+	obj, err := blder.project(input.object, input.objectProjection)
+	if err != nil {
+		return nil, err
+	}
+	return &finalizingReconciler{
+		Reconciler: r,
+		client:     blder.mgr.GetClient(),
+		finalizer:  blder.finalizer,
+		finalize:   blder.finalize,
+		object:     obj,
+	}, nil
+}
+
+// finalizingReconciler adds a finalizer to the objects it reconciles, and finalizes them
+// once they are being deleted.
+type finalizingReconciler struct {
+	reconcile.Reconciler
+
+	client    client.Client
+	finalizer string
+	finalize  FinalizeFunc
+
+	// object is the type of the objects reconciled
+	object client.Object
+}
+
+var _ inject.Injector = &finalizingReconciler{}
+
+// InjectFunc implements inject.Injector, the fields are injected into the wrapped reconciler.
+func (r *finalizingReconciler) InjectFunc(f inject.Func) error {
+	if f == nil {
+		return nil
+	}
+	return f(r.Reconciler)
+}
+
+// Reconcile implements reconcile.Reconciler.
+func (r *finalizingReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	obj := r.object.DeepCopyObject().(client.Object)
+	if err := r.client.Get(ctx, req.NamespacedName, obj); err != nil {
+		return reconcile.Result{}, client.IgnoreNotFound(err)
+	}
+
+	if obj.GetDeletionTimestamp().IsZero() {
+		if !controllerutil.ContainsFinalizer(obj, r.finalizer) {
+			patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+			controllerutil.AddFinalizer(obj, r.finalizer)
+			if err := r.client.Patch(ctx, obj, patch); err != nil {
+				return reconcile.Result{}, fmt.Errorf("failed to add the finalizer %q: %w", r.finalizer, err)
+			}
+		}
+		return r.Reconciler.Reconcile(ctx, req)
+	}
+
+	if !controllerutil.ContainsFinalizer(obj, r.finalizer) {
+		return reconcile.Result{}, nil
+	}
+	if err := r.finalize(ctx, obj); err != nil {
+		return reconcile.Result{}, err
+	}
+	patch := client.MergeFromWithOptions(obj.DeepCopyObject().(client.Object), client.MergeFromWithOptimisticLock{})
+	controllerutil.RemoveFinalizer(obj, r.finalizer)
+	if err := r.client.Patch(ctx, obj, patch); err != nil {
+		return reconcile.Result{}, fmt.Errorf("failed to remove the finalizer %q: %w", r.finalizer, err)
+	}
+	return reconcile.Result{}, nil
+}
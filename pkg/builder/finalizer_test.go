@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("finalizingReconciler", func() {
+	const finalizer = "example.com/cleanup"
+	var (
+		ctx         = context.Background()
+		key         = types.NamespacedName{Namespace: "default", Name: "deploy"}
+		reconciled  []reconcile.Request
+		finalized   []client.Object
+		finalizeErr error
+		cl          client.Client
+		r           *finalizingReconciler
+	)
+
+	newReconciler := func(objs ...client.Object) {
+		reconciled, finalized, finalizeErr = nil, nil, nil
+		cl = fake.NewClientBuilder().WithObjects(objs...).Build()
+		r = &finalizingReconciler{
+			Reconciler: reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				reconciled = append(reconciled, req)
+				return reconcile.Result{}, nil
+			}),
+			client:    cl,
+			finalizer: finalizer,
+			finalize: func(_ context.Context, obj client.Object) error {
+				finalized = append(finalized, obj)
+				return finalizeErr
+			},
+			object: &appsv1.Deployment{},
+		}
+	}
+
+	It("should add the finalizer before reconciling an object", func() {
+		newReconciler(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Namespace: key.Namespace, Name: key.Name}})
+
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciled).To(HaveLen(1))
+		Expect(finalized).To(BeEmpty())
+
+		deploy := &appsv1.Deployment{}
+		Expect(cl.Get(ctx, key, deploy)).To(Succeed())
+		Expect(deploy.Finalizers).To(ConsistOf(finalizer))
+	})
+
+	It("should finalize an object being deleted and remove its finalizer", func() {
+		now := metav1.Now()
+		newReconciler(&appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{
+			Namespace: key.Namespace, Name: key.Name, DeletionTimestamp: &now, Finalizers: []string{finalizer, "other"},
+		}})
+
+		By("retrying when finalizing fails")
+		finalizeErr = fmt.Errorf("expected error")
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).To(MatchError("expected error"))
+		deploy := &appsv1.Deployment{}
+		Expect(cl.Get(ctx, key, deploy)).To(Succeed())
+		Expect(deploy.Finalizers).To(ConsistOf(finalizer, "other"))
+
+		By("removing the finalizer once finalizing succeeds")
+		finalizeErr = nil
+		_, err = r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(finalized).To(HaveLen(2))
+		Expect(reconciled).To(BeEmpty())
+		Expect(cl.Get(ctx, key, deploy)).To(Succeed())
+		Expect(deploy.Finalizers).To(ConsistOf("other"))
+	})
+
+	It("should ignore the objects which don't exist", func() {
+		newReconciler()
+		_, err := r.Reconcile(ctx, reconcile.Request{NamespacedName: key})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciled).To(BeEmpty())
+	})
+})
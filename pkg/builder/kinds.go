@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// groupVersionKindKey is the key of the GroupVersionKind in the context of a reconcile.
+type groupVersionKindKey struct{}
+
+// GroupVersionKindFromContext returns the GroupVersionKind of the object to reconcile, when the
+// context was passed to the reconciler of a builder given types with AlsoFor.
+func GroupVersionKindFromContext(ctx context.Context) (schema.GroupVersionKind, bool) {
+	gvk, ok := ctx.Value(groupVersionKindKey{}).(schema.GroupVersionKind)
+	return gvk, ok
+}
+
+// kindTracker records the kinds of the objects to reconcile for the Requests of a controller
+// reconciling several kinds, since a Request only names the object.
+type kindTracker struct {
+	mu      sync.Mutex
+	pending map[reconcile.Request][]schema.GroupVersionKind
+}
+
+func newKindTracker() *kindTracker {
+	return &kindTracker{pending: map[reconcile.Request][]schema.GroupVersionKind{}}
+}
+
+// add records that the object of kind gvk named by req is to be reconciled.
+func (t *kindTracker) add(req reconcile.Request, gvk schema.GroupVersionKind) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, pending := range t.pending[req] {
+		if pending == gvk {
+			return
+		}
+	}
+	t.pending[req] = append(t.pending[req], gvk)
+}
+
+// take returns the kinds of the objects named by req to reconcile, and forgets them.
+func (t *kindTracker) take(req reconcile.Request) []schema.GroupVersionKind {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	kinds := t.pending[req]
+	delete(t.pending, req)
+	return kinds
+}
+
+// kindHandler records the kind of the objects named by the Requests its handler enqueues.
+type kindHandler struct {
+	handler.EventHandler
+
+	gvk   schema.GroupVersionKind
+	kinds *kindTracker
+}
+
+var _ handler.EventHandler = &kindHandler{}
+var _ inject.Injector = &kindHandler{}
+
+// Create implements handler.EventHandler.
+func (h *kindHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Create(evt, h.queue(q))
+}
+
+// Update implements handler.EventHandler.
+func (h *kindHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Update(evt, h.queue(q))
+}
+
+// Delete implements handler.EventHandler.
+func (h *kindHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Delete(evt, h.queue(q))
+}
+
+// Generic implements handler.EventHandler.
+func (h *kindHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Generic(evt, h.queue(q))
+}
+
+// InjectFunc implements inject.Injector, the fields are injected into the wrapped handler.
+func (h *kindHandler) InjectFunc(f inject.Func) error {
+	if f == nil {
+		return nil
+	}
+	return f(h.EventHandler)
+}
+
+func (h *kindHandler) queue(q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	return &kindQueue{RateLimitingInterface: q, handler: h}
+}
+
+// kindQueue records the kind of the handler of the Requests added to it, before adding them.
+type kindQueue struct {
+	workqueue.RateLimitingInterface
+	handler *kindHandler
+}
+
+func (q *kindQueue) record(item interface{}) {
+	if req, ok := item.(reconcile.Request); ok {
+		q.handler.kinds.add(req, q.handler.gvk)
+	}
+}
+
+func (q *kindQueue) Add(item interface{}) {
+	q.record(item)
+	q.RateLimitingInterface.Add(item)
+}
+
+func (q *kindQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.record(item)
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+func (q *kindQueue) AddRateLimited(item interface{}) {
+	q.record(item)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
+
+// kindReconciler reconciles a Request once for each kind of object recorded for it, passing
+// the kind in the context. The Requests enqueued by the handlers which don't record a kind,
+// e.g. the ones given to Watches, are reconciled for every kind.
+type kindReconciler struct {
+	kinds *kindTracker
+
+	// gvks are the kinds reconciled, in the order of the For and AlsoFor calls
+	gvks        []schema.GroupVersionKind
+	reconcilers map[schema.GroupVersionKind]reconcile.Reconciler
+}
+
+var _ inject.Injector = &kindReconciler{}
+
+// InjectFunc implements inject.Injector, the fields are injected into the reconcilers of each kind.
+func (r *kindReconciler) InjectFunc(f inject.Func) error {
+	if f == nil {
+		return nil
+	}
+	for _, gvk := range r.gvks {
+		if err := f(r.reconcilers[gvk]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Reconcile implements reconcile.Reconciler.
+func (r *kindReconciler) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+	gvks := r.kinds.take(req)
+	if len(gvks) == 0 {
+		gvks = r.gvks
+	}
+
+	var requeue bool
+	var requeueAfter time.Duration
+	for i, gvk := range gvks {
+		result, err := r.reconcilers[gvk].Reconcile(context.WithValue(ctx, groupVersionKindKey{}, gvk), req)
+		if err != nil {
+			// the kinds left are reconciled again when the Request is retried
+			for _, left := range gvks[i:] {
+				r.kinds.add(req, left)
+			}
+			return result, err
+		}
+		switch {
+		case result.RequeueAfter > 0:
+			r.kinds.add(req, gvk)
+			if requeueAfter == 0 || result.RequeueAfter < requeueAfter {
+				requeueAfter = result.RequeueAfter
+			}
+		case result.Requeue:
+			r.kinds.add(req, gvk)
+			requeue = true
+		}
+	}
+	// the Request is requeued as soon as one of the kinds needs it
+	if requeue {
+		return reconcile.Result{Requeue: true}, nil
+	}
+	return reconcile.Result{RequeueAfter: requeueAfter}, nil
+}
+
+// newKindReconciler returns a reconciler passing the kind of the objects to reconcile to r, for
+// the objects of the For and AlsoFor types.
+func (blder *Builder) newKindReconciler(r reconcile.Reconciler) (*kindReconciler, error) {
+	kr := &kindReconciler{
+		kinds:       blder.kinds,
+		reconcilers: map[schema.GroupVersionKind]reconcile.Reconciler{},
+	}
+	for _, input := range blder.forInputs() {
+		gvk, err := getGvk(input.object, blder.mgr.GetScheme())
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := kr.reconcilers[gvk]; ok {
+			return nil, fmt.Errorf("the objects of kind %s are already reconciled, they can't be given to AlsoFor(...)", gvk)
+		}
+		kindR, err := blder.reconcilerFor(r, input)
+		if err != nil {
+			return nil, err
+		}
+		kr.gvks = append(kr.gvks, gvk)
+		kr.reconcilers[gvk] = kindR
+	}
+	return kr, nil
+}
+
+// withKind returns h recording the kind of the objects of input, if the builder reconciles several kinds.
+func (blder *Builder) withKind(h handler.EventHandler, input ForInput) (handler.EventHandler, error) {
+	if blder.kinds == nil {
+		return h, nil
+	}
+	gvk, err := getGvk(input.object, blder.mgr.GetScheme())
+	if err != nil {
+		return nil, err
+	}
+	return &kindHandler{EventHandler: h, gvk: gvk, kinds: blder.kinds}, nil
+}
@@ -0,0 +1,116 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package builder
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
+
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+var _ = Describe("kindReconciler", func() {
+	var (
+		ctx        = context.Background()
+		req        = reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "db"}}
+		v1         = schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Database"}
+		v1alpha1   = schema.GroupVersionKind{Group: "example.com", Version: "v1alpha1", Kind: "Database"}
+		results    map[schema.GroupVersionKind]reconcile.Result
+		errs       map[schema.GroupVersionKind]error
+		reconciled []schema.GroupVersionKind
+		r          *kindReconciler
+	)
+
+	BeforeEach(func() {
+		results = map[schema.GroupVersionKind]reconcile.Result{}
+		errs = map[schema.GroupVersionKind]error{}
+		reconciled = nil
+		inner := reconcile.Func(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+			gvk, ok := GroupVersionKindFromContext(ctx)
+			Expect(ok).To(BeTrue())
+			reconciled = append(reconciled, gvk)
+			return results[gvk], errs[gvk]
+		})
+		r = &kindReconciler{
+			kinds:       newKindTracker(),
+			gvks:        []schema.GroupVersionKind{v1, v1alpha1},
+			reconcilers: map[schema.GroupVersionKind]reconcile.Reconciler{v1: inner, v1alpha1: inner},
+		}
+	})
+
+	It("should only reconcile the kinds recorded for a Request", func() {
+		r.kinds.add(req, v1alpha1)
+		r.kinds.add(req, v1alpha1)
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciled).To(Equal([]schema.GroupVersionKind{v1alpha1}))
+	})
+
+	It("should reconcile every kind for a Request without a kind", func() {
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(reconciled).To(Equal([]schema.GroupVersionKind{v1, v1alpha1}))
+	})
+
+	It("should record the kinds left to reconcile when a kind fails", func() {
+		errs[v1] = errors.New("expected error")
+		_, err := r.Reconcile(ctx, req)
+		Expect(err).To(MatchError("expected error"))
+		Expect(reconciled).To(Equal([]schema.GroupVersionKind{v1}))
+		Expect(r.kinds.take(req)).To(Equal([]schema.GroupVersionKind{v1, v1alpha1}))
+	})
+
+	It("should requeue the kinds which need it as soon as one of them needs it", func() {
+		results[v1] = reconcile.Result{RequeueAfter: time.Minute}
+		result, err := r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(reconcile.Result{RequeueAfter: time.Minute}))
+		Expect(r.kinds.take(req)).To(Equal([]schema.GroupVersionKind{v1}))
+
+		results[v1alpha1] = reconcile.Result{Requeue: true}
+		result, err = r.Reconcile(ctx, req)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result).To(Equal(reconcile.Result{Requeue: true}))
+		Expect(r.kinds.take(req)).To(Equal([]schema.GroupVersionKind{v1, v1alpha1}))
+	})
+})
+
+var _ = Describe("kindHandler", func() {
+	It("should record the kind of the Requests before adding them", func() {
+		gvk := corev1.SchemeGroupVersion.WithKind("ConfigMap")
+		kinds := newKindTracker()
+		h := &kindHandler{EventHandler: &handler.EnqueueRequestForObject{}, gvk: gvk, kinds: kinds}
+		q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+		defer q.ShutDown()
+
+		h.Create(event.CreateEvent{Object: &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "cm"}}}, q)
+		req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "cm"}}
+		Expect(q.Len()).To(Equal(1))
+		Expect(kinds.take(req)).To(Equal([]schema.GroupVersionKind{gvk}))
+	})
+})
@@ -77,6 +77,42 @@ var _ WatchesOption = &Predicates{}
 
 // }}}
 
+// {{{ Owns options
+
+// matchEveryOwner configures the owns request to enqueue for every owner reference.
+type matchEveryOwner struct{}
+
+// ApplyToOwns applies this configuration to the given OwnsInput options.
+func (o matchEveryOwner) ApplyToOwns(opts *OwnsInput) {
+	opts.matchEveryOwner = true
+}
+
+var (
+	// MatchEveryOwner makes Owns reconcile every owner of the For type of an owned
+	// object, not only the one of its controller reference. It is useful when the
+	// owned objects are created by other tools that set plain owner references.
+	MatchEveryOwner = matchEveryOwner{}
+
+	_ OwnsOption = MatchEveryOwner
+)
+
+// OwnedByAnnotation makes Owns reconcile the owner an owned object refers to with the
+// annotation of the given key, set by controllerutil.SetOwnerAnnotation, instead of
+// its OwnerReferences; e.g. for owners in another namespace, which can't be set in
+// the OwnerReferences of an object.
+func OwnedByAnnotation(key string) OwnsOption {
+	return ownedByAnnotation(key)
+}
+
+type ownedByAnnotation string
+
+// ApplyToOwns applies this configuration to the given OwnsInput options.
+func (o ownedByAnnotation) ApplyToOwns(opts *OwnsInput) {
+	opts.ownerAnnotation = string(o)
+}
+
+// }}}
+
 // {{{ For & Owns Dual-Type options
 
 // asProjection configures the projection (currently only metadata) on the input.
@@ -107,6 +143,10 @@ var (
 	// metav1.PartialObjectMetadata to the client when fetching objects in your
 	// reconciler, otherwise you'll end up with a duplicate structured or
 	// unstructured cache.
+	//
+	// The handlers and predicates of the watch receive the objects as
+	// *metav1.PartialObjectMetadata, with their GVK set. With Watches, it only
+	// applies to a *source.Kind; Build returns an error for other sources.
 	OnlyMetadata = projectAs(projectAsMetadata)
 
 	_ ForOption     = OnlyMetadata
@@ -17,6 +17,7 @@ limitations under the License.
 package builder
 
 import (
+	"fmt"
 	"net/http"
 	"net/url"
 	"strings"
@@ -32,10 +33,15 @@ import (
 
 // WebhookBuilder builds a Webhook.
 type WebhookBuilder struct {
-	apiType runtime.Object
-	gvk     schema.GroupVersionKind
-	mgr     manager.Manager
-	config  *rest.Config
+	apiType         runtime.Object
+	gvk             schema.GroupVersionKind
+	mgr             manager.Manager
+	config          *rest.Config
+	mutatingPath    string
+	validatingPath  string
+	pathPrefix      string
+	customDefaulter admission.CustomDefaulter
+	customValidator admission.CustomValidator
 }
 
 // WebhookManagedBy allows inform its manager.Manager.
@@ -48,11 +54,48 @@ func WebhookManagedBy(m manager.Manager) *WebhookBuilder {
 // For takes a runtime.Object which should be a CR.
 // If the given object implements the admission.Defaulter interface, a MutatingWebhook will be wired for this type.
 // If the given object implements the admission.Validator interface, a ValidatingWebhook will be wired for this type.
+// WithDefaulter and WithValidator wire them for a type which doesn't implement these interfaces.
 func (blder *WebhookBuilder) For(apiType runtime.Object) *WebhookBuilder {
 	blder.apiType = apiType
 	return blder
 }
 
+// WithDefaulter sets the defaulter of the objects of the type given to For, instead of
+// the Default method of the type; a mutating webhook is then wired for the type.
+func (blder *WebhookBuilder) WithDefaulter(defaulter admission.CustomDefaulter) *WebhookBuilder {
+	blder.customDefaulter = defaulter
+	return blder
+}
+
+// WithValidator sets the validator of the objects of the type given to For, instead of
+// the validation methods of the type; a validating webhook is then wired for the type.
+func (blder *WebhookBuilder) WithValidator(validator admission.CustomValidator) *WebhookBuilder {
+	blder.customValidator = validator
+	return blder
+}
+
+// WithMutatingPath sets the path of the mutating webhook, instead of the generated
+// "/mutate-<group>-<version>-<kind>". The path must not be registered already.
+func (blder *WebhookBuilder) WithMutatingPath(path string) *WebhookBuilder {
+	blder.mutatingPath = path
+	return blder
+}
+
+// WithValidatingPath sets the path of the validating webhook, instead of the generated
+// "/validate-<group>-<version>-<kind>". The path must not be registered already.
+func (blder *WebhookBuilder) WithValidatingPath(path string) *WebhookBuilder {
+	blder.validatingPath = path
+	return blder
+}
+
+// WithPathPrefix prefixes the generated paths of the mutating and validating webhooks
+// with prefix, e.g. "/v2" registers the mutating webhook on "/v2/mutate-<group>-<version>-<kind>".
+// It doesn't apply to the paths set with WithMutatingPath and WithValidatingPath.
+func (blder *WebhookBuilder) WithPathPrefix(prefix string) *WebhookBuilder {
+	blder.pathPrefix = prefix
+	return blder
+}
+
 // Complete builds the webhook.
 func (blder *WebhookBuilder) Complete() error {
 	// Set the Config
@@ -76,8 +119,18 @@ func (blder *WebhookBuilder) registerWebhooks() error {
 		return err
 	}
 
-	blder.registerDefaultingWebhook()
-	blder.registerValidatingWebhook()
+	for _, path := range []string{blder.mutatingPath, blder.validatingPath, blder.pathPrefix} {
+		if path != "" && !strings.HasPrefix(path, "/") {
+			return fmt.Errorf("the webhook path %q of %v must start with a slash", path, blder.gvk)
+		}
+	}
+
+	if err := blder.registerDefaultingWebhook(); err != nil {
+		return err
+	}
+	if err := blder.registerValidatingWebhook(); err != nil {
+		return err
+	}
 
 	err = blder.registerConversionWebhook()
 	if err != nil {
@@ -87,15 +140,15 @@ func (blder *WebhookBuilder) registerWebhooks() error {
 }
 
 // registerDefaultingWebhook registers a defaulting webhook if th.
-func (blder *WebhookBuilder) registerDefaultingWebhook() {
-	defaulter, isDefaulter := blder.apiType.(admission.Defaulter)
-	if !isDefaulter {
-		log.Info("skip registering a mutating webhook, admission.Defaulter interface is not implemented", "GVK", blder.gvk)
-		return
-	}
-	mwh := admission.DefaultingWebhookFor(defaulter)
+func (blder *WebhookBuilder) registerDefaultingWebhook() error {
+	mwh := blder.defaultingWebhook()
 	if mwh != nil {
-		path := generateMutatePath(blder.gvk)
+		path := blder.mutatingPath
+		if path == "" {
+			path = strings.TrimSuffix(blder.pathPrefix, "/") + generateMutatePath(blder.gvk)
+		} else if blder.isAlreadyHandled(path) {
+			return fmt.Errorf("the mutating webhook path %q of %v is already registered", path, blder.gvk)
+		}
 
 		// Checking if the path is already registered.
 		// If so, just skip it.
@@ -106,17 +159,18 @@ func (blder *WebhookBuilder) registerDefaultingWebhook() {
 			blder.mgr.GetWebhookServer().Register(path, mwh)
 		}
 	}
+	return nil
 }
 
-func (blder *WebhookBuilder) registerValidatingWebhook() {
-	validator, isValidator := blder.apiType.(admission.Validator)
-	if !isValidator {
-		log.Info("skip registering a validating webhook, admission.Validator interface is not implemented", "GVK", blder.gvk)
-		return
-	}
-	vwh := admission.ValidatingWebhookFor(validator)
+func (blder *WebhookBuilder) registerValidatingWebhook() error {
+	vwh := blder.validatingWebhook()
 	if vwh != nil {
-		path := generateValidatePath(blder.gvk)
+		path := blder.validatingPath
+		if path == "" {
+			path = strings.TrimSuffix(blder.pathPrefix, "/") + generateValidatePath(blder.gvk)
+		} else if blder.isAlreadyHandled(path) {
+			return fmt.Errorf("the validating webhook path %q of %v is already registered", path, blder.gvk)
+		}
 
 		// Checking if the path is already registered.
 		// If so, just skip it.
@@ -127,6 +181,31 @@ func (blder *WebhookBuilder) registerValidatingWebhook() {
 			blder.mgr.GetWebhookServer().Register(path, vwh)
 		}
 	}
+	return nil
+}
+
+func (blder *WebhookBuilder) defaultingWebhook() *admission.Webhook {
+	if blder.customDefaulter != nil {
+		return admission.WithCustomDefaulter(blder.apiType, blder.customDefaulter)
+	}
+	defaulter, isDefaulter := blder.apiType.(admission.Defaulter)
+	if !isDefaulter {
+		log.Info("skip registering a mutating webhook, admission.Defaulter interface is not implemented", "GVK", blder.gvk)
+		return nil
+	}
+	return admission.DefaultingWebhookFor(defaulter)
+}
+
+func (blder *WebhookBuilder) validatingWebhook() *admission.Webhook {
+	if blder.customValidator != nil {
+		return admission.WithCustomValidator(blder.apiType, blder.customValidator)
+	}
+	validator, isValidator := blder.apiType.(admission.Validator)
+	if !isValidator {
+		log.Info("skip registering a validating webhook, admission.Validator interface is not implemented", "GVK", blder.gvk)
+		return nil
+	}
+	return admission.ValidatingWebhookFor(validator)
 }
 
 func (blder *WebhookBuilder) registerConversionWebhook() error {
@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"net/url"
 	"os"
 	"strings"
 
@@ -286,6 +287,76 @@ func runTests(admissionReviewVersion string) {
 		ExpectWithOffset(1, w.Body).To(ContainSubstring(`"code":200`))
 	})
 
+	It("should scaffold the webhooks on the given paths", func() {
+		By("creating a controller manager")
+		m, err := manager.New(cfg, manager.Options{})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+		By("registering the type in the Scheme")
+		builder := scheme.Builder{GroupVersion: testDefaultValidatorGVK.GroupVersion()}
+		builder.Register(&TestDefaultValidator{}, &TestDefaultValidatorList{})
+		err = builder.AddToScheme(m.GetScheme())
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+		err = WebhookManagedBy(m).
+			For(&TestDefaultValidator{}).
+			WithMutatingPath("/custom-mutating-path").
+			WithPathPrefix("/v2/").
+			Complete()
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		svr := m.GetWebhookServer()
+
+		By("checking the webhooks are registered on the given paths only")
+		for path, registered := range map[string]bool{
+			"/custom-mutating-path":                               true,
+			"/v2" + generateValidatePath(testDefaultValidatorGVK): true,
+			generateMutatePath(testDefaultValidatorGVK):           false,
+			generateValidatePath(testDefaultValidatorGVK):         false,
+		} {
+			_, pattern := svr.WebhookMux.Handler(&http.Request{URL: &url.URL{Path: path}})
+			ExpectWithOffset(1, pattern == path).To(Equal(registered), path)
+		}
+
+		By("refusing to register a webhook on a path already registered")
+		err = WebhookManagedBy(m).
+			For(&TestDefaultValidator{}).
+			WithMutatingPath("/custom-mutating-path").
+			Complete()
+		ExpectWithOffset(1, err).To(MatchError(ContainSubstring(`the mutating webhook path "/custom-mutating-path"`)))
+
+		By("refusing a path without a leading slash")
+		err = WebhookManagedBy(m).
+			For(&TestDefaultValidator{}).
+			WithValidatingPath("validate").
+			Complete()
+		ExpectWithOffset(1, err).To(MatchError(ContainSubstring("must start with a slash")))
+	})
+
+	It("should scaffold a defaulting webhook with the given defaulter", func() {
+		By("creating a controller manager")
+		m, err := manager.New(cfg, manager.Options{})
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+		By("registering the type in the Scheme")
+		builder := scheme.Builder{GroupVersion: testValidatorGVK.GroupVersion()}
+		builder.Register(&TestValidator{}, &TestValidatorList{})
+		err = builder.AddToScheme(m.GetScheme())
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+
+		err = WebhookManagedBy(m).
+			For(&TestValidator{}).
+			WithDefaulter(&testCustomDefaulter{}).
+			Complete()
+		ExpectWithOffset(1, err).NotTo(HaveOccurred())
+		svr := m.GetWebhookServer()
+
+		By("checking the mutating webhook is registered along with the validating one")
+		for _, path := range []string{generateMutatePath(testValidatorGVK), generateValidatePath(testValidatorGVK)} {
+			_, pattern := svr.WebhookMux.Handler(&http.Request{URL: &url.URL{Path: path}})
+			ExpectWithOffset(1, pattern).To(Equal(path))
+		}
+	})
+
 	It("should scaffold a validating webhook if the type implements the Validator interface to validate deletes", func() {
 		By("creating a controller manager")
 		ctx, cancel := context.WithCancel(context.Background())
@@ -537,3 +608,9 @@ func (dv *TestDefaultValidator) ValidateDelete() error {
 	}
 	return nil
 }
+
+type testCustomDefaulter struct{}
+
+func (*testCustomDefaulter) Default(context.Context, runtime.Object) error {
+	return nil
+}
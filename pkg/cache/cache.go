@@ -19,9 +19,14 @@ package cache
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"strings"
 	"time"
 
+	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/client-go/kubernetes/scheme"
@@ -69,6 +74,45 @@ type Informers interface {
 	client.FieldIndexer
 }
 
+// KindSyncChecker is implemented by the caches which know whether the
+// informers of a single kind have synced, like the caches created by New and
+// MultiNamespacedCacheBuilder.
+type KindSyncChecker interface {
+	// HasSyncedFor returns whether the informers for the given group-version-kind
+	// have synced. It returns false if no informer has been requested for it yet.
+	// Unlike WaitForCacheSync it doesn't block, so it can be used to gate work
+	// on particular types, e.g. in health checks.
+	HasSyncedFor(gvk schema.GroupVersionKind) bool
+}
+
+// hasSyncedFor returns whether c has synced the informers for gvk, or false if
+// c doesn't implement KindSyncChecker.
+func hasSyncedFor(c Informers, gvk schema.GroupVersionKind) bool {
+	checker, ok := c.(KindSyncChecker)
+	return ok && checker.HasSyncedFor(gvk)
+}
+
+// InformerRemover is implemented by the caches whose informers can be stopped
+// and removed individually, like the caches created by New and
+// MultiNamespacedCacheBuilder.
+type InformerRemover interface {
+	// RemoveInformer stops and removes the informer for the given object, if any,
+	// e.g. because the type is no longer needed or its CRD has been deleted.
+	// Event handlers added to the informer stop receiving events, and a later
+	// read or GetInformer for the object creates a new informer.
+	RemoveInformer(ctx context.Context, obj client.Object) error
+}
+
+// removeInformer removes the informer for obj from c, or returns an error if
+// c doesn't implement InformerRemover.
+func removeInformer(ctx context.Context, c Informers, obj client.Object) error {
+	remover, ok := c.(InformerRemover)
+	if !ok {
+		return fmt.Errorf("removing informers from caches of type %T is not supported", c)
+	}
+	return remover.RemoveInformer(ctx, obj)
+}
+
 // Informer - informer allows you interact with the underlying informer.
 type Informer interface {
 	// AddEventHandler adds an event handler to the shared informer using the shared informer's resync
@@ -86,8 +130,100 @@ type Informer interface {
 	HasSynced() bool
 }
 
+// ObjectSelector restricts the objects an informer lists and watches to the
+// ones matching its label and field selectors.
+type ObjectSelector internal.Selector
+
+// InformerGetterWithOptions is implemented by the caches whose informers can be
+// fetched with per-call options, like the caches created by New and
+// MultiNamespacedCacheBuilder.
+type InformerGetterWithOptions interface {
+	// GetInformerWithOptions is like GetInformer, configured by opts.
+	GetInformerWithOptions(ctx context.Context, obj client.Object, opts ...InformerGetOption) (Informer, error)
+
+	// GetInformerForKindWithOptions is like GetInformerForKind, configured by opts.
+	GetInformerForKindWithOptions(ctx context.Context, gvk schema.GroupVersionKind, opts ...InformerGetOption) (Informer, error)
+}
+
+// getInformer gets the informer for obj from c with opts, or returns an error if
+// there are options and c doesn't implement InformerGetterWithOptions.
+func getInformer(ctx context.Context, c Informers, obj client.Object, opts []InformerGetOption) (Informer, error) {
+	if len(opts) == 0 {
+		return c.GetInformer(ctx, obj)
+	}
+	getter, ok := c.(InformerGetterWithOptions)
+	if !ok {
+		return nil, fmt.Errorf("getting informers with options from caches of type %T is not supported", c)
+	}
+	return getter.GetInformerWithOptions(ctx, obj, opts...)
+}
+
+// getInformerForKind is like getInformer, for a GroupVersionKind.
+func getInformerForKind(ctx context.Context, c Informers, gvk schema.GroupVersionKind, opts []InformerGetOption) (Informer, error) {
+	if len(opts) == 0 {
+		return c.GetInformerForKind(ctx, gvk)
+	}
+	getter, ok := c.(InformerGetterWithOptions)
+	if !ok {
+		return nil, fmt.Errorf("getting informers with options from caches of type %T is not supported", c)
+	}
+	return getter.GetInformerForKindWithOptions(ctx, gvk, opts...)
+}
+
+// InformerGetOption configures a single call to the methods of InformerGetterWithOptions.
+type InformerGetOption func(*internal.GetOptions)
+
+// BlockUntilSynced decides whether getting an informer from a started cache
+// waits for the informer to sync, which is the default.
+func BlockUntilSynced(shouldBlock bool) InformerGetOption {
+	return func(opts *internal.GetOptions) {
+		opts.BlockUntilSynced = &shouldBlock
+	}
+}
+
+// WithSelector restricts the objects of the informer to the ones matching the
+// selector, instead of the selector configured in the cache Options. It only
+// has an effect if the call creates the informer: informers are shared per
+// type, so getting an existing informer with another selector returns an error.
+func WithSelector(selector ObjectSelector) InformerGetOption {
+	return func(opts *internal.GetOptions) {
+		s := internal.Selector(selector)
+		opts.Selector = &s
+	}
+}
+
+func makeGetOptions(opts []InformerGetOption) internal.GetOptions {
+	var getOpts internal.GetOptions
+	for _, opt := range opts {
+		opt(&getOpts)
+	}
+	return getOpts
+}
+
 // SelectorsByObject associate a client.Object's GVK to a field/label selector.
-type SelectorsByObject map[client.Object]internal.Selector
+type SelectorsByObject map[client.Object]ObjectSelector
+
+// ResyncByObject associate a client.Object's GVK to the resync period of its informer.
+type ResyncByObject map[client.Object]time.Duration
+
+// TransformFunc allows for transforming an object before it is stored in the
+// cache, e.g. to drop fields that are never read in order to save memory.
+// It receives the object as decoded from the API server and must return a
+// runtime.Object of the same type.  It's fine to modify the object in place.
+//
+// Note that the same function is used for structured, unstructured and
+// metadata-only informers of a GVK, so it should work on any of them, e.g.
+// by using meta.Accessor.
+type TransformFunc func(interface{}) (interface{}, error)
+
+// TransformByObject associate a client.Object's GVK to a transform function.
+type TransformByObject map[client.Object]TransformFunc
+
+// NewStoreFunc creates the store of the informer for a GroupVersionKind, e.g.
+// to keep the objects of very large clusters on disk rather than in memory.
+// The store must compute the keys of objects with keyFunc and support the
+// given indexers, as well as the indexers added later through IndexField.
+type NewStoreFunc func(gvk schema.GroupVersionKind, keyFunc toolscache.KeyFunc, indexers toolscache.Indexers) toolscache.Indexer
 
 // Options are the optional arguments for creating a new InformersMap object.
 type Options struct {
@@ -103,32 +239,154 @@ type Options struct {
 	// So that all informers will not send list requests simultaneously.
 	Resync *time.Duration
 
+	// ResyncByObject overrides the Resync period per object type, e.g. to
+	// resync a few critical types more often without resyncing every other
+	// object in the cache as well. The same 10 percent jitter is applied.
+	ResyncByObject ResyncByObject
+
 	// Namespace restricts the cache's ListWatch to the desired namespace
 	// Default watches all namespaces
 	Namespace string
 
+	// Namespaces restricts the cache to the given namespaces for namespaced
+	// types, while cluster-scoped types are still cached globally. Such a
+	// cache is the same as the one of MultiNamespacedCacheBuilder, and
+	// implements DynamicNamespaces. It can't be used together with Namespace.
+	Namespaces []string
+
 	// SelectorsByObject restricts the cache's ListWatch to the desired
 	// fields per GVK at the specified object, the map's value must implement
 	// Selector [1] using for example a Set [2]
 	// [1] https://pkg.go.dev/k8s.io/apimachinery/pkg/fields#Selector
 	// [2] https://pkg.go.dev/k8s.io/apimachinery/pkg/fields#Set
 	SelectorsByObject SelectorsByObject
+
+	// DefaultSelector will be used as selector for all object types
+	// that do not have a selector in SelectorsByObject defined.
+	DefaultSelector ObjectSelector
+
+	// TransformByObject is a map from objects to transform functions which
+	// get applied to objects of the respective GVK before they are stored in
+	// the cache, both when they enter the cache and when they are updated.
+	TransformByObject TransformByObject
+
+	// DefaultTransform is the transform function used for all object types
+	// that do not have a transform function in TransformByObject defined.
+	DefaultTransform TransformFunc
+
+	// ListPageSize is the number of objects requested per page when the
+	// informers list their objects initially and after their watches
+	// expired. Setting it bypasses the API server's watch cache, which serves
+	// those lists in one piece, so that the responses stay small on clusters
+	// with very large object counts, at the cost of more load on etcd.
+	// Defaults to 0, which uses client-go's default behavior.
+	ListPageSize int64
+
+	// LazyInformers defers running an informer until its objects are first
+	// read from the cache or an event handler is added to it, instead of
+	// running every informer known to the cache as soon as it is started.
+	// This reduces startup time and memory when many informers are rarely
+	// used. Note that in this mode GetInformer doesn't wait for the informer
+	// to sync, and WaitForCacheSync only waits for informers that are running.
+	LazyInformers bool
+
+	// InformerIdleTTL, if set, stops and removes informers that haven't been
+	// read from for that long, e.g. the ones started for ad-hoc reads through
+	// the delegating client. They are started again on the next read.
+	// Informers with event handlers or indexers added are never removed.
+	// Defaults to 0, which keeps informers until the cache is stopped.
+	InformerIdleTTL time.Duration
+
+	// UseWatchList makes informers stream their initial list through a watch
+	// (the WatchList feature of Kubernetes 1.27+), which the API server serves
+	// from its watch cache, instead of listing all objects at once. If the
+	// server doesn't support it, informers fall back to (paginated) lists, and
+	// try streaming again with an exponential backoff.
+	// It currently only applies to structured objects.
+	UseWatchList bool
+
+	// CompressObjects makes informers store objects as gzipped JSON, and
+	// decode them whenever they are read or passed to event handlers and index
+	// functions. It trades CPU for memory, for controllers whose memory is
+	// dominated by their cache. The stores and indexers of the informers
+	// returned by GetInformer decode the objects read from them too.
+	// This is experimental.
+	CompressObjects bool
+
+	// TombstoneTTL is how long the cache remembers deleted objects. Getting
+	// an object that has been deleted within the TTL fails with a NotFound
+	// error for which IsRecentlyDeleted returns true, which tells reconcilers
+	// apart objects that have just been deleted from objects that haven't
+	// made it into the cache yet. The deletions are recorded by the stores of the
+	// informers, so their informers call event handlers like the ones of a custom
+	// NewStore. Defaults to 0, which doesn't track deletions.
+	TombstoneTTL time.Duration
+
+	// NewStore creates the stores of the informers. Informers with a custom
+	// store call event handlers synchronously, in the order they were added,
+	// and resync each of them with its own resync period, like other informers.
+	// Defaults to nil, which uses client-go's in-memory store.
+	NewStore NewStoreFunc
+
+	// WatchErrorHandler is called whenever the ListAndWatch of an informer
+	// drops its connection with an error, e.g. because of missing RBAC
+	// permissions or a removed API. Informers keep retrying after the handler
+	// returns; handlers can stop them with the RemoveInformer method of the
+	// cache, see InformerRemover.
+	// Defaults to client-go's DefaultWatchErrorHandler, which logs the error.
+	WatchErrorHandler toolscache.WatchErrorHandler
+
+	// CacheSyncTimeout is the maximum time WaitForCacheSync waits for the
+	// informers to sync. If they don't sync in time, the kinds that failed
+	// to sync are logged, e.g. because of missing RBAC permissions, and
+	// WaitForCacheSync returns false.
+	// Defaults to 0, which means to wait until the context is done.
+	CacheSyncTimeout time.Duration
 }
 
 var defaultResyncTime = 10 * time.Hour
 
 // New initializes and returns a new Cache.
 func New(config *rest.Config, opts Options) (Cache, error) {
+	if len(opts.Namespaces) > 0 {
+		if opts.Namespace != "" {
+			return nil, fmt.Errorf("only one of Namespace and Namespaces can be set")
+		}
+		namespaces := opts.Namespaces
+		opts.Namespaces = nil
+		return MultiNamespacedCacheBuilder(namespaces)(config, opts)
+	}
 	opts, err := defaultOpts(config, opts)
 	if err != nil {
 		return nil, err
 	}
-	selectorsByGVK, err := convertToSelectorsByGVK(opts.SelectorsByObject, opts.Scheme)
+	selectorsByGVK, err := convertToSelectorsByGVK(opts.SelectorsByObject, opts.DefaultSelector, opts.Scheme)
 	if err != nil {
 		return nil, err
 	}
-	im := internal.NewInformersMap(config, opts.Scheme, opts.Mapper, *opts.Resync, opts.Namespace, selectorsByGVK)
-	return &informerCache{InformersMap: im}, nil
+	transformByGVK, err := convertToTransformByGVK(opts.TransformByObject, opts.DefaultTransform, opts.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	resyncByGVK, err := convertToResyncByGVK(opts.ResyncByObject, *opts.Resync, opts.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	im := internal.NewInformersMap(config, opts.Scheme, opts.Mapper, internal.InformersMapOptions{
+		Resync:            resyncByGVK,
+		Namespace:         opts.Namespace,
+		Selectors:         selectorsByGVK,
+		Transformers:      transformByGVK,
+		WatchErrorHandler: opts.WatchErrorHandler,
+		ListPageSize:      opts.ListPageSize,
+		Lazy:              opts.LazyInformers,
+		IdleTTL:           opts.InformerIdleTTL,
+		WatchList:         opts.UseWatchList,
+		Compress:          opts.CompressObjects,
+		TombstoneTTL:      opts.TombstoneTTL,
+		NewStore:          internal.NewStoreFunc(opts.NewStore),
+	})
+	return &informerCache{InformersMap: im, syncTimeout: opts.CacheSyncTimeout}, nil
 }
 
 // BuilderWithOptions returns a Cache constructor that will build the a cache
@@ -150,7 +408,43 @@ func BuilderWithOptions(options Options) NewCacheFunc {
 		if opts.Namespace == "" {
 			opts.Namespace = options.Namespace
 		}
+		if opts.Namespaces == nil {
+			opts.Namespaces = options.Namespaces
+		}
+		if opts.ResyncByObject == nil {
+			opts.ResyncByObject = options.ResyncByObject
+		}
+		if !opts.LazyInformers {
+			opts.LazyInformers = options.LazyInformers
+		}
+		if !opts.CompressObjects {
+			opts.CompressObjects = options.CompressObjects
+		}
+		if !opts.UseWatchList {
+			opts.UseWatchList = options.UseWatchList
+		}
+		if opts.TombstoneTTL == 0 {
+			opts.TombstoneTTL = options.TombstoneTTL
+		}
+		if opts.NewStore == nil {
+			opts.NewStore = options.NewStore
+		}
+		if opts.InformerIdleTTL == 0 {
+			opts.InformerIdleTTL = options.InformerIdleTTL
+		}
+		if opts.ListPageSize == 0 {
+			opts.ListPageSize = options.ListPageSize
+		}
+		if opts.WatchErrorHandler == nil {
+			opts.WatchErrorHandler = options.WatchErrorHandler
+		}
+		if opts.CacheSyncTimeout == 0 {
+			opts.CacheSyncTimeout = options.CacheSyncTimeout
+		}
 		opts.SelectorsByObject = options.SelectorsByObject
+		opts.DefaultSelector = options.DefaultSelector
+		opts.TransformByObject = options.TransformByObject
+		opts.DefaultTransform = options.DefaultTransform
 		return New(config, opts)
 	}
 }
@@ -178,14 +472,116 @@ func defaultOpts(config *rest.Config, opts Options) (Options, error) {
 	return opts, nil
 }
 
-func convertToSelectorsByGVK(selectorsByObject SelectorsByObject, scheme *runtime.Scheme) (internal.SelectorsByGVK, error) {
+func convertToSelectorsByGVK(selectorsByObject SelectorsByObject, defaultSelector ObjectSelector, scheme *runtime.Scheme) (internal.SelectorsByGVK, error) {
 	selectorsByGVK := internal.SelectorsByGVK{}
 	for object, selector := range selectorsByObject {
 		gvk, err := apiutil.GVKForObject(object, scheme)
 		if err != nil {
 			return nil, err
 		}
-		selectorsByGVK[gvk] = selector
+		selectorsByGVK[gvk] = internal.Selector(selector)
 	}
+	selectorsByGVK[schema.GroupVersionKind{}] = internal.Selector(defaultSelector)
 	return selectorsByGVK, nil
 }
+
+func convertToResyncByGVK(resyncByObject ResyncByObject, defaultResync time.Duration, scheme *runtime.Scheme) (internal.ResyncPeriodByGVK, error) {
+	resyncByGVK := internal.ResyncPeriodByGVK{}
+	for object, resync := range resyncByObject {
+		gvk, err := apiutil.GVKForObject(object, scheme)
+		if err != nil {
+			return nil, err
+		}
+		resyncByGVK[gvk] = resync
+	}
+	resyncByGVK[schema.GroupVersionKind{}] = defaultResync
+	return resyncByGVK, nil
+}
+
+func convertToTransformByGVK(transformByObject TransformByObject, defaultTransform TransformFunc, scheme *runtime.Scheme) (internal.TransformFuncByGVK, error) {
+	transformByGVK := internal.TransformFuncByGVK{}
+	for object, transform := range transformByObject {
+		gvk, err := apiutil.GVKForObject(object, scheme)
+		if err != nil {
+			return nil, err
+		}
+		transformByGVK[gvk] = internal.TransformFunc(transform)
+	}
+	if defaultTransform != nil {
+		transformByGVK[schema.GroupVersionKind{}] = internal.TransformFunc(defaultTransform)
+	}
+	return transformByGVK, nil
+}
+
+// TransformStripManagedFields returns a transform function that drops the
+// managedFields and the kubectl last-applied-configuration annotation of
+// objects, which often make up a large part of their size but are rarely
+// needed by controllers.
+func TransformStripManagedFields() TransformFunc {
+	return func(in interface{}) (interface{}, error) {
+		if obj, err := meta.Accessor(in); err == nil {
+			obj.SetManagedFields(nil)
+			if annotations := obj.GetAnnotations(); annotations != nil {
+				delete(annotations, corev1.LastAppliedConfigAnnotation)
+				obj.SetAnnotations(annotations)
+			}
+		}
+		return in, nil
+	}
+}
+
+// TransformProjectFields returns a transform function that only keeps the given
+// fields of objects, plus their apiVersion, kind and metadata. Fields are
+// dot-separated paths into the JSON representation of the object, such as
+// "spec.replicas" or "status". It allows to not keep huge objects in memory
+// when only a few of their fields are used.
+//
+// Typed objects keep their type, the fields that weren't kept are left empty.
+// Metadata-only objects are left unchanged.
+func TransformProjectFields(paths ...string) TransformFunc {
+	fieldPaths := make([][]string, 0, len(paths))
+	for _, path := range paths {
+		fieldPaths = append(fieldPaths, strings.Split(path, "."))
+	}
+	return func(in interface{}) (interface{}, error) {
+		switch obj := in.(type) {
+		case *metav1.PartialObjectMetadata:
+			return obj, nil
+		case *unstructured.Unstructured:
+			obj.Object = projectFields(obj.Object, fieldPaths)
+			return obj, nil
+		case runtime.Object:
+			u, err := runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+			if err != nil {
+				return nil, err
+			}
+			projected := reflect.New(reflect.TypeOf(obj).Elem()).Interface()
+			if err := runtime.DefaultUnstructuredConverter.FromUnstructured(projectFields(u, fieldPaths), projected); err != nil {
+				return nil, err
+			}
+			return projected, nil
+		default:
+			return nil, fmt.Errorf("cannot project fields of %T", in)
+		}
+	}
+}
+
+// projectFields copies the given fields, apiVersion, kind and metadata of in into a new map.
+func projectFields(in map[string]interface{}, fieldPaths [][]string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, field := range []string{"apiVersion", "kind", "metadata"} {
+		if value, found := in[field]; found {
+			out[field] = value
+		}
+	}
+	for _, fieldPath := range fieldPaths {
+		value, found, err := unstructured.NestedFieldNoCopy(in, fieldPath...)
+		if err != nil || !found {
+			continue
+		}
+		// SetNestedField only fails when an intermediate field is not a map,
+		// which can't happen for paths found in a valid object.
+		_ = unstructured.SetNestedField(out, value, fieldPath...)
+	}
+	return out
+}
@@ -18,7 +18,11 @@ package cache_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/ginkgo/extensions/table"
@@ -26,6 +30,7 @@ import (
 
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/fields"
@@ -37,6 +42,7 @@ import (
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 )
 
 const testNodeOne = "test-node-1"
@@ -271,6 +277,25 @@ func CacheTest(createCacheFunc func(config *rest.Config, opts cache.Options) (ca
 					Expect(out).NotTo(Equal(knownPod2))
 				})
 
+				It("should not deep copy listed objects when told so", func() {
+					By("listing pods twice without deep copying them")
+					first := &corev1.PodList{}
+					Expect(informerCache.List(context.Background(), first, client.InNamespace(testNamespaceTwo), client.UnsafeDisableDeepCopy)).To(Succeed())
+					second := &corev1.PodList{}
+					Expect(informerCache.List(context.Background(), second, client.InNamespace(testNamespaceTwo), client.UnsafeDisableDeepCopy)).To(Succeed())
+
+					By("verifying both lists share the objects stored in the cache")
+					Expect(first.Items).NotTo(BeEmpty())
+					Expect(second.Items).To(HaveLen(len(first.Items)))
+					deadlines := map[string]*int64{}
+					for _, pod := range first.Items {
+						deadlines[pod.Name] = pod.Spec.ActiveDeadlineSeconds
+					}
+					for _, pod := range second.Items {
+						Expect(pod.Spec.ActiveDeadlineSeconds).To(BeIdenticalTo(deadlines[pod.Name]))
+					}
+				})
+
 				It("should return an error if the object is not found", func() {
 					By("getting a service that does not exists")
 					svc := &corev1.Service{}
@@ -776,22 +801,26 @@ func CacheTest(createCacheFunc func(config *rest.Config, opts cache.Options) (ca
 				})
 			})
 			type selectorsTestCase struct {
-				fieldSelectors map[string]string
-				labelSelectors map[string]string
-				expectedPods   []string
+				fieldSelectors        map[string]string
+				labelSelectors        map[string]string
+				defaultLabelSelectors map[string]string
+				expectedPods          []string
 			}
 			DescribeTable(" and cache with selectors", func(tc selectorsTestCase) {
 				By("creating the cache")
-				builder := cache.BuilderWithOptions(
-					cache.Options{
-						SelectorsByObject: cache.SelectorsByObject{
-							&corev1.Pod{}: {
-								Label: labels.Set(tc.labelSelectors).AsSelector(),
-								Field: fields.Set(tc.fieldSelectors).AsSelector(),
-							},
-						},
-					},
-				)
+				opts := cache.Options{SelectorsByObject: cache.SelectorsByObject{}}
+				if tc.fieldSelectors != nil || tc.labelSelectors != nil {
+					opts.SelectorsByObject[&corev1.Pod{}] = cache.ObjectSelector{
+						Label: labels.Set(tc.labelSelectors).AsSelector(),
+						Field: fields.Set(tc.fieldSelectors).AsSelector(),
+					}
+				}
+				if tc.defaultLabelSelectors != nil {
+					opts.DefaultSelector = cache.ObjectSelector{
+						Label: labels.Set(tc.defaultLabelSelectors).AsSelector(),
+					}
+				}
+				builder := cache.BuilderWithOptions(opts)
 				informer, err := builder(cfg, cache.Options{})
 				Expect(err).NotTo(HaveOccurred())
 
@@ -881,8 +910,541 @@ func CacheTest(createCacheFunc func(config *rest.Config, opts cache.Options) (ca
 					fieldSelectors: map[string]string{"metadata.namespace": "new"},
 					expectedPods:   []string{},
 				}),
+				Entry("when only the default selector is set it has to inform about the matching pods", selectorsTestCase{
+					defaultLabelSelectors: map[string]string{"common-label": "common"},
+					expectedPods:          []string{"test-pod-3", "test-pod-4"},
+				}),
+				Entry("when a selector for the object is set it has to take precedence over the default selector", selectorsTestCase{
+					labelSelectors:        map[string]string{"test-label": "test-pod-4"},
+					defaultLabelSelectors: map[string]string{"new-label": "new"},
+					expectedPods:          []string{"test-pod-4"},
+				}),
 			)
 		})
+		Context("with transform functions", func() {
+			It("should apply transform functions before storing objects", func() {
+				By("creating the cache")
+				setAnnotation := func(value string) cache.TransformFunc {
+					return func(in interface{}) (interface{}, error) {
+						obj, err := apimeta.Accessor(in)
+						if err != nil {
+							return nil, err
+						}
+						obj.SetAnnotations(map[string]string{"transformed": value})
+						return in, nil
+					}
+				}
+				builder := cache.BuilderWithOptions(cache.Options{
+					TransformByObject: cache.TransformByObject{
+						&corev1.Pod{}: setAnnotation("pod"),
+					},
+					DefaultTransform: setAnnotation("default"),
+				})
+				informer, err := builder(cfg, cache.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("checking that pods were transformed with their own transform function")
+				pods := &corev1.PodList{}
+				Expect(informer.List(context.Background(), pods)).To(Succeed())
+				Expect(pods.Items).NotTo(BeEmpty())
+				for _, pod := range pods.Items {
+					Expect(pod.Annotations).To(HaveKeyWithValue("transformed", "pod"))
+				}
+
+				By("checking that other objects were transformed with the default transform function")
+				svc := &corev1.Service{}
+				Expect(informer.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "kubernetes"}, svc)).To(Succeed())
+				Expect(svc.Annotations).To(HaveKeyWithValue("transformed", "default"))
+			})
+
+			It("should only keep the projected fields", func() {
+				By("creating the cache")
+				informer, err := cache.New(cfg, cache.Options{
+					TransformByObject: cache.TransformByObject{
+						&corev1.Pod{}: cache.TransformProjectFields("spec.nodeName", "status.phase"),
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("checking that only the projected fields and the metadata were kept")
+				pod := &corev1.Pod{}
+				Expect(informer.Get(context.Background(), client.ObjectKey{Namespace: testNamespaceOne, Name: "test-pod-1"}, pod)).To(Succeed())
+				Expect(pod.Name).To(Equal("test-pod-1"))
+				Expect(pod.Labels).NotTo(BeEmpty())
+				Expect(pod.Spec.Containers).To(BeEmpty())
+			})
+		})
+		Context("with a custom store", func() {
+			It("should keep the objects in the store", func() {
+				By("creating the cache")
+				stores := map[schema.GroupVersionKind]kcache.Indexer{}
+				informer, err := cache.New(cfg, cache.Options{
+					NewStore: func(gvk schema.GroupVersionKind, keyFunc kcache.KeyFunc, indexers kcache.Indexers) kcache.Indexer {
+						stores[gvk] = kcache.NewIndexer(keyFunc, indexers)
+						return stores[gvk]
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("reading pods from the cache")
+				pod := &corev1.Pod{}
+				Expect(informer.Get(context.Background(), client.ObjectKey{Namespace: testNamespaceOne, Name: "test-pod-1"}, pod)).To(Succeed())
+				pods := &corev1.PodList{}
+				Expect(informer.List(context.Background(), pods, client.InNamespace(testNamespaceOne))).To(Succeed())
+				Expect(pods.Items).NotTo(BeEmpty())
+
+				By("checking that the pods are in the store")
+				store := stores[corev1.SchemeGroupVersion.WithKind("Pod")]
+				Expect(store).NotTo(BeNil())
+				_, exists, err := store.GetByKey(testNamespaceOne + "/test-pod-1")
+				Expect(err).NotTo(HaveOccurred())
+				Expect(exists).To(BeTrue())
+			})
+		})
+		Context("with tombstones", func() {
+			It("should tell recently deleted objects apart", func() {
+				By("creating the cache")
+				informer, err := cache.New(cfg, cache.Options{TombstoneTTL: time.Minute})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("checking that unknown pods are not reported as deleted")
+				err = informer.Get(context.Background(), client.ObjectKey{Namespace: testNamespaceOne, Name: "unknown"}, &corev1.Pod{})
+				Expect(apierrors.IsNotFound(err)).To(BeTrue())
+				Expect(cache.IsRecentlyDeleted(err)).To(BeFalse())
+
+				By("deleting a pod")
+				pod := createPod("test-pod-tombstone", testNamespaceOne, corev1.RestartPolicyNever)
+				key := client.ObjectKeyFromObject(pod)
+				Eventually(func() error {
+					return informer.Get(context.Background(), key, &corev1.Pod{})
+				}).Should(Succeed())
+				deletePod(pod)
+
+				By("checking that the pod is reported as deleted")
+				Eventually(func() bool {
+					err := informer.Get(context.Background(), key, &corev1.Pod{})
+					return apierrors.IsNotFound(err) && cache.IsRecentlyDeleted(err)
+				}).Should(BeTrue())
+			})
+		})
+		Context("with several namespaces", func() {
+			It("should cache namespaced types of the namespaces and cluster-scoped types globally", func() {
+				By("creating the cache")
+				informer, err := cache.New(cfg, cache.Options{Namespaces: []string{testNamespaceOne}})
+				Expect(err).NotTo(HaveOccurred())
+				_, isDynamic := informer.(cache.DynamicNamespaces)
+				Expect(isDynamic).To(BeTrue())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("listing pods of the namespaces only")
+				pods := &corev1.PodList{}
+				Expect(informer.List(context.Background(), pods)).To(Succeed())
+				Expect(pods.Items).NotTo(BeEmpty())
+				for _, pod := range pods.Items {
+					Expect(pod.Namespace).To(Equal(testNamespaceOne))
+				}
+
+				By("listing namespaces globally")
+				namespaces := &corev1.NamespaceList{}
+				Expect(informer.List(context.Background(), namespaces)).To(Succeed())
+				Expect(len(namespaces.Items)).To(BeNumerically(">", 1))
+			})
+
+			It("should not allow setting both Namespace and Namespaces", func() {
+				_, err := cache.New(cfg, cache.Options{Namespace: testNamespaceOne, Namespaces: []string{testNamespaceTwo}})
+				Expect(err).To(HaveOccurred())
+			})
+		})
+		Context("with compressed objects", func() {
+			It("should decompress objects when reading them", func() {
+				By("creating the cache")
+				informer, err := cache.New(cfg, cache.Options{CompressObjects: true})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("adding an event handler")
+				sii, err := informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+				added := make(chan interface{}, 100)
+				sii.AddEventHandler(kcache.ResourceEventHandlerFuncs{
+					AddFunc: func(obj interface{}) { added <- obj },
+				})
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("getting and listing pods")
+				pod := &corev1.Pod{}
+				Expect(informer.Get(context.Background(), client.ObjectKey{Namespace: testNamespaceOne, Name: "test-pod-1"}, pod)).To(Succeed())
+				Expect(pod.Spec.Containers).NotTo(BeEmpty())
+
+				pods := &corev1.PodList{}
+				Expect(informer.List(context.Background(), pods, client.InNamespace(testNamespaceOne), client.MatchingLabels{"test-label": "test-pod-1"})).To(Succeed())
+				Expect(pods.Items).To(HaveLen(1))
+				Expect(pods.Items[0].Spec.Containers).NotTo(BeEmpty())
+
+				By("checking that event handlers receive decompressed objects")
+				Eventually(added).Should(Receive(BeAssignableToTypeOf(&corev1.Pod{})))
+			})
+		})
+		Context("with per-call informer options", func() {
+			It("should only select the objects matching the selector of the call", func() {
+				By("creating the cache")
+				informer, err := cache.New(cfg, cache.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("getting an informer with a selector without blocking")
+				sii, err := informer.(cache.InformerGetterWithOptions).GetInformerWithOptions(context.TODO(), &corev1.Pod{},
+					cache.BlockUntilSynced(false),
+					cache.WithSelector(cache.ObjectSelector{Label: labels.SelectorFromSet(labels.Set{"test-label": "test-pod-2"})}))
+				Expect(err).NotTo(HaveOccurred())
+				Eventually(sii.HasSynced).Should(BeTrue())
+
+				By("checking that only the matching pods are cached")
+				pods := &corev1.PodList{}
+				Expect(informer.List(context.Background(), pods)).To(Succeed())
+				Expect(pods.Items).To(HaveLen(1))
+				Expect(pods.Items[0].Name).To(Equal("test-pod-2"))
+
+				By("failing to get the informer again with another selector")
+				_, err = informer.(cache.InformerGetterWithOptions).GetInformerWithOptions(context.TODO(), &corev1.Pod{},
+					cache.WithSelector(cache.ObjectSelector{Label: labels.SelectorFromSet(labels.Set{"test-label": "test-pod-3"})}))
+				Expect(err).To(MatchError(ContainSubstring("another selector")))
+			})
+		})
+		Context("with a readiness check", func() {
+			It("should only be ready once the informers have synced", func() {
+				By("creating the cache")
+				informer, err := cache.New(cfg, cache.Options{})
+				Expect(err).NotTo(HaveOccurred())
+				_, err = informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+
+				check := cache.ReadyzCheck(informer)
+				checkPods := cache.ReadyzCheck(informer, &corev1.Pod{})
+				checkServices := cache.ReadyzCheck(informer, &corev1.Service{})
+				req := httptest.NewRequest(http.MethodGet, "/readyz", nil)
+				Expect(check(req)).NotTo(Succeed())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("checking that the cache is ready")
+				Expect(check(req)).To(Succeed())
+				Expect(checkPods(req)).To(Succeed())
+				Expect(checkServices(req)).NotTo(Succeed())
+			})
+		})
+		Context("when dumping the cache", func() {
+			It("should serve the keys of the cached objects", func() {
+				By("creating the cache")
+				informer, err := cache.New(cfg, cache.Options{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache and waiting for it to sync")
+				_, err = informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("requesting a dump")
+				rec := httptest.NewRecorder()
+				cache.DumpHandler(informer).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/cache", nil))
+				Expect(rec.Code).To(Equal(http.StatusOK))
+
+				var dumps []cache.InformerDump
+				Expect(json.Unmarshal(rec.Body.Bytes(), &dumps)).To(Succeed())
+				Expect(dumps).To(HaveLen(1))
+				Expect(dumps[0].GroupVersionKind).To(Equal(corev1.SchemeGroupVersion.WithKind("Pod")))
+				Expect(dumps[0].Representation).To(Equal("structured"))
+				Expect(dumps[0].Synced).To(BeTrue())
+				Expect(dumps[0].Keys).To(ContainElement(testNamespaceOne + "/test-pod-1"))
+				Expect(dumps[0].Objects).To(BeEmpty())
+
+				By("requesting a dump with objects")
+				rec = httptest.NewRecorder()
+				cache.DumpHandler(informer).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/debug/cache?objects=true", nil))
+				Expect(rec.Code).To(Equal(http.StatusOK))
+				Expect(json.Unmarshal(rec.Body.Bytes(), &dumps)).To(Succeed())
+				Expect(dumps[0].Objects).To(HaveLen(len(dumps[0].Keys)))
+			})
+		})
+		Context("with per-object resync periods", func() {
+			It("should resync objects with their own resync period", func() {
+				By("creating the cache")
+				resync := 100 * time.Millisecond
+				informer, err := cache.New(cfg, cache.Options{
+					ResyncByObject: cache.ResyncByObject{&corev1.Pod{}: resync},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("adding an event handler counting resyncs")
+				sii, err := informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+				resynced := make(chan struct{}, 100)
+				sii.AddEventHandlerWithResyncPeriod(kcache.ResourceEventHandlerFuncs{
+					UpdateFunc: func(oldObj, newObj interface{}) {
+						if oldObj.(*corev1.Pod).ResourceVersion == newObj.(*corev1.Pod).ResourceVersion {
+							resynced <- struct{}{}
+						}
+					},
+				}, resync)
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("verifying the pods are resynced")
+				Eventually(resynced).Should(Receive())
+			})
+		})
+		Context("with metrics", func() {
+			It("should report the number of cached objects per kind", func() {
+				By("listing pods to create their informer")
+				pods := &corev1.PodList{}
+				Expect(informerCache.List(context.Background(), pods)).To(Succeed())
+				Expect(pods.Items).NotTo(BeEmpty())
+
+				By("gathering the cache metrics")
+				cachedPods := func() float64 {
+					families, err := metrics.Registry.Gather()
+					Expect(err).NotTo(HaveOccurred())
+					for _, family := range families {
+						if family.GetName() != "controller_runtime_cache_objects" {
+							continue
+						}
+						for _, metric := range family.GetMetric() {
+							for _, label := range metric.GetLabel() {
+								if label.GetName() == "kind" && label.GetValue() == "Pod" {
+									return metric.GetGauge().GetValue()
+								}
+							}
+						}
+					}
+					return 0
+				}
+				Expect(cachedPods()).To(BeNumerically(">=", len(pods.Items)))
+			})
+		})
+		Context("with lazy informers", func() {
+			It("should only run informers once they are used", func() {
+				By("creating the cache and an informer for pods")
+				informer, err := cache.New(cfg, cache.Options{LazyInformers: true})
+				Expect(err).NotTo(HaveOccurred())
+				sii, err := informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).To(BeTrue())
+
+				By("verifying the unused informer isn't running")
+				Consistently(sii.HasSynced).Should(BeFalse())
+
+				By("reading pods from the cache")
+				pods := &corev1.PodList{}
+				Expect(informer.List(context.Background(), pods)).To(Succeed())
+				Expect(pods.Items).NotTo(BeEmpty())
+				Expect(sii.HasSynced()).To(BeTrue())
+			})
+
+			It("should run informers once an event handler is added", func() {
+				By("creating and running the cache")
+				informer, err := cache.New(cfg, cache.Options{LazyInformers: true})
+				Expect(err).NotTo(HaveOccurred())
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).To(BeTrue())
+
+				By("adding an event handler to the informer for pods")
+				sii, err := informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+				added := make(chan interface{}, 100)
+				sii.AddEventHandler(kcache.ResourceEventHandlerFuncs{AddFunc: func(obj interface{}) {
+					added <- obj
+				}})
+
+				By("verifying the informer runs")
+				Eventually(sii.HasSynced).Should(BeTrue())
+				Eventually(added).Should(Receive())
+			})
+		})
+		Context("with an informer idle TTL", func() {
+			It("should remove informers that aren't read from and restart them on demand", func() {
+				podGVK := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}
+				By("creating and running the cache")
+				informer, err := cache.New(cfg, cache.Options{InformerIdleTTL: 200 * time.Millisecond})
+				Expect(err).NotTo(HaveOccurred())
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).To(BeTrue())
+
+				By("reading pods from the cache")
+				pods := &corev1.PodList{}
+				Expect(informer.List(context.Background(), pods)).To(Succeed())
+				Expect(informer.(cache.KindSyncChecker).HasSyncedFor(podGVK)).To(BeTrue())
+
+				By("verifying the informer is removed once idle")
+				Eventually(func() bool { return informer.(cache.KindSyncChecker).HasSyncedFor(podGVK) }).Should(BeFalse())
+
+				By("reading pods again")
+				Expect(informer.List(context.Background(), pods)).To(Succeed())
+				Expect(pods.Items).NotTo(BeEmpty())
+			})
+
+			It("should keep informers with event handlers", func() {
+				podGVK := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "Pod"}
+				By("creating and running the cache")
+				informer, err := cache.New(cfg, cache.Options{InformerIdleTTL: 200 * time.Millisecond})
+				Expect(err).NotTo(HaveOccurred())
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).To(BeTrue())
+
+				By("adding an event handler to the informer for pods")
+				sii, err := informer.GetInformer(context.TODO(), &corev1.Pod{})
+				Expect(err).NotTo(HaveOccurred())
+				sii.AddEventHandler(kcache.ResourceEventHandlerFuncs{})
+
+				By("verifying the informer is kept")
+				Consistently(func() bool { return informer.(cache.KindSyncChecker).HasSyncedFor(podGVK) }, time.Second).Should(BeTrue())
+			})
+		})
+		Context("with a list page size", func() {
+			It("should list all objects in pages", func() {
+				By("creating the cache")
+				informer, err := cache.New(cfg, cache.Options{ListPageSize: 1})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache and waiting for it to sync")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+				Expect(informer.WaitForCacheSync(informerCacheCtx)).NotTo(BeFalse())
+
+				By("verifying all pods are cached")
+				pods := &corev1.PodList{}
+				Expect(informer.List(context.Background(), pods)).To(Succeed())
+				cl, err := client.New(cfg, client.Options{})
+				Expect(err).NotTo(HaveOccurred())
+				expected := &corev1.PodList{}
+				Expect(cl.List(context.Background(), expected)).To(Succeed())
+				Expect(pods.Items).To(HaveLen(len(expected.Items)))
+			})
+		})
+		Context("with a watch error handler", func() {
+			It("should call the handler when an informer can't list its objects", func() {
+				By("creating a cache with a mapping for a kind the server doesn't serve")
+				gvk := schema.GroupVersionKind{Group: "missing.example.com", Version: "v1", Kind: "Missing"}
+				mapper := apimeta.NewDefaultRESTMapper(nil)
+				mapper.Add(gvk, apimeta.RESTScopeNamespace)
+				watchErrors := make(chan error, 10)
+				informer, err := cache.New(cfg, cache.Options{
+					Mapper: mapper,
+					WatchErrorHandler: func(_ *kcache.Reflector, err error) {
+						select {
+						case watchErrors <- err:
+						default:
+						}
+					},
+				})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("getting an informer for the kind")
+				obj := &unstructured.Unstructured{}
+				obj.SetGroupVersionKind(gvk)
+				_, err = informer.GetInformer(context.TODO(), obj)
+				Expect(err).NotTo(HaveOccurred())
+
+				By("running the cache")
+				go func() {
+					defer GinkgoRecover()
+					Expect(informer.Start(informerCacheCtx)).To(Succeed())
+				}()
+
+				By("verifying the handler is called")
+				Eventually(watchErrors).Should(Receive(MatchError(ContainSubstring("failed to list"))))
+				Expect(informer.(cache.InformerRemover).RemoveInformer(context.TODO(), obj)).To(Succeed())
+			})
+		})
+		Context("with a cache sync timeout", func() {
+			It("should stop waiting for the cache to sync after the timeout", func() {
+				By("creating a cache that is never started")
+				informer, err := cache.New(cfg, cache.Options{CacheSyncTimeout: 100 * time.Millisecond})
+				Expect(err).NotTo(HaveOccurred())
+
+				By("verifying WaitForCacheSync gives up")
+				Expect(informer.WaitForCacheSync(context.Background())).To(BeFalse())
+			})
+		})
 		Describe("as an Informer", func() {
 			Context("with structured objects", func() {
 				It("should be able to get informer for the object", func(done Done) {
@@ -962,6 +1524,64 @@ func CacheTest(createCacheFunc func(config *rest.Config, opts cache.Options) (ca
 					Eventually(out).Should(Receive(Equal(pod)))
 					close(done)
 				})
+				It("should report whether the informer for a kind has synced", func() {
+					gvk := schema.GroupVersionKind{Group: "", Version: "v1", Kind: "ServiceAccount"}
+					By("checking a kind without informer")
+					Expect(informerCache.(cache.KindSyncChecker).HasSyncedFor(gvk)).To(BeFalse())
+
+					By("getting an informer for the kind")
+					_, err := informerCache.GetInformerForKind(context.TODO(), gvk)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(informerCache.(cache.KindSyncChecker).HasSyncedFor(gvk)).To(BeTrue())
+				})
+				It("should be able to remove an informer", func() {
+					By("getting a shared index informer for a pod")
+					sii, err := informerCache.GetInformer(context.TODO(), &corev1.Pod{})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(sii.HasSynced()).To(BeTrue())
+
+					By("adding an event handler listening for object creation which sends the object to a channel")
+					out := make(chan interface{}, 10)
+					sii.AddEventHandler(kcache.ResourceEventHandlerFuncs{AddFunc: func(obj interface{}) {
+						out <- obj
+					}})
+					Eventually(out).Should(Receive())
+
+					By("removing the informer")
+					Expect(informerCache.(cache.InformerRemover).RemoveInformer(context.TODO(), &corev1.Pod{})).To(Succeed())
+					for len(out) > 0 {
+						<-out
+					}
+
+					By("adding an object")
+					cl, err := client.New(cfg, client.Options{})
+					Expect(err).NotTo(HaveOccurred())
+					pod := &corev1.Pod{
+						ObjectMeta: metav1.ObjectMeta{
+							Name:      "informer-removed",
+							Namespace: "default",
+						},
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:  "nginx",
+									Image: "nginx",
+								},
+							},
+						},
+					}
+					Expect(cl.Create(context.Background(), pod)).To(Succeed())
+					defer deletePod(pod)
+
+					By("verifying the removed informer doesn't receive the object")
+					Consistently(out).ShouldNot(Receive())
+
+					By("verifying a new informer is created on demand")
+					newSii, err := informerCache.GetInformer(context.TODO(), &corev1.Pod{})
+					Expect(err).NotTo(HaveOccurred())
+					Expect(newSii).NotTo(BeIdenticalTo(sii))
+					Expect(newSii.HasSynced()).To(BeTrue())
+				})
 				It("should be able to index an object field then retrieve objects by that field", func() {
 					By("creating the cache")
 					informer, err := cache.New(cfg, cache.Options{})
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// InformerDump describes the contents of an informer of a cache.
+type InformerDump struct {
+	GroupVersionKind schema.GroupVersionKind `json:"groupVersionKind"`
+	// Representation is how objects are stored by the informer,
+	// one of "structured", "unstructured" and "metadata".
+	Representation string `json:"representation"`
+	// Namespace is set for the informers of a multi-namespace cache, which
+	// have one informer per namespace.
+	Namespace string `json:"namespace,omitempty"`
+	Synced    bool   `json:"synced"`
+	// Keys are the namespace/name keys of the objects in the informer.
+	Keys []string `json:"keys"`
+	// Objects are the objects in the informer, only set if requested.
+	Objects []interface{} `json:"objects,omitempty"`
+}
+
+// dumper is implemented by the caches that can describe their contents.
+type dumper interface {
+	dump(includeObjects bool) []InformerDump
+}
+
+// Dump describes the contents of all the informers of the cache, which helps
+// finding out why a controller acts on stale data. Objects are only included
+// if includeObjects is true.
+// It fails for caches that don't support it, like the ones not created by this package.
+func Dump(c Cache, includeObjects bool) ([]InformerDump, error) {
+	d, ok := c.(dumper)
+	if !ok {
+		return nil, fmt.Errorf("dumping caches of type %T is not supported", c)
+	}
+	return d.dump(includeObjects), nil
+}
+
+// DumpHandler returns an http.Handler serving the contents of the informers of
+// the cache as JSON, for debugging. Objects are only included if the query
+// parameter "objects" is true, e.g. /debug/cache?objects=true.
+//
+// The handler can be added to the metrics server of a manager with
+// AddMetricsExtraHandler. Beware that it exposes everything in the cache,
+// including Secrets when they are cached, to anyone able to reach it.
+func DumpHandler(c Cache) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		includeObjects := false
+		if value := req.URL.Query().Get("objects"); value != "" {
+			var err error
+			if includeObjects, err = strconv.ParseBool(value); err != nil {
+				http.Error(w, fmt.Sprintf("invalid value for objects: %v", err), http.StatusBadRequest)
+				return
+			}
+		}
+		dumps, err := Dump(c, includeObjects)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(dumps); err != nil {
+			log.Error(err, "failed to write cache dump")
+		}
+	})
+}
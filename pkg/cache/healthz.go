@@ -0,0 +1,74 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"fmt"
+	"net/http"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// readinessChecker is implemented by the caches that can tell without
+// blocking whether their informers have synced.
+type readinessChecker interface {
+	// checkSynced returns an error if the informers for the types of objs,
+	// or all the informers if no objects are given, haven't synced yet.
+	checkSynced(objs ...client.Object) error
+}
+
+// ReadyzCheck returns a healthz.Checker that fails until the cache has been
+// started and all of its informers have synced. If objects are given, only
+// the informers for their types are checked, and they fail until the
+// informers have been created and synced. Use it as:
+//
+//  mgr.AddReadyzCheck("cache", cache.ReadyzCheck(mgr.GetCache()))
+//
+// It always fails for caches that don't support it, like the ones not
+// created by this package.
+func ReadyzCheck(c Cache, objs ...client.Object) healthz.Checker {
+	return func(_ *http.Request) error {
+		checker, ok := c.(readinessChecker)
+		if !ok {
+			return fmt.Errorf("readiness checks of caches of type %T are not supported", c)
+		}
+		return checker.checkSynced(objs...)
+	}
+}
+
+// checkSyncedFor returns an error if c has not synced the informers for the types of objs.
+func checkSyncedFor(c Informers, scheme *runtime.Scheme, objs []client.Object) error {
+	var unsynced []schema.GroupVersionKind
+	for _, obj := range objs {
+		gvk, err := apiutil.GVKForObject(obj, scheme)
+		if err != nil {
+			return err
+		}
+		if !hasSyncedFor(c, gvk) {
+			unsynced = append(unsynced, gvk)
+		}
+	}
+	if len(unsynced) > 0 {
+		return fmt.Errorf("informers have not synced for %v", unsynced)
+	}
+	return nil
+}
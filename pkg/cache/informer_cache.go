@@ -18,10 +18,13 @@ package cache
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"strings"
+	"time"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -33,9 +36,12 @@ import (
 )
 
 var (
-	_ Informers     = &informerCache{}
-	_ client.Reader = &informerCache{}
-	_ Cache         = &informerCache{}
+	_ Informers                 = &informerCache{}
+	_ client.Reader             = &informerCache{}
+	_ Cache                     = &informerCache{}
+	_ InformerRemover           = &informerCache{}
+	_ KindSyncChecker           = &informerCache{}
+	_ InformerGetterWithOptions = &informerCache{}
 )
 
 // ErrCacheNotStarted is returned when trying to read from the cache that wasn't started.
@@ -45,9 +51,27 @@ func (*ErrCacheNotStarted) Error() string {
 	return "the cache is not started, can not read objects"
 }
 
+// IsRecentlyDeleted returns true if err is the NotFound error of reading an
+// object that has been deleted recently, see Options.TombstoneTTL.
+func IsRecentlyDeleted(err error) bool {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) || status.Status().Details == nil {
+		return false
+	}
+	for _, cause := range status.Status().Details.Causes {
+		if cause.Type == internal.RecentlyDeletedCause {
+			return true
+		}
+	}
+	return false
+}
+
 // informerCache is a Kubernetes Object cache populated from InformersMap.  informerCache wraps an InformersMap.
 type informerCache struct {
 	*internal.InformersMap
+
+	// syncTimeout bounds WaitForCacheSync, 0 means no bound.
+	syncTimeout time.Duration
 }
 
 // Get implements Reader.
@@ -57,7 +81,7 @@ func (ip *informerCache) Get(ctx context.Context, key client.ObjectKey, out clie
 		return err
 	}
 
-	started, cache, err := ip.InformersMap.Get(ctx, gvk, out)
+	started, cache, err := ip.InformersMap.Get(ctx, gvk, out, internal.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -75,7 +99,7 @@ func (ip *informerCache) List(ctx context.Context, out client.ObjectList, opts .
 		return err
 	}
 
-	started, cache, err := ip.InformersMap.Get(ctx, *gvk, cacheTypeObj)
+	started, cache, err := ip.InformersMap.Get(ctx, *gvk, cacheTypeObj, internal.GetOptions{})
 	if err != nil {
 		return err
 	}
@@ -131,13 +155,18 @@ func (ip *informerCache) objectTypeForListObject(list client.ObjectList) (*schem
 
 // GetInformerForKind returns the informer for the GroupVersionKind.
 func (ip *informerCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (Informer, error) {
+	return ip.GetInformerForKindWithOptions(ctx, gvk)
+}
+
+// GetInformerForKindWithOptions returns the informer for the GroupVersionKind, configured by opts.
+func (ip *informerCache) GetInformerForKindWithOptions(ctx context.Context, gvk schema.GroupVersionKind, opts ...InformerGetOption) (Informer, error) {
 	// Map the gvk to an object
 	obj, err := ip.Scheme.New(gvk)
 	if err != nil {
 		return nil, err
 	}
 
-	_, i, err := ip.InformersMap.Get(ctx, gvk, obj)
+	_, i, err := ip.InformersMap.Get(ctx, gvk, obj, makeGetOptions(opts))
 	if err != nil {
 		return nil, err
 	}
@@ -146,18 +175,87 @@ func (ip *informerCache) GetInformerForKind(ctx context.Context, gvk schema.Grou
 
 // GetInformer returns the informer for the obj.
 func (ip *informerCache) GetInformer(ctx context.Context, obj client.Object) (Informer, error) {
+	return ip.GetInformerWithOptions(ctx, obj)
+}
+
+// GetInformerWithOptions returns the informer for the obj, configured by opts.
+func (ip *informerCache) GetInformerWithOptions(ctx context.Context, obj client.Object, opts ...InformerGetOption) (Informer, error) {
 	gvk, err := apiutil.GVKForObject(obj, ip.Scheme)
 	if err != nil {
 		return nil, err
 	}
 
-	_, i, err := ip.InformersMap.Get(ctx, gvk, obj)
+	_, i, err := ip.InformersMap.Get(ctx, gvk, obj, makeGetOptions(opts))
 	if err != nil {
 		return nil, err
 	}
 	return i.Informer, err
 }
 
+// RemoveInformer removes the informer for the obj and stops it.
+func (ip *informerCache) RemoveInformer(ctx context.Context, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, ip.Scheme)
+	if err != nil {
+		return err
+	}
+
+	ip.InformersMap.Remove(gvk, obj)
+	return nil
+}
+
+// WaitForCacheSync waits for all the informers to sync, for at most the
+// configured sync timeout, and logs the kinds that failed to sync in time.
+func (ip *informerCache) WaitForCacheSync(ctx context.Context) bool {
+	if ip.syncTimeout <= 0 {
+		return ip.InformersMap.WaitForCacheSync(ctx)
+	}
+
+	syncCtx, cancel := context.WithTimeout(ctx, ip.syncTimeout)
+	defer cancel()
+	if ip.InformersMap.WaitForCacheSync(syncCtx) {
+		return true
+	}
+	// only complain if the timeout hit, not if the caller gave up
+	if ctx.Err() == nil {
+		err := fmt.Errorf("timed out after %v waiting for caches to sync", ip.syncTimeout)
+		log.Error(err, "Could not wait for caches to sync", "unsyncedKinds", ip.InformersMap.UnsyncedGVKs())
+	}
+	return false
+}
+
+func (ip *informerCache) dump(includeObjects bool) []InformerDump {
+	internalDumps := ip.InformersMap.Dump(includeObjects)
+	dumps := make([]InformerDump, 0, len(internalDumps))
+	for _, d := range internalDumps {
+		dumps = append(dumps, InformerDump{
+			GroupVersionKind: d.GroupVersionKind,
+			Representation:   d.Representation,
+			Synced:           d.Synced,
+			Keys:             d.Keys,
+			Objects:          d.Objects,
+		})
+	}
+	return dumps
+}
+
+func (ip *informerCache) checkSynced(objs ...client.Object) error {
+	if !ip.InformersMap.HasStarted() {
+		return fmt.Errorf("the cache has not been started")
+	}
+	if len(objs) == 0 {
+		if unsynced := ip.InformersMap.UnsyncedGVKs(); len(unsynced) > 0 {
+			return fmt.Errorf("informers have not synced for %v", unsynced)
+		}
+		return nil
+	}
+	return checkSyncedFor(ip, ip.Scheme, objs)
+}
+
+// HasSyncedFor returns whether the informers for the gvk have synced.
+func (ip *informerCache) HasSyncedFor(gvk schema.GroupVersionKind) bool {
+	return ip.InformersMap.HasSyncedFor(gvk)
+}
+
 // NeedLeaderElection implements the LeaderElectionRunnable interface
 // to indicate that this can be started without requiring the leader lock.
 func (ip *informerCache) NeedLeaderElection() bool {
@@ -79,6 +79,19 @@ func (c *FakeInformers) GetInformer(ctx context.Context, obj client.Object) (cac
 	return c.informerFor(gvk, obj)
 }
 
+// RemoveInformer implements Informers.
+func (c *FakeInformers) RemoveInformer(ctx context.Context, obj client.Object) error {
+	if c.Scheme == nil {
+		c.Scheme = scheme.Scheme
+	}
+	gvks, _, err := c.Scheme.ObjectKinds(obj)
+	if err != nil {
+		return err
+	}
+	delete(c.InformersByGVK, gvks[0])
+	return nil
+}
+
 // WaitForCacheSync implements Informers.
 func (c *FakeInformers) WaitForCacheSync(ctx context.Context) bool {
 	if c.Synced == nil {
@@ -87,6 +100,15 @@ func (c *FakeInformers) WaitForCacheSync(ctx context.Context) bool {
 	return *c.Synced
 }
 
+// HasSyncedFor implements Informers.
+func (c *FakeInformers) HasSyncedFor(gvk schema.GroupVersionKind) bool {
+	if c.Synced != nil && !*c.Synced {
+		return false
+	}
+	informer, ok := c.InformersByGVK[gvk]
+	return ok && informer.HasSynced()
+}
+
 // FakeInformerFor implements Informers.
 func (c *FakeInformers) FakeInformerFor(obj runtime.Object) (*controllertest.FakeInformer, error) {
 	if c.Scheme == nil {
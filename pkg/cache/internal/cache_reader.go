@@ -23,6 +23,7 @@ import (
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -46,10 +47,29 @@ type CacheReader struct {
 
 	// scopeName is the scope of the resource (namespaced or cluster-scoped).
 	scopeName apimeta.RESTScopeName
+
+	// beforeRead, if set, is called before reading from the indexer,
+	// e.g. to start the informer backing it.
+	beforeRead func(ctx context.Context) error
+
+	// decompressor, if set, decodes the compressed objects of the indexer.
+	decompressor *decompressor
+
+	// tombstones, if set, tells which objects have been deleted recently.
+	tombstones *tombstoneTracker
 }
 
+// RecentlyDeletedCause is the type of the cause added to the NotFound errors
+// of objects that have been deleted recently.
+const RecentlyDeletedCause metav1.CauseType = "RecentlyDeleted"
+
 // Get checks the indexer for the object and writes a copy of it if found.
-func (c *CacheReader) Get(_ context.Context, key client.ObjectKey, out client.Object) error {
+func (c *CacheReader) Get(ctx context.Context, key client.ObjectKey, out client.Object) error {
+	if c.beforeRead != nil {
+		if err := c.beforeRead(ctx); err != nil {
+			return err
+		}
+	}
 	if c.scopeName == apimeta.RESTScopeNameRoot {
 		key.Namespace = ""
 	}
@@ -64,10 +84,17 @@ func (c *CacheReader) Get(_ context.Context, key client.ObjectKey, out client.Ob
 	// Not found, return an error
 	if !exists {
 		// Resource gets transformed into Kind in the error anyway, so this is fine
-		return apierrors.NewNotFound(schema.GroupResource{
+		err := apierrors.NewNotFound(schema.GroupResource{
 			Group:    c.groupVersionKind.Group,
 			Resource: c.groupVersionKind.Kind,
 		}, key.Name)
+		if c.tombstones != nil && c.tombstones.recentlyDeleted(storeKey) {
+			err.ErrStatus.Details.Causes = append(err.ErrStatus.Details.Causes, metav1.StatusCause{
+				Type:    RecentlyDeletedCause,
+				Message: "the object has been deleted recently",
+			})
+		}
+		return err
 	}
 
 	// Verify the result is a runtime.Object
@@ -76,9 +103,16 @@ func (c *CacheReader) Get(_ context.Context, key client.ObjectKey, out client.Ob
 		return fmt.Errorf("cache contained %T, which is not an Object", obj)
 	}
 
-	// deep copy to avoid mutating cache
-	// TODO(directxman12): revisit the decision to always deepcopy
-	obj = obj.(runtime.Object).DeepCopyObject()
+	if c.decompressor != nil {
+		// decompressing returns a new object already
+		if obj, err = c.decompressor.decompress(obj.(runtime.Object)); err != nil {
+			return err
+		}
+	} else {
+		// deep copy to avoid mutating cache
+		// TODO(directxman12): revisit the decision to always deepcopy
+		obj = obj.(runtime.Object).DeepCopyObject()
+	}
 
 	// Copy the value of the item in the cache to the returned value
 	// TODO(directxman12): this is a terrible hack, pls fix (we should have deepcopyinto)
@@ -94,7 +128,12 @@ func (c *CacheReader) Get(_ context.Context, key client.ObjectKey, out client.Ob
 }
 
 // List lists items out of the indexer and writes them to out.
-func (c *CacheReader) List(_ context.Context, out client.ObjectList, opts ...client.ListOption) error {
+func (c *CacheReader) List(ctx context.Context, out client.ObjectList, opts ...client.ListOption) error {
+	if c.beforeRead != nil {
+		if err := c.beforeRead(ctx); err != nil {
+			return err
+		}
+	}
 	var objs []interface{}
 	var err error
 
@@ -150,8 +189,20 @@ func (c *CacheReader) List(_ context.Context, out client.ObjectList, opts ...cli
 			}
 		}
 
-		outObj := obj.DeepCopyObject()
-		outObj.GetObjectKind().SetGroupVersionKind(c.groupVersionKind)
+		var outObj runtime.Object
+		if c.decompressor != nil {
+			if outObj, err = c.decompressor.decompress(obj); err != nil {
+				return err
+			}
+			outObj.GetObjectKind().SetGroupVersionKind(c.groupVersionKind)
+		} else if listOpts.UnsafeDisableDeepCopy != nil && *listOpts.UnsafeDisableDeepCopy {
+			// skip the deep copy, which might be unsafe:
+			// the caller must DeepCopy any object before mutating it.
+			outObj = obj
+		} else {
+			outObj = obj.DeepCopyObject()
+			outObj.GetObjectKind().SetGroupVersionKind(c.groupVersionKind)
+		}
 		runtimeObjs = append(runtimeObjs, outObj)
 	}
 	return apimeta.SetList(out, runtimeObjs)
@@ -0,0 +1,374 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"reflect"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// compressedObject is what compressing informers store instead of objects.
+// It keeps the metadata needed by the store, its indices and label selectors,
+// and the gzipped JSON of the whole object.
+type compressedObject struct {
+	metav1.ObjectMeta `json:"metadata"`
+	Data              []byte `json:"data"`
+}
+
+var _ runtime.Object = &compressedObject{}
+
+// GetObjectKind implements runtime.Object.
+func (c *compressedObject) GetObjectKind() schema.ObjectKind {
+	return schema.EmptyObjectKind
+}
+
+// DeepCopyObject implements runtime.Object.
+func (c *compressedObject) DeepCopyObject() runtime.Object {
+	// the data is never mutated, so it can be shared
+	return &compressedObject{ObjectMeta: *c.ObjectMeta.DeepCopy(), Data: c.Data}
+}
+
+// compress turns obj into a compressedObject.
+func compress(obj runtime.Object) (*compressedObject, error) {
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	w := gzip.NewWriter(&buf)
+	if err := json.NewEncoder(w).Encode(obj); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return &compressedObject{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            objMeta.GetName(),
+			Namespace:       objMeta.GetNamespace(),
+			UID:             objMeta.GetUID(),
+			ResourceVersion: objMeta.GetResourceVersion(),
+			Labels:          objMeta.GetLabels(),
+		},
+		Data: buf.Bytes(),
+	}, nil
+}
+
+// decompressor decodes compressedObjects into new objects of a given type.
+type decompressor struct {
+	objType reflect.Type
+}
+
+func newDecompressor(obj runtime.Object) decompressor {
+	return decompressor{objType: reflect.TypeOf(obj).Elem()}
+}
+
+// decompress returns a new object decoded from obj if it is a compressedObject,
+// and obj itself otherwise.
+func (d decompressor) decompress(obj runtime.Object) (runtime.Object, error) {
+	compressed, ok := obj.(*compressedObject)
+	if !ok {
+		return obj, nil
+	}
+	r, err := gzip.NewReader(bytes.NewReader(compressed.Data))
+	if err != nil {
+		return nil, err
+	}
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	out := reflect.New(d.objType).Interface().(runtime.Object)
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, fmt.Errorf("failed to decode cached %T: %w", out, err)
+	}
+	return out, nil
+}
+
+// decompressItem decompresses items of stores and event handlers, including tombstones.
+func (d decompressor) decompressItem(item interface{}) (interface{}, error) {
+	switch obj := item.(type) {
+	case cache.DeletedFinalStateUnknown:
+		runtimeObj, ok := obj.Obj.(runtime.Object)
+		if !ok {
+			return obj, nil
+		}
+		decompressed, err := d.decompress(runtimeObj)
+		if err != nil {
+			return nil, err
+		}
+		obj.Obj = decompressed
+		return obj, nil
+	case runtime.Object:
+		return d.decompress(obj)
+	default:
+		return item, nil
+	}
+}
+
+// compressingListWatch wraps lw so that every object it lists or watches is compressed.
+func compressingListWatch(lw *cache.ListWatch) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			list, err := lw.ListFunc(opts)
+			if err != nil {
+				return nil, err
+			}
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				return nil, err
+			}
+			compressed := make([]runtime.Object, 0, len(items))
+			for _, item := range items {
+				c, err := compress(item)
+				if err != nil {
+					return nil, err
+				}
+				compressed = append(compressed, c)
+			}
+			listMeta, err := meta.ListAccessor(list)
+			if err != nil {
+				return nil, err
+			}
+			// the list type can't hold compressedObjects, the reflector
+			// only needs the items, the resource version and the continue
+			// token of paginated lists anyways
+			return &compressedList{
+				ListMeta: metav1.ListMeta{ResourceVersion: listMeta.GetResourceVersion(), Continue: listMeta.GetContinue()},
+				Items:    compressed,
+			}, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			w, err := lw.WatchFunc(opts)
+			if err != nil {
+				return nil, err
+			}
+			return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+				// bookmarks only carry the resource version, and errors a
+				// status, they must reach the reflector untouched
+				if event.Type == watch.Error || event.Type == watch.Bookmark {
+					return event, true
+				}
+				c, err := compress(event.Object)
+				if err != nil {
+					return watch.Event{Type: watch.Error, Object: &apierrors.NewInternalError(err).ErrStatus}, true
+				}
+				event.Object = c
+				return event, true
+			}), nil
+		},
+	}
+}
+
+// compressedList is the list of compressedObjects returned by compressing list watches.
+type compressedList struct {
+	metav1.ListMeta `json:"metadata"`
+	Items           []runtime.Object `json:"items"`
+}
+
+// GetObjectKind implements runtime.Object.
+func (c *compressedList) GetObjectKind() schema.ObjectKind {
+	return schema.EmptyObjectKind
+}
+
+// DeepCopyObject implements runtime.Object.
+func (c *compressedList) DeepCopyObject() runtime.Object {
+	items := make([]runtime.Object, 0, len(c.Items))
+	for _, item := range c.Items {
+		items = append(items, item.DeepCopyObject())
+	}
+	return &compressedList{ListMeta: *c.ListMeta.DeepCopy(), Items: items}
+}
+
+// decompressingInformer hands out decompressed objects to event handlers, index
+// functions, stores and indexers of an informer storing compressedObjects.
+type decompressingInformer struct {
+	cache.SharedIndexInformer
+	decompressor decompressor
+}
+
+func (d *decompressingInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	d.SharedIndexInformer.AddEventHandler(&decompressingHandler{handler: handler, decompressor: d.decompressor})
+}
+
+func (d *decompressingInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	d.SharedIndexInformer.AddEventHandlerWithResyncPeriod(&decompressingHandler{handler: handler, decompressor: d.decompressor}, resyncPeriod)
+}
+
+func (d *decompressingInformer) AddIndexers(indexers cache.Indexers) error {
+	decompressingIndexers := make(cache.Indexers, len(indexers))
+	for name, indexFunc := range indexers {
+		indexFunc := indexFunc
+		decompressingIndexers[name] = func(obj interface{}) ([]string, error) {
+			decompressed, err := d.decompressor.decompressItem(obj)
+			if err != nil {
+				return nil, err
+			}
+			return indexFunc(decompressed)
+		}
+	}
+	return d.SharedIndexInformer.AddIndexers(decompressingIndexers)
+}
+
+// GetStore returns the store of the informer, which hands out decompressed
+// objects too, e.g. for the resyncs of sources.
+func (d *decompressingInformer) GetStore() cache.Store {
+	return &decompressingStore{Store: d.SharedIndexInformer.GetStore(), decompressor: d.decompressor}
+}
+
+// GetIndexer returns the indexer of the informer, which hands out decompressed
+// objects too.
+func (d *decompressingInformer) GetIndexer() cache.Indexer {
+	return &decompressingIndexer{Indexer: d.SharedIndexInformer.GetIndexer(), decompressor: d.decompressor}
+}
+
+// decompressingStore decompresses the objects read from a store of compressedObjects.
+type decompressingStore struct {
+	cache.Store
+	decompressor decompressor
+}
+
+func (s *decompressingStore) List() []interface{} {
+	items := s.Store.List()
+	decompressed := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		if obj, err := s.decompressor.decompressItem(item); err == nil {
+			decompressed = append(decompressed, obj)
+		} else {
+			utilruntime.HandleError(err)
+		}
+	}
+	return decompressed
+}
+
+func (s *decompressingStore) Get(obj interface{}) (interface{}, bool, error) {
+	return s.decompressed(s.Store.Get(obj))
+}
+
+func (s *decompressingStore) GetByKey(key string) (interface{}, bool, error) {
+	return s.decompressed(s.Store.GetByKey(key))
+}
+
+func (s *decompressingStore) decompressed(item interface{}, exists bool, err error) (interface{}, bool, error) {
+	if !exists || err != nil {
+		return item, exists, err
+	}
+	obj, err := s.decompressor.decompressItem(item)
+	return obj, err == nil, err
+}
+
+// decompressingIndexer decompresses the objects read from an indexer of compressedObjects.
+type decompressingIndexer struct {
+	cache.Indexer
+	decompressor decompressor
+}
+
+func (i *decompressingIndexer) List() []interface{} {
+	return i.store().List()
+}
+
+func (i *decompressingIndexer) Get(obj interface{}) (interface{}, bool, error) {
+	return i.store().Get(obj)
+}
+
+func (i *decompressingIndexer) GetByKey(key string) (interface{}, bool, error) {
+	return i.store().GetByKey(key)
+}
+
+func (i *decompressingIndexer) Index(indexName string, obj interface{}) ([]interface{}, error) {
+	items, err := i.Indexer.Index(indexName, obj)
+	if err != nil {
+		return nil, err
+	}
+	return i.decompressAll(items)
+}
+
+func (i *decompressingIndexer) ByIndex(indexName, indexedValue string) ([]interface{}, error) {
+	items, err := i.Indexer.ByIndex(indexName, indexedValue)
+	if err != nil {
+		return nil, err
+	}
+	return i.decompressAll(items)
+}
+
+func (i *decompressingIndexer) store() *decompressingStore {
+	return &decompressingStore{Store: i.Indexer, decompressor: i.decompressor}
+}
+
+func (i *decompressingIndexer) decompressAll(items []interface{}) ([]interface{}, error) {
+	decompressed := make([]interface{}, 0, len(items))
+	for _, item := range items {
+		obj, err := i.decompressor.decompressItem(item)
+		if err != nil {
+			return nil, err
+		}
+		decompressed = append(decompressed, obj)
+	}
+	return decompressed, nil
+}
+
+// decompressingHandler decompresses objects before passing them to handler.
+type decompressingHandler struct {
+	handler      cache.ResourceEventHandler
+	decompressor decompressor
+}
+
+func (h *decompressingHandler) OnAdd(obj interface{}) {
+	if decompressed, ok := h.decompress(obj); ok {
+		h.handler.OnAdd(decompressed)
+	}
+}
+
+func (h *decompressingHandler) OnUpdate(oldObj, newObj interface{}) {
+	oldDecompressed, ok := h.decompress(oldObj)
+	if !ok {
+		return
+	}
+	if newDecompressed, ok := h.decompress(newObj); ok {
+		h.handler.OnUpdate(oldDecompressed, newDecompressed)
+	}
+}
+
+func (h *decompressingHandler) OnDelete(obj interface{}) {
+	if decompressed, ok := h.decompress(obj); ok {
+		h.handler.OnDelete(decompressed)
+	}
+}
+
+func (h *decompressingHandler) decompress(obj interface{}) (interface{}, bool) {
+	decompressed, err := h.decompressor.decompressItem(obj)
+	if err != nil {
+		// the data has been encoded by compress, failing to decode it is a bug
+		utilruntime.HandleError(err)
+		return nil, false
+	}
+	return decompressed, true
+}
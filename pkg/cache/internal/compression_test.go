@@ -0,0 +1,63 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var _ = Describe("decompressingInformer", func() {
+	var (
+		informer *decompressingInformer
+		pod      = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+	)
+
+	BeforeEach(func() {
+		informer = &decompressingInformer{
+			SharedIndexInformer: cache.NewSharedIndexInformer(&cache.ListWatch{}, &compressedObject{}, 0, cache.Indexers{
+				cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
+			}),
+			decompressor: newDecompressor(&corev1.Pod{}),
+		}
+		compressed, err := compress(pod)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(informer.SharedIndexInformer.GetIndexer().Add(compressed)).To(Succeed())
+	})
+
+	It("should hand out decompressed objects from its store", func() {
+		store := informer.GetStore()
+		Expect(store.List()).To(Equal([]interface{}{pod}))
+		obj, exists, err := store.GetByKey("default/foo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+		Expect(obj).To(Equal(pod))
+	})
+
+	It("should hand out decompressed objects from its indexer", func() {
+		indexer := informer.GetIndexer()
+		Expect(indexer.List()).To(Equal([]interface{}{pod}))
+		obj, exists, err := indexer.GetByKey("default/foo")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+		Expect(obj).To(Equal(pod))
+		Expect(indexer.ByIndex(cache.NamespaceIndex, "default")).To(Equal([]interface{}{pod}))
+	})
+})
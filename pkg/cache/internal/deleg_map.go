@@ -18,6 +18,7 @@ package internal
 
 import (
 	"context"
+	"sort"
 	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -43,19 +44,68 @@ type InformersMap struct {
 	Scheme *runtime.Scheme
 }
 
+// InformersMapOptions configures the informers created by an InformersMap.
+type InformersMapOptions struct {
+	// Resync is the base frequency the informers are resynced, per GVK.
+	Resync ResyncPeriodByGVK
+
+	// Namespace restricts the informers to a single namespace,
+	// empty means all namespaces.
+	Namespace string
+
+	// Selectors are the label or field selectors that will be added to the
+	// ListWatch ListOptions.
+	Selectors SelectorsByGVK
+
+	// Transformers are applied to the objects before they are
+	// stored in the informers.
+	Transformers TransformFuncByGVK
+
+	// ListPageSize is the number of objects requested per page when the
+	// informers list their objects, 0 leaves paging up to client-go.
+	ListPageSize int64
+
+	// Lazy defers running an informer until it is first read from or an
+	// event handler is added to it, instead of running every informer as
+	// soon as the map is started.
+	Lazy bool
+
+	// IdleTTL, if positive, stops and removes informers that haven't been
+	// read from for that long. Informers with event handlers or additional
+	// indexers are never removed.
+	IdleTTL time.Duration
+
+	// WatchList makes informers for structured objects stream their
+	// initial list through a watch, if the server supports it.
+	WatchList bool
+
+	// Compress makes informers store objects compressed.
+	Compress bool
+
+	// TombstoneTTL is how long the keys of deleted objects are remembered.
+	TombstoneTTL time.Duration
+
+	// NewStore, if set, creates the stores of the informers instead of
+	// client-go's in-memory store.
+	NewStore NewStoreFunc
+
+	// WatchErrorHandler is called whenever the ListAndWatch of an informer
+	// drops its connection with an error. Defaults to client-go's handler,
+	// which only logs the error.
+	WatchErrorHandler cache.WatchErrorHandler
+}
+
 // NewInformersMap creates a new InformersMap that can create informers for
 // both structured and unstructured objects.
 func NewInformersMap(config *rest.Config,
 	scheme *runtime.Scheme,
 	mapper meta.RESTMapper,
-	resync time.Duration,
-	namespace string,
-	selectors SelectorsByGVK,
+	opts InformersMapOptions,
 ) *InformersMap {
 	return &InformersMap{
-		structured:   newStructuredInformersMap(config, scheme, mapper, resync, namespace, selectors),
-		unstructured: newUnstructuredInformersMap(config, scheme, mapper, resync, namespace, selectors),
-		metadata:     newMetadataInformersMap(config, scheme, mapper, resync, namespace, selectors),
+		structured:   newStructuredInformersMap(config, scheme, mapper, opts),
+		unstructured: newUnstructuredInformersMap(config, scheme, mapper, opts),
+		metadata:     newMetadataInformersMap(config, scheme, mapper, opts),
 
 		Scheme: scheme,
 	}
@@ -88,37 +138,113 @@ func (m *InformersMap) WaitForCacheSync(ctx context.Context) bool {
 	return cache.WaitForCacheSync(ctx.Done(), syncedFuncs...)
 }
 
+// HasSyncedFor returns true if there is at least one informer for the
+// GroupVersionKind, and all of its informers (structured, unstructured and
+// metadata-only) have synced.
+func (m *InformersMap) HasSyncedFor(gvk schema.GroupVersionKind) bool {
+	foundAny := false
+	for _, ip := range []*specificInformersMap{m.structured, m.unstructured, m.metadata} {
+		found, synced := ip.hasSyncedFor(gvk)
+		if found && !synced {
+			return false
+		}
+		foundAny = foundAny || found
+	}
+	return foundAny
+}
+
+// HasStarted returns whether the informers have been started.
+func (m *InformersMap) HasStarted() bool {
+	return m.structured.hasStarted() && m.unstructured.hasStarted() && m.metadata.hasStarted()
+}
+
+// UnsyncedGVKs returns the GroupVersionKinds of all the informers that haven't synced yet,
+// sorted for stable output.
+func (m *InformersMap) UnsyncedGVKs() []schema.GroupVersionKind {
+	gvks := append([]schema.GroupVersionKind(nil), m.structured.unsyncedGVKs()...)
+	gvks = append(gvks, m.unstructured.unsyncedGVKs()...)
+	gvks = append(gvks, m.metadata.unsyncedGVKs()...)
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+	return gvks
+}
+
+// InformerDump describes the contents of an informer.
+type InformerDump struct {
+	GroupVersionKind schema.GroupVersionKind
+	// Representation is one of "structured", "unstructured" and "metadata".
+	Representation string
+	Synced         bool
+	Keys           []string
+	Objects        []interface{}
+}
+
+// Dump describes the contents of all the informers, sorted by GroupVersionKind.
+// Objects are only included if includeObjects is true.
+func (m *InformersMap) Dump(includeObjects bool) []InformerDump {
+	dumps := m.structured.dump("structured", includeObjects)
+	dumps = append(dumps, m.unstructured.dump("unstructured", includeObjects)...)
+	dumps = append(dumps, m.metadata.dump("metadata", includeObjects)...)
+	sort.SliceStable(dumps, func(i, j int) bool {
+		return dumps[i].GroupVersionKind.String() < dumps[j].GroupVersionKind.String()
+	})
+	return dumps
+}
+
+// GetOptions configures a single call to Get.
+type GetOptions struct {
+	// BlockUntilSynced makes Get wait for the informer to sync if the map
+	// has been started. Defaults to true.
+	BlockUntilSynced *bool
+
+	// Selector is used instead of the configured selector if the
+	// informer is created by this call, Get fails if the informer
+	// exists already with another selector.
+	Selector *Selector
+}
+
 // Get will create a new Informer and add it to the map of InformersMap if none exists.  Returns
 // the Informer from the map.
-func (m *InformersMap) Get(ctx context.Context, gvk schema.GroupVersionKind, obj runtime.Object) (bool, *MapEntry, error) {
+func (m *InformersMap) Get(ctx context.Context, gvk schema.GroupVersionKind, obj runtime.Object, opts GetOptions) (bool, *MapEntry, error) {
 	switch obj.(type) {
 	case *unstructured.Unstructured:
-		return m.unstructured.Get(ctx, gvk, obj)
+		return m.unstructured.Get(ctx, gvk, obj, opts)
 	case *unstructured.UnstructuredList:
-		return m.unstructured.Get(ctx, gvk, obj)
+		return m.unstructured.Get(ctx, gvk, obj, opts)
 	case *metav1.PartialObjectMetadata:
-		return m.metadata.Get(ctx, gvk, obj)
+		return m.metadata.Get(ctx, gvk, obj, opts)
 	case *metav1.PartialObjectMetadataList:
-		return m.metadata.Get(ctx, gvk, obj)
+		return m.metadata.Get(ctx, gvk, obj, opts)
+	default:
+		return m.structured.Get(ctx, gvk, obj, opts)
+	}
+}
+
+// Remove stops and removes the informer for the given GroupVersionKind and
+// object type, if any.
+func (m *InformersMap) Remove(gvk schema.GroupVersionKind, obj runtime.Object) {
+	switch obj.(type) {
+	case *unstructured.Unstructured, *unstructured.UnstructuredList:
+		m.unstructured.Remove(gvk)
+	case *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
+		m.metadata.Remove(gvk)
 	default:
-		return m.structured.Get(ctx, gvk, obj)
+		m.structured.Remove(gvk)
 	}
 }
 
 // newStructuredInformersMap creates a new InformersMap for structured objects.
-func newStructuredInformersMap(config *rest.Config, scheme *runtime.Scheme, mapper meta.RESTMapper, resync time.Duration,
-	namespace string, selectors SelectorsByGVK) *specificInformersMap {
-	return newSpecificInformersMap(config, scheme, mapper, resync, namespace, selectors, createStructuredListWatch)
+func newStructuredInformersMap(config *rest.Config, scheme *runtime.Scheme, mapper meta.RESTMapper, opts InformersMapOptions) *specificInformersMap {
+	return newSpecificInformersMap(config, scheme, mapper, opts, createStructuredListWatch)
 }
 
 // newUnstructuredInformersMap creates a new InformersMap for unstructured objects.
-func newUnstructuredInformersMap(config *rest.Config, scheme *runtime.Scheme, mapper meta.RESTMapper, resync time.Duration,
-	namespace string, selectors SelectorsByGVK) *specificInformersMap {
-	return newSpecificInformersMap(config, scheme, mapper, resync, namespace, selectors, createUnstructuredListWatch)
+func newUnstructuredInformersMap(config *rest.Config, scheme *runtime.Scheme, mapper meta.RESTMapper, opts InformersMapOptions) *specificInformersMap {
+	return newSpecificInformersMap(config, scheme, mapper, opts, createUnstructuredListWatch)
 }
 
 // newMetadataInformersMap creates a new InformersMap for metadata-only objects.
-func newMetadataInformersMap(config *rest.Config, scheme *runtime.Scheme, mapper meta.RESTMapper, resync time.Duration,
-	namespace string, selectors SelectorsByGVK) *specificInformersMap {
-	return newSpecificInformersMap(config, scheme, mapper, resync, namespace, selectors, createMetadataListWatch)
+func newMetadataInformersMap(config *rest.Config, scheme *runtime.Scheme, mapper meta.RESTMapper, opts InformersMapOptions) *specificInformersMap {
+	return newSpecificInformersMap(config, scheme, mapper, opts, createMetadataListWatch)
 }
@@ -20,7 +20,9 @@ import (
 	"context"
 	"fmt"
 	"math/rand"
+	"sort"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
@@ -29,6 +31,7 @@ import (
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/dynamic"
 	"k8s.io/client-go/metadata"
@@ -42,16 +45,14 @@ func init() {
 }
 
 // clientListWatcherFunc knows how to create a ListWatcher.
-type createListWatcherFunc func(gvk schema.GroupVersionKind, ip *specificInformersMap) (*cache.ListWatch, error)
+type createListWatcherFunc func(gvk schema.GroupVersionKind, selector Selector, ip *specificInformersMap) (*cache.ListWatch, error)
 
 // newSpecificInformersMap returns a new specificInformersMap (like
 // the generical InformersMap, except that it doesn't implement WaitForCacheSync).
 func newSpecificInformersMap(config *rest.Config,
 	scheme *runtime.Scheme,
 	mapper meta.RESTMapper,
-	resync time.Duration,
-	namespace string,
-	selectors SelectorsByGVK,
+	opts InformersMapOptions,
 	createListWatcher createListWatcherFunc) *specificInformersMap {
 	ip := &specificInformersMap{
 		config:            config,
@@ -60,11 +61,20 @@ func newSpecificInformersMap(config *rest.Config,
 		informersByGVK:    make(map[schema.GroupVersionKind]*MapEntry),
 		codecs:            serializer.NewCodecFactory(scheme),
 		paramCodec:        runtime.NewParameterCodec(scheme),
-		resync:            resync,
+		resync:            opts.Resync,
 		startWait:         make(chan struct{}),
 		createListWatcher: createListWatcher,
-		namespace:         namespace,
-		selectors:         selectors,
+		namespace:         opts.Namespace,
+		selectors:         opts.Selectors,
+		transformers:      opts.Transformers,
+		watchErrorHandler: opts.WatchErrorHandler,
+		listPageSize:      opts.ListPageSize,
+		lazy:              opts.Lazy,
+		idleTTL:           opts.IdleTTL,
+		watchList:         opts.WatchList,
+		compress:          opts.Compress,
+		tombstoneTTL:      opts.TombstoneTTL,
+		newStore:          opts.NewStore,
 	}
 	return ip
 }
@@ -76,6 +86,35 @@ type MapEntry struct {
 
 	// CacheReader wraps Informer and implements the CacheReader interface for a single type
 	Reader CacheReader
+
+	// stop is closed when the informer is removed from the map,
+	// to stop it independently of the other informers.
+	stop chan struct{}
+
+	// used records whether the informer has been read from or had an event
+	// handler added, lazy maps only run informers that have been used.
+	used bool
+
+	// pinned records whether event handlers or indexers have been added to
+	// the informer, which prevents it from being removed when idle.
+	pinned bool
+
+	// lastRead is the time of the last read from the informer, in Unix
+	// nanoseconds. It is accessed atomically.
+	lastRead int64
+
+	// selector is the selector the informer has been created with.
+	selector Selector
+}
+
+// touch records a read from the informer of the entry.
+func (e *MapEntry) touch() {
+	atomic.StoreInt64(&e.lastRead, time.Now().UnixNano())
+}
+
+// idleFor returns the time since the last read from the informer of the entry.
+func (e *MapEntry) idleFor() time.Duration {
+	return time.Since(time.Unix(0, atomic.LoadInt64(&e.lastRead)))
 }
 
 // specificInformersMap create and caches Informers for (runtime.Object, schema.GroupVersionKind) pairs.
@@ -102,10 +141,10 @@ type specificInformersMap struct {
 	// stop is the stop channel to stop informers
 	stop <-chan struct{}
 
-	// resync is the base frequency the informers are resynced
+	// resync is the base frequency the informers are resynced, per GVK.
 	// a 10 percent jitter will be added to the resync period between informers
 	// so that all informers will not send list requests simultaneously.
-	resync time.Duration
+	resync ResyncPeriodByGVK
 
 	// mu guards access to the map
 	mu sync.RWMutex
@@ -129,6 +168,36 @@ type specificInformersMap struct {
 	// selectors are the label or field selectors that will be added to the
 	// ListWatch ListOptions.
 	selectors SelectorsByGVK
+
+	// transformers are applied to the objects before they are
+	// stored in the informers.
+	transformers TransformFuncByGVK
+
+	// watchErrorHandler is set on every informer, if not nil.
+	watchErrorHandler cache.WatchErrorHandler
+
+	// listPageSize is the page size used when listing objects, 0 means
+	// to leave it up to client-go.
+	listPageSize int64
+
+	// lazy defers running informers until they are used.
+	lazy bool
+
+	// idleTTL is the time after which informers that haven't been read
+	// from are removed, 0 means never.
+	idleTTL time.Duration
+
+	// watchList streams initial lists through watches, if supported.
+	watchList bool
+
+	// compress stores objects gzipped, see compressedObject.
+	compress bool
+
+	// tombstoneTTL is how long deleted objects are remembered, 0 means not at all.
+	tombstoneTTL time.Duration
+
+	// newStore creates the stores of the informers, nil means client-go's in-memory store.
+	newStore NewStoreFunc
 }
 
 // Start calls Run on each of the informers and sets started to true.  Blocks on the context.
@@ -143,16 +212,58 @@ func (ip *specificInformersMap) Start(ctx context.Context) {
 
 		// Start each informer
 		for _, informer := range ip.informersByGVK {
-			go informer.Informer.Run(ctx.Done())
+			if ip.shouldRun(informer) {
+				ip.runInformer(informer)
+			}
 		}
 
 		// Set started to true so we immediately start any informers added later.
 		ip.started = true
 		close(ip.startWait)
 	}()
+	collector.register(ip)
+	defer collector.unregister(ip)
+	if ip.idleTTL > 0 {
+		go wait.Until(ip.removeIdleInformers, ip.idleTTL/2, ctx.Done())
+	}
 	<-ctx.Done()
 }
 
+// removeIdleInformers stops and removes the informers that haven't been read
+// from within the idle TTL, unless they are pinned. They are recreated on demand.
+func (ip *specificInformersMap) removeIdleInformers() {
+	var removed []schema.GroupVersionKind
+	ip.mu.Lock()
+	for gvk, entry := range ip.informersByGVK {
+		if !entry.pinned && entry.idleFor() > ip.idleTTL {
+			close(entry.stop)
+			delete(ip.informersByGVK, gvk)
+			removed = append(removed, gvk)
+		}
+	}
+	ip.mu.Unlock()
+
+	for _, gvk := range removed {
+		collector.forget(gvk)
+	}
+}
+
+// pin prevents the informer of the entry from being removed when idle.
+func (ip *specificInformersMap) pin(entry *MapEntry) {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	entry.pinned = true
+}
+
+func (ip *specificInformersMap) hasStarted() bool {
+	select {
+	case <-ip.startWait:
+		return true
+	default:
+		return false
+	}
+}
+
 func (ip *specificInformersMap) waitForStarted(ctx context.Context) bool {
 	select {
 	case <-ip.startWait:
@@ -162,20 +273,145 @@ func (ip *specificInformersMap) waitForStarted(ctx context.Context) bool {
 	}
 }
 
+// shouldRun returns whether the informer of the entry should be running once the
+// map has been started. It must be called with the lock held.
+func (ip *specificInformersMap) shouldRun(entry *MapEntry) bool {
+	return !ip.lazy || entry.used
+}
+
+// use marks the entry as used, and runs its informer if the map is lazy and
+// has already been started.
+func (ip *specificInformersMap) use(entry *MapEntry) {
+	ip.mu.Lock()
+	defer ip.mu.Unlock()
+	if entry.used {
+		return
+	}
+	entry.used = true
+	if ip.lazy && ip.started {
+		ip.runInformer(entry)
+	}
+}
+
+// runInformer runs the informer of the entry in the background until either the
+// map is stopped or the informer is removed from it. It must be called with
+// the lock held, after the map has been started.
+func (ip *specificInformersMap) runInformer(entry *MapEntry) {
+	stop := make(chan struct{})
+	go func() {
+		defer close(stop)
+		select {
+		case <-ip.stop:
+		case <-entry.stop:
+		}
+	}()
+	go entry.Informer.Run(stop)
+}
+
+// Remove stops the informer for the given GroupVersionKind, if any, and
+// removes it from the map. A later Get creates a new informer.
+func (ip *specificInformersMap) Remove(gvk schema.GroupVersionKind) {
+	ip.mu.Lock()
+	entry, ok := ip.informersByGVK[gvk]
+	if !ok {
+		ip.mu.Unlock()
+		return
+	}
+	close(entry.stop)
+	delete(ip.informersByGVK, gvk)
+	ip.mu.Unlock()
+
+	collector.forget(gvk)
+}
+
 // HasSyncedFuncs returns all the HasSynced functions for the informers in this map.
 func (ip *specificInformersMap) HasSyncedFuncs() []cache.InformerSynced {
 	ip.mu.RLock()
 	defer ip.mu.RUnlock()
 	syncedFuncs := make([]cache.InformerSynced, 0, len(ip.informersByGVK))
 	for _, informer := range ip.informersByGVK {
-		syncedFuncs = append(syncedFuncs, informer.Informer.HasSynced)
+		// informers that lazy maps don't run would never sync
+		if ip.shouldRun(informer) {
+			syncedFuncs = append(syncedFuncs, informer.Informer.HasSynced)
+		}
 	}
 	return syncedFuncs
 }
 
+// hasSyncedFor returns whether there is an informer for gvk in this map, and
+// whether it has synced.
+func (ip *specificInformersMap) hasSyncedFor(gvk schema.GroupVersionKind) (found bool, synced bool) {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+	informer, ok := ip.informersByGVK[gvk]
+	if !ok {
+		return false, false
+	}
+	return true, informer.Informer.HasSynced()
+}
+
+// gvkIndexer is the indexer of the informer for a GroupVersionKind.
+type gvkIndexer struct {
+	gvk     schema.GroupVersionKind
+	indexer cache.Indexer
+}
+
+// indexers returns the indexers of all the informers in this map.
+func (ip *specificInformersMap) indexers() []gvkIndexer {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+	indexers := make([]gvkIndexer, 0, len(ip.informersByGVK))
+	for gvk, informer := range ip.informersByGVK {
+		indexers = append(indexers, gvkIndexer{gvk: gvk, indexer: informer.Informer.GetIndexer()})
+	}
+	return indexers
+}
+
+// unsyncedGVKs returns the GroupVersionKinds of the informers in this map that haven't synced yet.
+func (ip *specificInformersMap) unsyncedGVKs() []schema.GroupVersionKind {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+	var gvks []schema.GroupVersionKind
+	for gvk, informer := range ip.informersByGVK {
+		if ip.shouldRun(informer) && !informer.Informer.HasSynced() {
+			gvks = append(gvks, gvk)
+		}
+	}
+	return gvks
+}
+
+// dump describes the contents of the informers in this map.
+func (ip *specificInformersMap) dump(representation string, includeObjects bool) []InformerDump {
+	ip.mu.RLock()
+	defer ip.mu.RUnlock()
+	dumps := make([]InformerDump, 0, len(ip.informersByGVK))
+	for gvk, entry := range ip.informersByGVK {
+		store := entry.Informer.GetStore()
+		dump := InformerDump{
+			GroupVersionKind: gvk,
+			Representation:   representation,
+			Synced:           entry.Informer.HasSynced(),
+			Keys:             store.ListKeys(),
+		}
+		sort.Strings(dump.Keys)
+		if includeObjects {
+			dump.Objects = store.List()
+			if d := entry.Reader.decompressor; d != nil {
+				for i := range dump.Objects {
+					if decompressed, err := d.decompressItem(dump.Objects[i]); err == nil {
+						dump.Objects[i] = decompressed
+					}
+				}
+			}
+		}
+		dumps = append(dumps, dump)
+	}
+	return dumps
+}
+
 // Get will create a new Informer and add it to the map of specificInformersMap if none exists.  Returns
 // the Informer from the map.
-func (ip *specificInformersMap) Get(ctx context.Context, gvk schema.GroupVersionKind, obj runtime.Object) (bool, *MapEntry, error) {
+func (ip *specificInformersMap) Get(ctx context.Context, gvk schema.GroupVersionKind, obj runtime.Object, opts GetOptions) (bool, *MapEntry, error) {
 	// Return the informer if it is found
 	i, started, ok := func() (*MapEntry, bool, bool) {
 		ip.mu.RLock()
@@ -186,12 +422,18 @@ func (ip *specificInformersMap) Get(ctx context.Context, gvk schema.GroupVersion
 
 	if !ok {
 		var err error
-		if i, started, err = ip.addInformerToMap(gvk, obj); err != nil {
+		if i, started, err = ip.addInformerToMap(gvk, obj, opts.Selector); err != nil {
 			return started, nil, err
 		}
 	}
+	// the informer is shared by all the callers, they can't select other objects
+	if opts.Selector != nil && !opts.Selector.equals(i.selector) {
+		return started, nil, fmt.Errorf("the informer for %s has already been created with another selector", gvk)
+	}
 
-	if started && !i.Informer.HasSynced() {
+	// Lazy maps don't run the informer yet, reading from it waits for it to sync instead.
+	shouldBlock := opts.BlockUntilSynced == nil || *opts.BlockUntilSynced
+	if shouldBlock && started && !ip.lazy && !i.Informer.HasSynced() {
 		// Wait for it to sync before returning the Informer so that folks don't read from a stale cache.
 		if !cache.WaitForCacheSync(ctx.Done(), i.Informer.HasSynced) {
 			return started, nil, apierrors.NewTimeoutError(fmt.Sprintf("failed waiting for %T Informer to sync", obj), 0)
@@ -201,7 +443,7 @@ func (ip *specificInformersMap) Get(ctx context.Context, gvk schema.GroupVersion
 	return started, i, nil
 }
 
-func (ip *specificInformersMap) addInformerToMap(gvk schema.GroupVersionKind, obj runtime.Object) (*MapEntry, bool, error) {
+func (ip *specificInformersMap) addInformerToMap(gvk schema.GroupVersionKind, obj runtime.Object, selector *Selector) (*MapEntry, bool, error) {
 	ip.mu.Lock()
 	defer ip.mu.Unlock()
 
@@ -214,18 +456,64 @@ func (ip *specificInformersMap) addInformerToMap(gvk schema.GroupVersionKind, ob
 
 	// Create a NewSharedIndexInformer and add it to the map.
 	var lw *cache.ListWatch
-	lw, err := ip.createListWatcher(gvk, ip)
+	if selector == nil {
+		s := ip.selectors.forGVK(gvk)
+		selector = &s
+	}
+	lw, err := ip.createListWatcher(gvk, *selector, ip)
 	if err != nil {
 		return nil, false, err
 	}
-	ni := cache.NewSharedIndexInformer(lw, obj, resyncPeriod(ip.resync)(), cache.Indexers{
+	lw = paginatingListWatch(lw, ip.listPageSize)
+	lw = transformingListWatch(lw, ip.transformers.forGVK(gvk))
+	lw = instrumentedListWatch(lw, gvk)
+	exampleObj := obj
+	if ip.compress {
+		lw = compressingListWatch(lw)
+		exampleObj = &compressedObject{}
+	}
+	indexers := cache.Indexers{
 		cache.NamespaceIndex: cache.MetaNamespaceIndexFunc,
-	})
+	}
+	var tombstones *tombstoneTracker
+	var ni cache.SharedIndexInformer
+	switch {
+	case ip.newStore != nil || ip.tombstoneTTL > 0:
+		var store cache.Indexer
+		if ip.newStore != nil {
+			store = ip.newStore(gvk, cache.DeletionHandlingMetaNamespaceKeyFunc, indexers)
+		} else {
+			store = cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, indexers)
+		}
+		if ip.tombstoneTTL > 0 {
+			// the deletions are recorded by the store of the informer, before
+			// any event handler or reader can observe the object is gone
+			tombstones = newTombstoneTracker(ip.tombstoneTTL)
+			store = &tombstoneIndexer{Indexer: store, tombstones: tombstones}
+		}
+		ni = newStoreInformer(lw, exampleObj, resyncPeriod(ip.resync.forGVK(gvk))(), store)
+	default:
+		ni = cache.NewSharedIndexInformer(lw, exampleObj, resyncPeriod(ip.resync.forGVK(gvk))(), indexers)
+	}
+	if ip.watchErrorHandler != nil {
+		if err := ni.SetWatchErrorHandler(ip.watchErrorHandler); err != nil {
+			return nil, false, err
+		}
+	}
 	rm, err := ip.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
 	if err != nil {
 		return nil, false, err
 	}
 
+	// the reader decompresses the objects it reads itself, after filtering them
+	indexer := ni.GetIndexer()
+	var d *decompressor
+	if ip.compress {
+		decompressor := newDecompressor(obj)
+		d = &decompressor
+		ni = &decompressingInformer{SharedIndexInformer: ni, decompressor: decompressor}
+	}
+
 	switch obj.(type) {
 	case *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
 		ni = metadataSharedIndexInformerPreserveGVK(gvk, ni)
@@ -234,21 +522,51 @@ func (ip *specificInformersMap) addInformerToMap(gvk schema.GroupVersionKind, ob
 
 	i := &MapEntry{
 		Informer: ni,
-		Reader:   CacheReader{indexer: ni.GetIndexer(), groupVersionKind: gvk, scopeName: rm.Scope.Name()},
+		Reader: CacheReader{
+			indexer:          indexer,
+			groupVersionKind: gvk,
+			scopeName:        rm.Scope.Name(),
+			decompressor:     d,
+			tombstones:       tombstones,
+		},
+		stop:     make(chan struct{}),
+		selector: *selector,
+	}
+	if ip.lazy || ip.idleTTL > 0 {
+		i.touch()
+		i.Informer = &trackingInformer{
+			SharedIndexInformer: ni,
+			onEventHandler: func() {
+				ip.use(i)
+				ip.pin(i)
+			},
+			onIndexers: func() { ip.pin(i) },
+		}
+		i.Reader.beforeRead = func(ctx context.Context) error {
+			i.touch()
+			if !ip.lazy {
+				return nil
+			}
+			ip.use(i)
+			if !cache.WaitForCacheSync(ctx.Done(), ni.HasSynced) {
+				return apierrors.NewTimeoutError(fmt.Sprintf("failed waiting for %T Informer to sync", obj), 0)
+			}
+			return nil
+		}
 	}
 	ip.informersByGVK[gvk] = i
 
 	// Start the Informer if need by
 	// TODO(seans): write thorough tests and document what happens here - can you add indexers?
 	// can you add eventhandlers?
-	if ip.started {
-		go i.Informer.Run(ip.stop)
+	if ip.started && ip.shouldRun(i) {
+		ip.runInformer(i)
 	}
 	return i, ip.started, nil
 }
 
 // newListWatch returns a new ListWatch object that can be used to create a SharedIndexInformer.
-func createStructuredListWatch(gvk schema.GroupVersionKind, ip *specificInformersMap) (*cache.ListWatch, error) {
+func createStructuredListWatch(gvk schema.GroupVersionKind, selector Selector, ip *specificInformersMap) (*cache.ListWatch, error) {
 	// Kubernetes APIs work against Resources, not GroupVersionKinds.  Map the
 	// groupVersionKind to the Resource API we will use.
 	mapping, err := ip.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
@@ -270,9 +588,9 @@ func createStructuredListWatch(gvk schema.GroupVersionKind, ip *specificInformer
 	//  pass in their own contexts instead of relying on this fixed one here.
 	ctx := context.TODO()
 	// Create a new ListWatch for the obj
-	return &cache.ListWatch{
+	lw := &cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-			ip.selectors[gvk].ApplyToList(&opts)
+			selector.ApplyToList(&opts)
 			res := listObj.DeepCopyObject()
 			isNamespaceScoped := ip.namespace != "" && mapping.Scope.Name() != meta.RESTScopeNameRoot
 			err := client.Get().NamespaceIfScoped(ip.namespace, isNamespaceScoped).Resource(mapping.Resource.Resource).VersionedParams(&opts, ip.paramCodec).Do(ctx).Into(res)
@@ -280,16 +598,28 @@ func createStructuredListWatch(gvk schema.GroupVersionKind, ip *specificInformer
 		},
 		// Setup the watch function
 		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
-			ip.selectors[gvk].ApplyToList(&opts)
+			selector.ApplyToList(&opts)
 			// Watch needs to be set to true separately
 			opts.Watch = true
 			isNamespaceScoped := ip.namespace != "" && mapping.Scope.Name() != meta.RESTScopeNameRoot
 			return client.Get().NamespaceIfScoped(ip.namespace, isNamespaceScoped).Resource(mapping.Resource.Resource).VersionedParams(&opts, ip.paramCodec).Watch(ctx)
 		},
-	}, nil
+	}
+	if !ip.watchList {
+		return lw, nil
+	}
+
+	watchList := func(opts metav1.ListOptions) (watch.Interface, error) {
+		selector.ApplyToList(&opts)
+		opts.Watch = true
+		isNamespaceScoped := ip.namespace != "" && mapping.Scope.Name() != meta.RESTScopeNameRoot
+		return client.Get().NamespaceIfScoped(ip.namespace, isNamespaceScoped).Resource(mapping.Resource.Resource).VersionedParams(&opts, ip.paramCodec).
+			Param("sendInitialEvents", "true").Watch(ctx)
+	}
+	return watchListingListWatch(lw, watchList, listObj.DeepCopyObject), nil
 }
 
-func createUnstructuredListWatch(gvk schema.GroupVersionKind, ip *specificInformersMap) (*cache.ListWatch, error) {
+func createUnstructuredListWatch(gvk schema.GroupVersionKind, selector Selector, ip *specificInformersMap) (*cache.ListWatch, error) {
 	// Kubernetes APIs work against Resources, not GroupVersionKinds.  Map the
 	// groupVersionKind to the Resource API we will use.
 	mapping, err := ip.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
@@ -312,7 +642,7 @@ func createUnstructuredListWatch(gvk schema.GroupVersionKind, ip *specificInform
 	// Create a new ListWatch for the obj
 	return &cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-			ip.selectors[gvk].ApplyToList(&opts)
+			selector.ApplyToList(&opts)
 			if ip.namespace != "" && mapping.Scope.Name() != meta.RESTScopeNameRoot {
 				return dynamicClient.Resource(mapping.Resource).Namespace(ip.namespace).List(ctx, opts)
 			}
@@ -320,7 +650,7 @@ func createUnstructuredListWatch(gvk schema.GroupVersionKind, ip *specificInform
 		},
 		// Setup the watch function
 		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
-			ip.selectors[gvk].ApplyToList(&opts)
+			selector.ApplyToList(&opts)
 			// Watch needs to be set to true separately
 			opts.Watch = true
 			if ip.namespace != "" && mapping.Scope.Name() != meta.RESTScopeNameRoot {
@@ -331,7 +661,7 @@ func createUnstructuredListWatch(gvk schema.GroupVersionKind, ip *specificInform
 	}, nil
 }
 
-func createMetadataListWatch(gvk schema.GroupVersionKind, ip *specificInformersMap) (*cache.ListWatch, error) {
+func createMetadataListWatch(gvk schema.GroupVersionKind, selector Selector, ip *specificInformersMap) (*cache.ListWatch, error) {
 	// Kubernetes APIs work against Resources, not GroupVersionKinds.  Map the
 	// groupVersionKind to the Resource API we will use.
 	mapping, err := ip.mapper.RESTMapping(gvk.GroupKind(), gvk.Version)
@@ -357,7 +687,7 @@ func createMetadataListWatch(gvk schema.GroupVersionKind, ip *specificInformersM
 	// create the relevant listwatch
 	return &cache.ListWatch{
 		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
-			ip.selectors[gvk].ApplyToList(&opts)
+			selector.ApplyToList(&opts)
 			if ip.namespace != "" && mapping.Scope.Name() != meta.RESTScopeNameRoot {
 				return client.Resource(mapping.Resource).Namespace(ip.namespace).List(ctx, opts)
 			}
@@ -365,7 +695,7 @@ func createMetadataListWatch(gvk schema.GroupVersionKind, ip *specificInformersM
 		},
 		// Setup the watch function
 		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
-			ip.selectors[gvk].ApplyToList(&opts)
+			selector.ApplyToList(&opts)
 			// Watch needs to be set to true separately
 			opts.Watch = true
 			if ip.namespace != "" && mapping.Scope.Name() != meta.RESTScopeNameRoot {
@@ -376,6 +706,66 @@ func createMetadataListWatch(gvk schema.GroupVersionKind, ip *specificInformersM
 	}, nil
 }
 
+// trackingInformer notifies its map when event handlers or indexers are added
+// to its informer, for lazy maps and maps removing idle informers.
+type trackingInformer struct {
+	cache.SharedIndexInformer
+	onEventHandler func()
+	onIndexers     func()
+}
+
+func (t *trackingInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	t.SharedIndexInformer.AddEventHandler(handler)
+	t.onEventHandler()
+}
+
+func (t *trackingInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	t.SharedIndexInformer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
+	t.onEventHandler()
+}
+
+func (t *trackingInformer) AddIndexers(indexers cache.Indexers) error {
+	if err := t.SharedIndexInformer.AddIndexers(indexers); err != nil {
+		return err
+	}
+	t.onIndexers()
+	return nil
+}
+
+// paginatingListWatch wraps lw so that lists are always requested in pages of
+// pageSize objects.
+//
+// By default, the initial list of an informer is served from the API server's
+// watch cache in one go, as the watch cache ignores the limit for lists at
+// resourceVersion "0". For very large object counts that means huge responses,
+// so those lists are turned into paginated lists from etcd instead.
+func paginatingListWatch(lw *cache.ListWatch, pageSize int64) *cache.ListWatch {
+	if pageSize <= 0 {
+		return lw
+	}
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			opts.Limit = pageSize
+			if opts.ResourceVersion == "0" {
+				opts.ResourceVersion = ""
+			}
+			return lw.ListFunc(opts)
+		},
+		WatchFunc: lw.WatchFunc,
+	}
+}
+
+// ResyncPeriodByGVK associate a GroupVersionKind to the base resync period of its informer.
+// The period stored for the empty GroupVersionKind is used as default.
+type ResyncPeriodByGVK map[schema.GroupVersionKind]time.Duration
+
+func (r ResyncPeriodByGVK) forGVK(gvk schema.GroupVersionKind) time.Duration {
+	if specific, found := r[gvk]; found {
+		return specific
+	}
+	return r[schema.GroupVersionKind{}]
+}
+
 // resyncPeriod returns a function which generates a duration each time it is
 // invoked; this is so that multiple controllers don't get into lock-step and all
 // hammer the apiserver with list requests simultaneously.
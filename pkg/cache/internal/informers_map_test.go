@@ -0,0 +1,82 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/cache"
+)
+
+var _ = Describe("specificInformersMap", func() {
+	var (
+		ip        *specificInformersMap
+		selectors []Selector
+		podGVK    = corev1.SchemeGroupVersion.WithKind("Pod")
+	)
+
+	BeforeEach(func() {
+		selectors = nil
+		mapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(podGVK, meta.RESTScopeNamespace)
+		ip = &specificInformersMap{
+			Scheme:         scheme.Scheme,
+			mapper:         mapper,
+			informersByGVK: map[schema.GroupVersionKind]*MapEntry{},
+			startWait:      make(chan struct{}),
+			createListWatcher: func(_ schema.GroupVersionKind, selector Selector, _ *specificInformersMap) (*cache.ListWatch, error) {
+				selectors = append(selectors, selector)
+				return &cache.ListWatch{}, nil
+			},
+			selectors: SelectorsByGVK{
+				podGVK: {Label: labels.SelectorFromSet(labels.Set{"app": "default"})},
+			},
+		}
+	})
+
+	It("should create the informer with the selector of the call", func() {
+		foo := Selector{Label: labels.SelectorFromSet(labels.Set{"app": "foo"})}
+		_, _, err := ip.Get(context.Background(), podGVK, &corev1.Pod{}, GetOptions{Selector: &foo})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selectors).To(Equal([]Selector{foo}))
+
+		By("getting the informer again with the same selector or without any")
+		_, _, err = ip.Get(context.Background(), podGVK, &corev1.Pod{}, GetOptions{Selector: &Selector{Label: labels.SelectorFromSet(labels.Set{"app": "foo"})}})
+		Expect(err).NotTo(HaveOccurred())
+		_, _, err = ip.Get(context.Background(), podGVK, &corev1.Pod{}, GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selectors).To(HaveLen(1))
+	})
+
+	It("should fail to get an existing informer with another selector", func() {
+		_, _, err := ip.Get(context.Background(), podGVK, &corev1.Pod{}, GetOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(selectors).To(Equal([]Selector{ip.selectors[podGVK]}))
+
+		_, _, err = ip.Get(context.Background(), podGVK, &corev1.Pod{}, GetOptions{Selector: &Selector{}})
+		Expect(err).To(MatchError(ContainSubstring("another selector")))
+		_, _, err = ip.Get(context.Background(), podGVK, &corev1.Pod{}, GetOptions{Selector: &Selector{Label: labels.SelectorFromSet(labels.Set{"app": "default"})}})
+		Expect(err).NotTo(HaveOccurred())
+	})
+})
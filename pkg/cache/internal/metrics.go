@@ -0,0 +1,224 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+const (
+	// sizeSampleCount is the number of objects per informer whose size is
+	// measured to estimate the memory used by the informer.
+	sizeSampleCount = 10
+
+	// sizeSampleInterval is the minimum time between two measurements of the
+	// size of the objects of an informer.
+	sizeSampleInterval = 5 * time.Minute
+)
+
+var (
+	// WatchErrors is a prometheus counter metric which holds the number of
+	// times the watches of the informers for a GroupVersionKind failed, either
+	// to start or with an error event. Watches ending normally, e.g. on their
+	// timeout, aren't counted.
+	WatchErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_cache_watch_errors_total",
+		Help: "Total number of watch errors per GroupVersionKind",
+	}, []string{"group", "version", "kind"})
+
+	// LastSyncTimestamp is a prometheus gauge metric which holds the time
+	// the informers for a GroupVersionKind last completed listing their objects.
+	LastSyncTimestamp = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_cache_last_sync_timestamp_seconds",
+		Help: "Unix time of the last successful list per GroupVersionKind",
+	}, []string{"group", "version", "kind"})
+
+	// objectsDesc and sizeDesc are computed on scrape by the cacheCollector.
+	objectsDesc = prometheus.NewDesc(
+		"controller_runtime_cache_objects",
+		"Number of objects in the cache per GroupVersionKind",
+		[]string{"group", "version", "kind"}, nil,
+	)
+	sizeDesc = prometheus.NewDesc(
+		"controller_runtime_cache_approximate_size_bytes",
+		"Approximate size of the objects in the cache per GroupVersionKind, extrapolated from the serialized size of a sample",
+		[]string{"group", "version", "kind"}, nil,
+	)
+
+	collector = &cacheCollector{
+		maps:    map[*specificInformersMap]struct{}{},
+		samples: map[cache.Indexer]sizeSample{},
+	}
+)
+
+func init() {
+	metrics.Registry.MustRegister(
+		WatchErrors,
+		LastSyncTimestamp,
+		collector,
+	)
+}
+
+// cacheCollector reports the number and the approximate size of the objects
+// stored by the informers of all running informer maps.
+type cacheCollector struct {
+	mu   sync.Mutex
+	maps map[*specificInformersMap]struct{}
+
+	// samples are the last measurements of the size of the objects of each
+	// informer, refreshed every sizeSampleInterval.
+	samples map[cache.Indexer]sizeSample
+}
+
+// sizeSample is the average size of the objects of an informer at some time.
+type sizeSample struct {
+	averageSize float64
+	measured    time.Time
+}
+
+func (c *cacheCollector) register(ip *specificInformersMap) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.maps[ip] = struct{}{}
+}
+
+func (c *cacheCollector) unregister(ip *specificInformersMap) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.maps, ip)
+}
+
+// forget deletes the series of the metrics for gvk once none of the running
+// informer maps has an informer for it, e.g. after it has been removed. It
+// must not be called while holding the lock of an informer map.
+func (c *cacheCollector) forget(gvk schema.GroupVersionKind) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for ip := range c.maps {
+		if found, _ := ip.hasSyncedFor(gvk); found {
+			return
+		}
+	}
+	WatchErrors.DeleteLabelValues(gvk.Group, gvk.Version, gvk.Kind)
+	LastSyncTimestamp.DeleteLabelValues(gvk.Group, gvk.Version, gvk.Kind)
+}
+
+// Describe implements prometheus.Collector.
+func (c *cacheCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- objectsDesc
+	ch <- sizeDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *cacheCollector) Collect(ch chan<- prometheus.Metric) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	var indexers []gvkIndexer
+	for ip := range c.maps {
+		indexers = append(indexers, ip.indexers()...)
+	}
+
+	now := time.Now()
+	samples := make(map[cache.Indexer]sizeSample, len(indexers))
+	objects := map[schema.GroupVersionKind]float64{}
+	sizes := map[schema.GroupVersionKind]float64{}
+	for _, i := range indexers {
+		keys := i.indexer.ListKeys()
+		sample, ok := c.samples[i.indexer]
+		if !ok || sample.averageSize == 0 || now.Sub(sample.measured) > sizeSampleInterval {
+			sample = sizeSample{averageSize: averageSize(i.indexer, keys), measured: now}
+		}
+		samples[i.indexer] = sample
+		objects[i.gvk] += float64(len(keys))
+		sizes[i.gvk] += sample.averageSize * float64(len(keys))
+	}
+	// forget the samples of the informers which don't exist anymore
+	c.samples = samples
+
+	for gvk, count := range objects {
+		ch <- prometheus.MustNewConstMetric(objectsDesc, prometheus.GaugeValue, count, gvk.Group, gvk.Version, gvk.Kind)
+		ch <- prometheus.MustNewConstMetric(sizeDesc, prometheus.GaugeValue, sizes[gvk], gvk.Group, gvk.Version, gvk.Kind)
+	}
+}
+
+// averageSize returns the average JSON-serialized size of the first few
+// objects of keys in indexer.
+func averageSize(indexer cache.Indexer, keys []string) float64 {
+	if len(keys) > sizeSampleCount {
+		keys = keys[:sizeSampleCount]
+	}
+	var sampleSize, sampled int
+	for _, key := range keys {
+		item, exists, err := indexer.GetByKey(key)
+		if err != nil || !exists {
+			continue
+		}
+		b, err := json.Marshal(item)
+		if err != nil {
+			continue
+		}
+		sampleSize += len(b)
+		sampled++
+	}
+	if sampled == 0 {
+		return 0
+	}
+	return float64(sampleSize) / float64(sampled)
+}
+
+// instrumentedListWatch wraps lw to record the watch errors and the
+// time of the last completed list of the informer for gvk.
+func instrumentedListWatch(lw *cache.ListWatch, gvk schema.GroupVersionKind) *cache.ListWatch {
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			list, err := lw.ListFunc(opts)
+			if err != nil {
+				return list, err
+			}
+			// lists are complete once their last page has been received
+			if listMeta, metaErr := meta.ListAccessor(list); metaErr == nil && listMeta.GetContinue() == "" {
+				LastSyncTimestamp.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).SetToCurrentTime()
+			}
+			return list, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			w, err := lw.WatchFunc(opts)
+			if err != nil {
+				WatchErrors.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Inc()
+				return w, err
+			}
+			return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+				if event.Type == watch.Error {
+					WatchErrors.WithLabelValues(gvk.Group, gvk.Version, gvk.Kind).Inc()
+				}
+				return event, true
+			}), nil
+		},
+	}
+}
@@ -24,14 +24,38 @@ import (
 )
 
 // SelectorsByGVK associate a GroupVersionKind to a field/label selector.
+// The selector stored for the empty GroupVersionKind is used as default.
 type SelectorsByGVK map[schema.GroupVersionKind]Selector
 
+func (s SelectorsByGVK) forGVK(gvk schema.GroupVersionKind) Selector {
+	if specific, found := s[gvk]; found {
+		return specific
+	}
+	return s[schema.GroupVersionKind{}]
+}
+
 // Selector specify the label/field selector to fill in ListOptions.
 type Selector struct {
 	Label labels.Selector
 	Field fields.Selector
 }
 
+// equals returns whether s and other select the same objects.
+func (s Selector) equals(other Selector) bool {
+	return s.key() == other.key()
+}
+
+func (s Selector) key() string {
+	var label, field string
+	if s.Label != nil {
+		label = s.Label.String()
+	}
+	if s.Field != nil {
+		field = s.Field.String()
+	}
+	return label + ";" + field
+}
+
 // ApplyToList fill in ListOptions LabelSelector and FieldSelector if needed.
 func (s Selector) ApplyToList(listOpts *metav1.ListOptions) {
 	if s.Label != nil {
@@ -0,0 +1,242 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/tools/cache"
+)
+
+// NewStoreFunc creates the store of the informer for a GroupVersionKind.
+// The store must use keyFunc to compute the keys of objects, and must
+// support the given indexers as well as indexers added later on.
+type NewStoreFunc func(gvk schema.GroupVersionKind, keyFunc cache.KeyFunc, indexers cache.Indexers) cache.Indexer
+
+// storeInformer is a SharedIndexInformer backed by a custom store.
+//
+// client-go informers always keep their objects in an in-memory store, this
+// one works the same way but with any cache.Indexer, e.g. one keeping most
+// objects on disk, or one recording the deletions of objects. Unlike client-go
+// informers, it calls event handlers synchronously.
+//
+// Like client-go informers, each handler is resynced with its own resync
+// period, which is raised to the period the resyncs are checked with once the
+// informer runs.
+type storeInformer struct {
+	indexer             cache.Indexer
+	listerWatcher       cache.ListerWatcher
+	objectType          runtime.Object
+	defaultResyncPeriod time.Duration
+
+	// now returns the current time, it's overridden in tests
+	now func() time.Time
+
+	// mu guards the fields below, and is held for reading while distributing
+	// events, so that handlers added concurrently don't miss any.
+	mu                sync.RWMutex
+	handlers          []*storeHandler
+	resyncCheckPeriod time.Duration
+	controller        cache.Controller
+	watchErrorHandler cache.WatchErrorHandler
+}
+
+// storeHandler is an event handler of a storeInformer, with its resync period.
+type storeHandler struct {
+	cache.ResourceEventHandler
+
+	// resyncPeriod is 0 if the handler is never resynced
+	resyncPeriod time.Duration
+	nextResync   time.Time
+
+	// resyncing is true if the handler is to be given the resync in progress
+	resyncing bool
+}
+
+var _ cache.SharedIndexInformer = &storeInformer{}
+
+func newStoreInformer(lw cache.ListerWatcher, objType runtime.Object, resync time.Duration, indexer cache.Indexer) *storeInformer {
+	return &storeInformer{
+		indexer:             indexer,
+		listerWatcher:       lw,
+		objectType:          objType,
+		defaultResyncPeriod: resync,
+		resyncCheckPeriod:   resync,
+		now:                 time.Now,
+	}
+}
+
+// Run runs the informer until stopCh is closed.
+func (s *storeInformer) Run(stopCh <-chan struct{}) {
+	fifo := cache.NewDeltaFIFOWithOptions(cache.DeltaFIFOOptions{
+		KnownObjects:          s.indexer,
+		EmitDeltaTypeReplaced: true,
+	})
+
+	s.mu.Lock()
+	s.controller = cache.New(&cache.Config{
+		Queue:             fifo,
+		ListerWatcher:     s.listerWatcher,
+		ObjectType:        s.objectType,
+		FullResyncPeriod:  s.resyncCheckPeriod,
+		ShouldResync:      s.shouldResync,
+		Process:           s.handleDeltas,
+		WatchErrorHandler: s.watchErrorHandler,
+	})
+	controller := s.controller
+	s.mu.Unlock()
+
+	controller.Run(stopCh)
+}
+
+// shouldResync is called each time the resyncs are checked, and returns true
+// if any handler is due to be resynced.
+func (s *storeInformer) shouldResync() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+	resync := false
+	for _, handler := range s.handlers {
+		handler.resyncing = handler.resyncPeriod > 0 && !now.Before(handler.nextResync)
+		if handler.resyncing {
+			handler.nextResync = now.Add(handler.resyncPeriod)
+			resync = true
+		}
+	}
+	return resync
+}
+
+// handleDeltas applies the deltas to the store and distributes them to the handlers,
+// the resyncs only to the handlers being resynced.
+func (s *storeInformer) handleDeltas(obj interface{}) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// from oldest to newest
+	for _, d := range obj.(cache.Deltas) {
+		switch d.Type {
+		case cache.Sync, cache.Replaced, cache.Added, cache.Updated:
+			if old, exists, err := s.indexer.Get(d.Object); err == nil && exists {
+				if err := s.indexer.Update(d.Object); err != nil {
+					return err
+				}
+				for _, handler := range s.handlers {
+					if d.Type == cache.Sync && !handler.resyncing {
+						continue
+					}
+					handler.OnUpdate(old, d.Object)
+				}
+			} else {
+				if err := s.indexer.Add(d.Object); err != nil {
+					return err
+				}
+				for _, handler := range s.handlers {
+					handler.OnAdd(d.Object)
+				}
+			}
+		case cache.Deleted:
+			if err := s.indexer.Delete(d.Object); err != nil {
+				return err
+			}
+			for _, handler := range s.handlers {
+				handler.OnDelete(d.Object)
+			}
+		}
+	}
+	return nil
+}
+
+// AddEventHandler adds a handler resynced with the resync period of the informer.
+func (s *storeInformer) AddEventHandler(handler cache.ResourceEventHandler) {
+	s.AddEventHandlerWithResyncPeriod(handler, s.defaultResyncPeriod)
+}
+
+// AddEventHandlerWithResyncPeriod adds a handler, which first gets an add event
+// for every object already in the store. Before the informer runs, a period
+// shorter than the one the resyncs are checked with shortens the latter,
+// afterwards it's raised to it.
+func (s *storeInformer) AddEventHandlerWithResyncPeriod(handler cache.ResourceEventHandler, resyncPeriod time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if resyncPeriod > 0 && (s.resyncCheckPeriod == 0 || resyncPeriod < s.resyncCheckPeriod) {
+		if s.controller == nil {
+			s.resyncCheckPeriod = resyncPeriod
+		} else {
+			resyncPeriod = s.resyncCheckPeriod
+		}
+	}
+	for _, obj := range s.indexer.List() {
+		handler.OnAdd(obj)
+	}
+	s.handlers = append(s.handlers, &storeHandler{
+		ResourceEventHandler: handler,
+		resyncPeriod:         resyncPeriod,
+		nextResync:           s.now().Add(resyncPeriod),
+	})
+}
+
+// GetStore returns the store of the informer.
+func (s *storeInformer) GetStore() cache.Store {
+	return s.indexer
+}
+
+// GetIndexer returns the store of the informer.
+func (s *storeInformer) GetIndexer() cache.Indexer {
+	return s.indexer
+}
+
+// AddIndexers adds indexers to the store.
+func (s *storeInformer) AddIndexers(indexers cache.Indexers) error {
+	return s.indexer.AddIndexers(indexers)
+}
+
+// GetController returns the controller of the informer, nil if it hasn't been run.
+func (s *storeInformer) GetController() cache.Controller {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.controller
+}
+
+// HasSynced returns whether the initial list of objects has been stored.
+func (s *storeInformer) HasSynced() bool {
+	controller := s.GetController()
+	return controller != nil && controller.HasSynced()
+}
+
+// LastSyncResourceVersion returns the resource version observed last.
+func (s *storeInformer) LastSyncResourceVersion() string {
+	controller := s.GetController()
+	if controller == nil {
+		return ""
+	}
+	return controller.LastSyncResourceVersion()
+}
+
+// SetWatchErrorHandler sets the handler of watch errors, before the informer runs.
+func (s *storeInformer) SetWatchErrorHandler(handler cache.WatchErrorHandler) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.controller != nil {
+		return errors.New("informer has already started")
+	}
+	s.watchErrorHandler = handler
+	return nil
+}
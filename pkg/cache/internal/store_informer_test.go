@@ -0,0 +1,132 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// recordingHandler records the events it gets, e.g. "update foo".
+type recordingHandler struct {
+	events []string
+}
+
+func (h *recordingHandler) OnAdd(obj interface{}) {
+	h.events = append(h.events, "add "+obj.(*corev1.Pod).Name)
+}
+
+func (h *recordingHandler) OnUpdate(_, newObj interface{}) {
+	h.events = append(h.events, "update "+newObj.(*corev1.Pod).Name)
+}
+
+func (h *recordingHandler) OnDelete(obj interface{}) {
+	h.events = append(h.events, "delete "+obj.(*corev1.Pod).Name)
+}
+
+var _ = Describe("storeInformer", func() {
+	var (
+		now      time.Time
+		informer *storeInformer
+		foo      = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "foo"}}
+		bar      = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "bar"}}
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		informer = newStoreInformer(&cache.ListWatch{}, &corev1.Pod{}, time.Minute,
+			cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{}))
+		informer.now = func() time.Time { return now }
+	})
+
+	It("should apply the deltas to the store and distribute them to the handlers", func() {
+		handler := &recordingHandler{}
+		informer.AddEventHandler(handler)
+
+		Expect(informer.handleDeltas(cache.Deltas{
+			{Type: cache.Added, Object: foo},
+			{Type: cache.Updated, Object: foo},
+			{Type: cache.Replaced, Object: bar},
+			{Type: cache.Deleted, Object: foo},
+		})).To(Succeed())
+		Expect(handler.events).To(Equal([]string{"add foo", "update foo", "add bar", "delete foo"}))
+		Expect(informer.GetStore().ListKeys()).To(ConsistOf("default/bar"))
+	})
+
+	It("should give the objects already in the store to the handlers added later", func() {
+		Expect(informer.handleDeltas(cache.Deltas{{Type: cache.Added, Object: foo}})).To(Succeed())
+
+		handler := &recordingHandler{}
+		informer.AddEventHandler(handler)
+		Expect(handler.events).To(Equal([]string{"add foo"}))
+	})
+
+	It("should only resync the handlers whose resync period has passed", func() {
+		everyMinute, everyHour, never := &recordingHandler{}, &recordingHandler{}, &recordingHandler{}
+		informer.AddEventHandler(everyMinute)
+		informer.AddEventHandlerWithResyncPeriod(everyHour, time.Hour)
+		informer.AddEventHandlerWithResyncPeriod(never, 0)
+		Expect(informer.handleDeltas(cache.Deltas{{Type: cache.Added, Object: foo}})).To(Succeed())
+
+		now = now.Add(time.Minute)
+		Expect(informer.shouldResync()).To(BeTrue())
+		Expect(informer.handleDeltas(cache.Deltas{{Type: cache.Sync, Object: foo}})).To(Succeed())
+
+		now = now.Add(time.Hour)
+		Expect(informer.shouldResync()).To(BeTrue())
+		Expect(informer.handleDeltas(cache.Deltas{{Type: cache.Sync, Object: foo}})).To(Succeed())
+
+		Expect(everyMinute.events).To(Equal([]string{"add foo", "update foo", "update foo"}))
+		Expect(everyHour.events).To(Equal([]string{"add foo", "update foo"}))
+		Expect(never.events).To(Equal([]string{"add foo"}))
+
+		now = now.Add(time.Second)
+		Expect(informer.shouldResync()).To(BeFalse())
+	})
+
+	It("should check the resyncs with the shortest resync period before it runs", func() {
+		informer.AddEventHandlerWithResyncPeriod(&recordingHandler{}, 10*time.Second)
+		Expect(informer.resyncCheckPeriod).To(Equal(10 * time.Second))
+		Expect(informer.handlers[0].resyncPeriod).To(Equal(10 * time.Second))
+	})
+
+	It("should raise the resync periods of the handlers added once it runs to the resync check period", func() {
+		informer.listerWatcher = &cache.ListWatch{
+			ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+				return &corev1.PodList{}, nil
+			},
+			WatchFunc: func(metav1.ListOptions) (watch.Interface, error) {
+				return watch.NewFake(), nil
+			},
+		}
+		stop := make(chan struct{})
+		defer close(stop)
+		go informer.Run(stop)
+		Eventually(informer.GetController).ShouldNot(BeNil())
+
+		informer.AddEventHandlerWithResyncPeriod(&recordingHandler{}, 10*time.Second)
+		Expect(informer.resyncCheckPeriod).To(Equal(time.Minute))
+		Expect(informer.handlers[0].resyncPeriod).To(Equal(time.Minute))
+	})
+})
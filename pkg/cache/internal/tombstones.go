@@ -0,0 +1,100 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/tools/cache"
+)
+
+// tombstoneTracker records the keys of the objects deleted within the last ttl.
+type tombstoneTracker struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	deleted   map[string]time.Time
+	lastPrune time.Time
+	now       func() time.Time
+}
+
+func newTombstoneTracker(ttl time.Duration) *tombstoneTracker {
+	return &tombstoneTracker{ttl: ttl, deleted: map[string]time.Time{}, now: time.Now}
+}
+
+// forget forgets the deletion of an object that has been recreated.
+func (t *tombstoneTracker) forget(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.deleted, key)
+}
+
+// record records the deletion of an object.
+func (t *tombstoneTracker) record(obj interface{}) {
+	key, err := cache.DeletionHandlingMetaNamespaceKeyFunc(obj)
+	if err != nil {
+		return
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.deleted[key] = now
+	if now.Sub(t.lastPrune) < t.ttl {
+		return
+	}
+	for key, deletedAt := range t.deleted {
+		if now.Sub(deletedAt) >= t.ttl {
+			delete(t.deleted, key)
+		}
+	}
+	t.lastPrune = now
+}
+
+// recentlyDeleted returns whether the object with the given store key has
+// been deleted within the ttl.
+func (t *tombstoneTracker) recentlyDeleted(key string) bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	deletedAt, found := t.deleted[key]
+	return found && t.now().Sub(deletedAt) < t.ttl
+}
+
+// tombstoneIndexer records the deletions of the objects of the indexer with
+// tombstones, synchronously, so that an object missing from the indexer has
+// already been recorded as deleted.
+type tombstoneIndexer struct {
+	cache.Indexer
+	tombstones *tombstoneTracker
+}
+
+// Add implements cache.Store.
+func (i *tombstoneIndexer) Add(obj interface{}) error {
+	i.tombstones.forget(obj)
+	return i.Indexer.Add(obj)
+}
+
+// Delete implements cache.Store, the deletion is recorded before the object is
+// removed from the indexer.
+func (i *tombstoneIndexer) Delete(obj interface{}) error {
+	i.tombstones.record(obj)
+	return i.Indexer.Delete(obj)
+}
@@ -0,0 +1,81 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/cache"
+)
+
+var _ = Describe("tombstoneTracker", func() {
+	var (
+		now        time.Time
+		tombstones *tombstoneTracker
+		indexer    cache.Indexer
+		pod        = &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "pod"}}
+	)
+
+	BeforeEach(func() {
+		now = time.Now()
+		tombstones = newTombstoneTracker(time.Minute)
+		tombstones.now = func() time.Time { return now }
+		indexer = &tombstoneIndexer{
+			Indexer:    cache.NewIndexer(cache.DeletionHandlingMetaNamespaceKeyFunc, cache.Indexers{}),
+			tombstones: tombstones,
+		}
+	})
+
+	It("should record the deletions before the objects are removed from the store", func() {
+		Expect(indexer.Add(pod)).To(Succeed())
+		Expect(tombstones.recentlyDeleted("default/pod")).To(BeFalse())
+
+		Expect(indexer.Delete(pod)).To(Succeed())
+		_, exists, err := indexer.GetByKey("default/pod")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeFalse())
+		Expect(tombstones.recentlyDeleted("default/pod")).To(BeTrue())
+	})
+
+	It("should record the deletions of objects whose final state is unknown", func() {
+		Expect(indexer.Add(pod)).To(Succeed())
+		Expect(indexer.Delete(cache.DeletedFinalStateUnknown{Key: "default/pod", Obj: pod})).To(Succeed())
+		Expect(tombstones.recentlyDeleted("default/pod")).To(BeTrue())
+	})
+
+	It("should forget the deletions of recreated objects", func() {
+		Expect(indexer.Delete(pod)).To(Succeed())
+		Expect(indexer.Add(pod)).To(Succeed())
+		Expect(tombstones.recentlyDeleted("default/pod")).To(BeFalse())
+	})
+
+	It("should forget the deletions older than the ttl", func() {
+		Expect(indexer.Delete(pod)).To(Succeed())
+		now = now.Add(time.Minute)
+		Expect(tombstones.recentlyDeleted("default/pod")).To(BeFalse())
+
+		By("pruning them once per ttl")
+		other := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other"}}
+		Expect(indexer.Delete(other)).To(Succeed())
+		Expect(tombstones.deleted).To(HaveLen(1))
+		Expect(tombstones.deleted).To(HaveKey("default/other"))
+	})
+})
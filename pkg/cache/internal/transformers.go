@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// TransformFunc allows for transforming an object before it is stored in an
+// informer's store.  It has the same signature as the transform functions of
+// newer client-go informers.
+type TransformFunc func(interface{}) (interface{}, error)
+
+// TransformFuncByGVK associate a GroupVersionKind to a transform function.
+// The function stored for the empty GroupVersionKind is used as default.
+type TransformFuncByGVK map[schema.GroupVersionKind]TransformFunc
+
+func (t TransformFuncByGVK) forGVK(gvk schema.GroupVersionKind) TransformFunc {
+	if specific, found := t[gvk]; found {
+		return specific
+	}
+	return t[schema.GroupVersionKind{}]
+}
+
+// transformingListWatch wraps lw so that every object it lists or watches
+// is passed through transform before it reaches the informer.
+func transformingListWatch(lw *cache.ListWatch, transform TransformFunc) *cache.ListWatch {
+	if transform == nil {
+		return lw
+	}
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			list, err := lw.ListFunc(opts)
+			if err != nil {
+				return nil, err
+			}
+			items, err := meta.ExtractList(list)
+			if err != nil {
+				return nil, err
+			}
+			for i := range items {
+				if items[i], err = transformObject(transform, items[i]); err != nil {
+					return nil, err
+				}
+			}
+			if err := meta.SetList(list, items); err != nil {
+				return nil, err
+			}
+			return list, nil
+		},
+		WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+			w, err := lw.WatchFunc(opts)
+			if err != nil {
+				return nil, err
+			}
+			return watch.Filter(w, func(event watch.Event) (watch.Event, bool) {
+				if event.Type == watch.Bookmark || event.Type == watch.Error {
+					return event, true
+				}
+				obj, err := transformObject(transform, event.Object)
+				if err != nil {
+					// surface the error to the reflector, which will restart the watch
+					return watch.Event{Type: watch.Error, Object: &apierrors.NewInternalError(err).ErrStatus}, true
+				}
+				event.Object = obj
+				return event, true
+			}), nil
+		},
+	}
+}
+
+func transformObject(transform TransformFunc, obj runtime.Object) (runtime.Object, error) {
+	transformed, err := transform(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to transform %T: %w", obj, err)
+	}
+	transformedObj, ok := transformed.(runtime.Object)
+	if !ok {
+		return nil, fmt.Errorf("transforming %T returned %T, which is not a runtime.Object", obj, transformed)
+	}
+	return transformedObj, nil
+}
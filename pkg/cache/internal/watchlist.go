@@ -0,0 +1,169 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+const (
+	// initialEventsEndAnnotation is set on the bookmark event that marks the
+	// end of the initial events of a watch with sendInitialEvents=true.
+	initialEventsEndAnnotation = "k8s.io/initial-events-end"
+
+	// watchListTimeout bounds how long streaming the initial list may take,
+	// servers ignoring sendInitialEvents never send the final bookmark.
+	watchListTimeout = 5 * time.Minute
+
+	// watchListInitialBackoff and watchListMaxBackoff bound the time between
+	// two attempts to stream lists from a server which didn't support it.
+	watchListInitialBackoff = time.Minute
+	watchListMaxBackoff     = time.Hour
+)
+
+// errInitialEventsEndMissing is returned when a watch ends before the server
+// marked the end of the initial events.
+var errInitialEventsEndMissing = errors.New("watch ended before the end of the initial events")
+
+// watchListFunc starts a watch with sendInitialEvents=true for the given options.
+type watchListFunc func(opts metav1.ListOptions) (watch.Interface, error)
+
+// watchListingListWatch wraps lw so that lists are served by streaming the
+// objects through a watch (the WatchList feature), which the API server serves
+// from its watch cache instead of doing an expensive list from etcd.
+//
+// If the server doesn't support streaming lists, it falls back to the list
+// function of lw, and retries streaming with an exponential backoff, e.g.
+// in case the server is upgraded.
+func watchListingListWatch(lw *cache.ListWatch, watchList watchListFunc, newList func() runtime.Object) *cache.ListWatch {
+	backoff := &watchListBackoff{}
+	return &cache.ListWatch{
+		ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+			if backoff.shouldTry(time.Now()) {
+				list, err := listByWatching(watchList, newList, opts)
+				if err == nil {
+					backoff.succeeded()
+					return list, nil
+				}
+				// older servers reject resourceVersionMatch for watches, and
+				// servers without the feature gate reject sendInitialEvents.
+				if apierrors.IsBadRequest(err) || apierrors.IsInvalid(err) || errors.Is(err, errInitialEventsEndMissing) {
+					backoff.failed(time.Now())
+				}
+			}
+			return lw.ListFunc(opts)
+		},
+		WatchFunc: lw.WatchFunc,
+	}
+}
+
+// watchListBackoff delays streaming lists again after the server didn't support it.
+type watchListBackoff struct {
+	mu       sync.Mutex
+	failures int
+	retryAt  time.Time
+}
+
+// shouldTry returns true if streaming lists should be tried at now.
+func (b *watchListBackoff) shouldTry(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return !now.Before(b.retryAt)
+}
+
+// failed delays the next attempt, doubling the delay on each consecutive failure.
+func (b *watchListBackoff) failed(now time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	delay := watchListInitialBackoff
+	for i := 0; i < b.failures && delay < watchListMaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > watchListMaxBackoff {
+		delay = watchListMaxBackoff
+	}
+	b.failures++
+	b.retryAt = now.Add(delay)
+}
+
+// succeeded resets the delay.
+func (b *watchListBackoff) succeeded() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.retryAt = time.Time{}
+}
+
+// listByWatching collects the initial events of a watch into a list.
+func listByWatching(watchList watchListFunc, newList func() runtime.Object, opts metav1.ListOptions) (runtime.Object, error) {
+	opts.AllowWatchBookmarks = true
+	opts.ResourceVersionMatch = metav1.ResourceVersionMatchNotOlderThan
+	if opts.ResourceVersion == "0" {
+		opts.ResourceVersion = ""
+	}
+	opts.Limit = 0
+	opts.Continue = ""
+	timeoutSeconds := int64(watchListTimeout.Seconds())
+	opts.TimeoutSeconds = &timeoutSeconds
+
+	w, err := watchList(opts)
+	if err != nil {
+		return nil, err
+	}
+	defer w.Stop()
+
+	var items []runtime.Object
+	for event := range w.ResultChan() {
+		switch event.Type {
+		case watch.Added:
+			items = append(items, event.Object)
+		case watch.Bookmark:
+			obj, err := meta.Accessor(event.Object)
+			if err != nil {
+				return nil, err
+			}
+			if obj.GetAnnotations()[initialEventsEndAnnotation] != "true" {
+				continue
+			}
+			list := newList()
+			if err := meta.SetList(list, items); err != nil {
+				return nil, err
+			}
+			listAccessor, err := meta.ListAccessor(list)
+			if err != nil {
+				return nil, err
+			}
+			listAccessor.SetResourceVersion(obj.GetResourceVersion())
+			return list, nil
+		case watch.Error:
+			return nil, apierrors.FromObject(event.Object)
+		default:
+			return nil, fmt.Errorf("unexpected %s event while streaming the initial list", event.Type)
+		}
+	}
+	return nil, errInitialEventsEndMissing
+}
@@ -0,0 +1,199 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/tools/cache"
+)
+
+// fakeWatchList returns a watchListFunc whose watches send the given events, and records the options
+// of the watches into opts.
+func fakeWatchList(opts *metav1.ListOptions, events ...watch.Event) watchListFunc {
+	return func(o metav1.ListOptions) (watch.Interface, error) {
+		*opts = o
+		ch := make(chan watch.Event, len(events))
+		for _, evt := range events {
+			ch <- evt
+		}
+		close(ch)
+		return watch.NewProxyWatcher(ch), nil
+	}
+}
+
+func watchListPod(name string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: name}}
+}
+
+func initialEventsEnd(resourceVersion string) *corev1.Pod {
+	return &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+		ResourceVersion: resourceVersion,
+		Annotations:     map[string]string{initialEventsEndAnnotation: "true"},
+	}}
+}
+
+func newPodList() runtime.Object {
+	return &corev1.PodList{}
+}
+
+var _ = Describe("listByWatching", func() {
+	table.DescribeTable("should collect the initial events",
+		func(events []watch.Event, expected *corev1.PodList, expectedErr interface{}) {
+			var opts metav1.ListOptions
+			list, err := listByWatching(fakeWatchList(&opts, events...), newPodList, metav1.ListOptions{
+				ResourceVersion: "0",
+				Limit:           500,
+				Continue:        "continue",
+			})
+			if expectedErr != nil {
+				Expect(err).To(MatchError(expectedErr))
+			} else {
+				Expect(err).NotTo(HaveOccurred())
+				Expect(list).To(Equal(expected))
+			}
+
+			Expect(opts.AllowWatchBookmarks).To(BeTrue())
+			Expect(opts.ResourceVersionMatch).To(Equal(metav1.ResourceVersionMatchNotOlderThan))
+			Expect(opts.ResourceVersion).To(BeEmpty())
+			Expect(opts.Limit).To(BeZero())
+			Expect(opts.Continue).To(BeEmpty())
+			Expect(opts.TimeoutSeconds).NotTo(BeNil())
+		},
+		table.Entry("until the bookmark marking their end",
+			[]watch.Event{
+				{Type: watch.Added, Object: watchListPod("foo")},
+				{Type: watch.Bookmark, Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{ResourceVersion: "1"}}},
+				{Type: watch.Added, Object: watchListPod("bar")},
+				{Type: watch.Bookmark, Object: initialEventsEnd("2")},
+				{Type: watch.Added, Object: watchListPod("baz")},
+			},
+			&corev1.PodList{
+				ListMeta: metav1.ListMeta{ResourceVersion: "2"},
+				Items:    []corev1.Pod{*watchListPod("foo"), *watchListPod("bar")},
+			},
+			nil),
+		table.Entry("into an empty list",
+			[]watch.Event{{Type: watch.Bookmark, Object: initialEventsEnd("1")}},
+			&corev1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}, Items: []corev1.Pod{}},
+			nil),
+		table.Entry("and fail if the watch ends before the bookmark marking their end",
+			[]watch.Event{{Type: watch.Added, Object: watchListPod("foo")}},
+			nil,
+			errInitialEventsEndMissing),
+		table.Entry("and fail on error events",
+			[]watch.Event{{Type: watch.Error, Object: &metav1.Status{
+				Status: metav1.StatusFailure, Reason: metav1.StatusReasonGone, Message: "too old resource version",
+			}}},
+			nil,
+			"too old resource version"),
+	)
+})
+
+var _ = Describe("watchListingListWatch", func() {
+	var (
+		podsGR = schema.GroupResource{Resource: "pods"}
+		listed *corev1.PodList
+	)
+
+	BeforeEach(func() {
+		listed = &corev1.PodList{Items: []corev1.Pod{*watchListPod("listed")}}
+	})
+
+	table.DescribeTable("should fall back to listing",
+		func(watchErr error, fallsBack, backsOff bool) {
+			watches, lists := 0, 0
+			lw := watchListingListWatch(&cache.ListWatch{
+				ListFunc: func(metav1.ListOptions) (runtime.Object, error) {
+					lists++
+					return listed, nil
+				},
+			}, func(opts metav1.ListOptions) (watch.Interface, error) {
+				watches++
+				if watchErr != nil {
+					return nil, watchErr
+				}
+				return fakeWatchList(&opts, watch.Event{Type: watch.Bookmark, Object: initialEventsEnd("1")})(opts)
+			}, newPodList)
+
+			list, err := lw.List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			if fallsBack {
+				Expect(list).To(Equal(listed))
+			} else {
+				Expect(list).To(Equal(&corev1.PodList{ListMeta: metav1.ListMeta{ResourceVersion: "1"}, Items: []corev1.Pod{}}))
+			}
+
+			_, err = lw.List(metav1.ListOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			if backsOff {
+				Expect(watches).To(Equal(1))
+			} else {
+				Expect(watches).To(Equal(2))
+			}
+			if fallsBack {
+				Expect(lists).To(Equal(2))
+			} else {
+				Expect(lists).To(BeZero())
+			}
+		},
+		table.Entry("never when the server streams lists", nil, false, false),
+		table.Entry("and back off when the server rejects the options of the watch",
+			apierrors.NewBadRequest("resourceVersionMatch is forbidden for watch"), true, true),
+		table.Entry("and back off when the server rejects sendInitialEvents",
+			apierrors.NewInvalid(schema.GroupKind{Kind: "ListOptions"}, "", nil), true, true),
+		table.Entry("and back off when the server doesn't mark the end of the initial events",
+			errInitialEventsEndMissing, true, true),
+		table.Entry("without backing off on other errors",
+			apierrors.NewServiceUnavailable("unavailable"), true, false),
+		table.Entry("without backing off when the resources are gone",
+			apierrors.NewNotFound(podsGR, ""), true, false),
+	)
+})
+
+var _ = Describe("watchListBackoff", func() {
+	table.DescribeTable("should delay the next attempt",
+		func(failures int, expected time.Duration) {
+			now := time.Now()
+			backoff := &watchListBackoff{}
+			for i := 0; i < failures; i++ {
+				backoff.failed(now)
+			}
+			Expect(backoff.shouldTry(now.Add(expected - time.Second))).To(BeFalse())
+			Expect(backoff.shouldTry(now.Add(expected))).To(BeTrue())
+
+			backoff.succeeded()
+			Expect(backoff.shouldTry(now)).To(BeTrue())
+		},
+		table.Entry("by the initial backoff after a failure", 1, watchListInitialBackoff),
+		table.Entry("doubling on each consecutive failure", 3, 4*watchListInitialBackoff),
+		table.Entry("up to the max backoff", 10, watchListMaxBackoff),
+	)
+
+	It("should try streaming lists until it fails", func() {
+		Expect((&watchListBackoff{}).shouldTry(time.Now())).To(BeTrue())
+	})
+})
@@ -19,6 +19,9 @@ package cache
 import (
 	"context"
 	"fmt"
+	"reflect"
+	"sort"
+	"sync"
 	"time"
 
 	corev1 "k8s.io/api/core/v1"
@@ -28,6 +31,7 @@ import (
 	"k8s.io/client-go/rest"
 	toolscache "k8s.io/client-go/tools/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/internal/objectutil"
 )
 
@@ -43,6 +47,9 @@ const globalCache = "_cluster-scope"
 // a global cache for cluster scoped resource. Note that this is not intended
 // to be used for excluding namespaces, this is better done via a Predicate. Also note that
 // you may face performance issues when using this with a high number of namespaces.
+//
+// The returned cache implements DynamicNamespaces, so namespaces can be added
+// and removed while it is running, e.g. from a controller watching Namespaces.
 func MultiNamespacedCacheBuilder(namespaces []string) NewCacheFunc {
 	return func(config *rest.Config, opts Options) (Cache, error) {
 		opts, err := defaultOpts(config, opts)
@@ -58,35 +65,99 @@ func MultiNamespacedCacheBuilder(namespaces []string) NewCacheFunc {
 			return nil, fmt.Errorf("error creating global cache %v", err)
 		}
 
+		newNamespacedCache := func(ns string) (Cache, error) {
+			nsOpts := opts
+			nsOpts.Namespace = ns
+			return New(config, nsOpts)
+		}
+
 		for _, ns := range namespaces {
-			opts.Namespace = ns
-			c, err := New(config, opts)
+			c, err := newNamespacedCache(ns)
 			if err != nil {
 				return nil, err
 			}
 			caches[ns] = c
 		}
-		return &multiNamespaceCache{namespaceToCache: caches, Scheme: opts.Scheme, RESTMapper: opts.Mapper, clusterCache: gCache}, nil
+		return &multiNamespaceCache{
+			namespaceToCache:   caches,
+			Scheme:             opts.Scheme,
+			RESTMapper:         opts.Mapper,
+			clusterCache:       gCache,
+			newNamespacedCache: newNamespacedCache,
+			namespaceToCancel:  map[string]context.CancelFunc{},
+			informers:          map[informerKey]*multiNamespaceInformer{},
+		}, nil
 	}
 }
 
+// DynamicNamespaces is implemented by caches whose set of namespaces can be
+// changed after they have been created, such as the caches returned by
+// MultiNamespacedCacheBuilder.
+type DynamicNamespaces interface {
+	// AddNamespace starts caching objects of the given namespace. Informers
+	// that were already requested from the cache, including their event
+	// handlers and indexers, are extended to the new namespace. If the cache
+	// is running, the new informers are started right away; use
+	// WaitForCacheSync to wait for them to sync.
+	// Adding a namespace that is already cached is a no-op.
+	AddNamespace(ctx context.Context, namespace string) error
+
+	// RemoveNamespace stops caching objects of the given namespace and drops
+	// them from the cache. Note that event handlers do not receive delete
+	// events for the dropped objects.
+	// Removing a namespace that is not cached is a no-op.
+	RemoveNamespace(namespace string) error
+
+	// Namespaces returns the namespaces currently cached.
+	Namespaces() []string
+}
+
+// informerKey identifies the informers handed out by a multiNamespaceCache.
+// The object type is part of it because structured, unstructured and
+// metadata-only objects of the same GVK are backed by different informers.
+type informerKey struct {
+	gvk     schema.GroupVersionKind
+	objType reflect.Type
+}
+
 // multiNamespaceCache knows how to handle multiple namespaced caches
 // Use this feature when scoping permissions for your
 // operator to a list of namespaces instead of watching every namespace
 // in the cluster.
 type multiNamespaceCache struct {
+	mu               sync.RWMutex
 	namespaceToCache map[string]Cache
 	Scheme           *runtime.Scheme
 	RESTMapper       apimeta.RESTMapper
 	clusterCache     Cache
+
+	// newNamespacedCache creates the cache for a namespace added at runtime.
+	newNamespacedCache func(namespace string) (Cache, error)
+	// startCtx is the context the cache has been started with, nil if it
+	// hasn't been started yet.
+	startCtx context.Context
+	// namespaceToCancel stops the caches of the individual namespaces.
+	namespaceToCancel map[string]context.CancelFunc
+	// informers are the namespaced informers handed out so far, kept around
+	// so they can be extended to namespaces added later on.
+	informers map[informerKey]*multiNamespaceInformer
 }
 
 var _ Cache = &multiNamespaceCache{}
+var _ DynamicNamespaces = &multiNamespaceCache{}
+var _ InformerRemover = &multiNamespaceCache{}
+var _ KindSyncChecker = &multiNamespaceCache{}
+var _ InformerGetterWithOptions = &multiNamespaceCache{}
 
 // Methods for multiNamespaceCache to conform to the Informers interface.
 func (c *multiNamespaceCache) GetInformer(ctx context.Context, obj client.Object) (Informer, error) {
-	informers := map[string]Informer{}
+	return c.GetInformerWithOptions(ctx, obj)
+}
 
+// GetInformerWithOptions implements InformerGetterWithOptions. The options are
+// passed to the informers of each namespace, which return an error if they exist
+// already with another selector.
+func (c *multiNamespaceCache) GetInformerWithOptions(ctx context.Context, obj client.Object, opts ...InformerGetOption) (Informer, error) {
 	// If the object is clusterscoped, get the informer from clusterCache,
 	// if not use the namespaced caches.
 	isNamespaced, err := objectutil.IsAPINamespaced(obj, c.Scheme, c.RESTMapper)
@@ -94,54 +165,114 @@ func (c *multiNamespaceCache) GetInformer(ctx context.Context, obj client.Object
 		return nil, err
 	}
 	if !isNamespaced {
-		clusterCacheInf, err := c.clusterCache.GetInformer(ctx, obj)
+		clusterCacheInf, err := getInformer(ctx, c.clusterCache, obj, opts)
 		if err != nil {
 			return nil, err
 		}
-		informers[globalCache] = clusterCacheInf
+		informers := map[string]Informer{globalCache: clusterCacheInf}
 
 		return &multiNamespaceInformer{namespaceToInformer: informers}, nil
 	}
 
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme)
+	if err != nil {
+		return nil, err
+	}
+	key := informerKey{gvk: gvk, objType: reflect.TypeOf(obj)}
+
+	c.mu.RLock()
+	informer, ok := c.informers[key]
+	c.mu.RUnlock()
+	if ok {
+		// The informer might have been handed out before the cache was started, e.g. by
+		// IndexField: get it from the namespaced caches again, which waits for it to sync.
+		for _, cache := range c.namespacedCaches() {
+			if _, err := getInformer(ctx, cache, obj, opts); err != nil {
+				return nil, err
+			}
+		}
+		return informer, nil
+	}
+
+	// Getting an informer from a started cache blocks until it has synced,
+	// so don't hold the lock while doing so.
+	informers := map[string]Informer{}
+	for ns, cache := range c.namespacedCaches() {
+		informer, err := getInformer(ctx, cache, obj, opts)
+		if err != nil {
+			return nil, err
+		}
+		informers[ns] = informer
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if informer, ok := c.informers[key]; ok {
+		return informer, nil
+	}
+	// namespaces might have been added or removed in the meantime
+	for ns := range informers {
+		if _, ok := c.namespaceToCache[ns]; !ok {
+			delete(informers, ns)
+		}
+	}
 	for ns, cache := range c.namespaceToCache {
-		informer, err := cache.GetInformer(ctx, obj)
+		if _, ok := informers[ns]; ok {
+			continue
+		}
+		informer, err := getInformer(ctx, cache, obj, opts)
 		if err != nil {
 			return nil, err
 		}
 		informers[ns] = informer
 	}
 
-	return &multiNamespaceInformer{namespaceToInformer: informers}, nil
+	informer = &multiNamespaceInformer{namespaceToInformer: informers, obj: obj, getOpts: opts}
+	c.informers[key] = informer
+	return informer, nil
 }
 
 func (c *multiNamespaceCache) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (Informer, error) {
-	informers := map[string]Informer{}
+	return c.GetInformerForKindWithOptions(ctx, gvk)
+}
 
-	// If the object is clusterscoped, get the informer from clusterCache,
-	// if not use the namespaced caches.
-	isNamespaced, err := objectutil.IsAPINamespacedWithGVK(gvk, c.Scheme, c.RESTMapper)
+// GetInformerForKindWithOptions implements InformerGetterWithOptions.
+func (c *multiNamespaceCache) GetInformerForKindWithOptions(ctx context.Context, gvk schema.GroupVersionKind, opts ...InformerGetOption) (Informer, error) {
+	// Map the gvk to an object, the same way the namespaced caches do.
+	obj, err := c.Scheme.New(gvk)
 	if err != nil {
 		return nil, err
 	}
+	cObj, ok := obj.(client.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T is not a client.Object", obj)
+	}
+	return c.GetInformerWithOptions(ctx, cObj, opts...)
+}
+
+func (c *multiNamespaceCache) RemoveInformer(ctx context.Context, obj client.Object) error {
+	isNamespaced, err := objectutil.IsAPINamespaced(obj, c.Scheme, c.RESTMapper)
+	if err != nil {
+		return err
+	}
 	if !isNamespaced {
-		clusterCacheInf, err := c.clusterCache.GetInformerForKind(ctx, gvk)
-		if err != nil {
-			return nil, err
-		}
-		informers[globalCache] = clusterCacheInf
+		return removeInformer(ctx, c.clusterCache, obj)
+	}
 
-		return &multiNamespaceInformer{namespaceToInformer: informers}, nil
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme)
+	if err != nil {
+		return err
 	}
 
-	for ns, cache := range c.namespaceToCache {
-		informer, err := cache.GetInformerForKind(ctx, gvk)
-		if err != nil {
-			return nil, err
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, cache := range c.namespaceToCache {
+		if err := removeInformer(ctx, cache, obj); err != nil {
+			return err
 		}
-		informers[ns] = informer
 	}
-
-	return &multiNamespaceInformer{namespaceToInformer: informers}, nil
+	delete(c.informers, informerKey{gvk: gvk, objType: reflect.TypeOf(obj)})
+	return nil
 }
 
 func (c *multiNamespaceCache) Start(ctx context.Context) error {
@@ -154,22 +285,33 @@ func (c *multiNamespaceCache) Start(ctx context.Context) error {
 	}()
 
 	// start namespaced caches
+	c.mu.Lock()
+	c.startCtx = ctx
 	for ns, cache := range c.namespaceToCache {
-		go func(ns string, cache Cache) {
-			err := cache.Start(ctx)
-			if err != nil {
-				log.Error(err, "multinamespace cache failed to start namespaced informer", "namespace", ns)
-			}
-		}(ns, cache)
+		c.startNamespacedCache(ns, cache)
 	}
+	c.mu.Unlock()
 
 	<-ctx.Done()
 	return nil
 }
 
+// startNamespacedCache starts the cache of a single namespace in the
+// background. It must be called with the lock held.
+func (c *multiNamespaceCache) startNamespacedCache(ns string, cache Cache) {
+	ctx, cancel := context.WithCancel(c.startCtx)
+	c.namespaceToCancel[ns] = cancel
+	go func() {
+		err := cache.Start(ctx)
+		if err != nil {
+			log.Error(err, "multinamespace cache failed to start namespaced informer", "namespace", ns)
+		}
+	}()
+}
+
 func (c *multiNamespaceCache) WaitForCacheSync(ctx context.Context) bool {
 	synced := true
-	for _, cache := range c.namespaceToCache {
+	for _, cache := range c.namespacedCaches() {
 		if s := cache.WaitForCacheSync(ctx); !s {
 			synced = s
 		}
@@ -182,6 +324,90 @@ func (c *multiNamespaceCache) WaitForCacheSync(ctx context.Context) bool {
 	return synced
 }
 
+func (c *multiNamespaceCache) HasSyncedFor(gvk schema.GroupVersionKind) bool {
+	isNamespaced, err := objectutil.IsAPINamespacedWithGVK(gvk, c.Scheme, c.RESTMapper)
+	if err != nil {
+		return false
+	}
+	if !isNamespaced {
+		return hasSyncedFor(c.clusterCache, gvk)
+	}
+
+	caches := c.namespacedCaches()
+	if len(caches) == 0 {
+		return false
+	}
+	for _, cache := range caches {
+		if !hasSyncedFor(cache, gvk) {
+			return false
+		}
+	}
+	return true
+}
+
+func (c *multiNamespaceCache) dump(includeObjects bool) []InformerDump {
+	var dumps []InformerDump
+	if d, ok := c.clusterCache.(dumper); ok {
+		dumps = append(dumps, d.dump(includeObjects)...)
+	}
+	caches := c.namespacedCaches()
+	namespaces := make([]string, 0, len(caches))
+	for ns := range caches {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	for _, ns := range namespaces {
+		d, ok := caches[ns].(dumper)
+		if !ok {
+			continue
+		}
+		for _, dump := range d.dump(includeObjects) {
+			dump.Namespace = ns
+			dumps = append(dumps, dump)
+		}
+	}
+	return dumps
+}
+
+func (c *multiNamespaceCache) checkSynced(objs ...client.Object) error {
+	if len(objs) > 0 {
+		return checkSyncedFor(c, c.Scheme, objs)
+	}
+	caches := []Cache{c.clusterCache}
+	for _, cache := range c.namespacedCaches() {
+		caches = append(caches, cache)
+	}
+	for _, cache := range caches {
+		checker, ok := cache.(readinessChecker)
+		if !ok {
+			continue
+		}
+		if err := checker.checkSynced(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// namespacedCaches returns a snapshot of the caches of all namespaces.
+func (c *multiNamespaceCache) namespacedCaches() map[string]Cache {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	caches := make(map[string]Cache, len(c.namespaceToCache))
+	for ns, cache := range c.namespaceToCache {
+		caches[ns] = cache
+	}
+	return caches
+}
+
+// namespacedCache returns the cache of the given namespace.
+func (c *multiNamespaceCache) namespacedCache(namespace string) (Cache, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	cache, ok := c.namespaceToCache[namespace]
+	return cache, ok
+}
+
 func (c *multiNamespaceCache) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
 	isNamespaced, err := objectutil.IsAPINamespaced(obj, c.Scheme, c.RESTMapper)
 	if err != nil {
@@ -192,14 +418,75 @@ func (c *multiNamespaceCache) IndexField(ctx context.Context, obj client.Object,
 		return c.clusterCache.IndexField(ctx, obj, field, extractValue)
 	}
 
-	for _, cache := range c.namespaceToCache {
-		if err := cache.IndexField(ctx, obj, field, extractValue); err != nil {
-			return err
+	// go through the multiNamespaceInformer so that the index is also
+	// added to namespaces added later on.
+	informer, err := c.GetInformer(ctx, obj)
+	if err != nil {
+		return err
+	}
+	return indexByField(informer, field, extractValue)
+}
+
+// AddNamespace implements DynamicNamespaces.
+func (c *multiNamespaceCache) AddNamespace(ctx context.Context, namespace string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.namespaceToCache[namespace]; ok {
+		return nil
+	}
+
+	cache, err := c.newNamespacedCache(namespace)
+	if err != nil {
+		return fmt.Errorf("error creating cache for namespace %q: %w", namespace, err)
+	}
+	for _, informer := range c.informers {
+		nsInformer, err := getInformer(ctx, cache, informer.obj, informer.getOpts)
+		if err != nil {
+			return fmt.Errorf("error creating informer for namespace %q: %w", namespace, err)
+		}
+		if err := informer.addNamespace(namespace, nsInformer); err != nil {
+			return fmt.Errorf("error creating informer for namespace %q: %w", namespace, err)
 		}
 	}
+
+	c.namespaceToCache[namespace] = cache
+	if c.startCtx != nil {
+		c.startNamespacedCache(namespace, cache)
+	}
+	return nil
+}
+
+// RemoveNamespace implements DynamicNamespaces.
+func (c *multiNamespaceCache) RemoveNamespace(namespace string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.namespaceToCache[namespace]; !ok {
+		return nil
+	}
+
+	for _, informer := range c.informers {
+		informer.removeNamespace(namespace)
+	}
+	if cancel, ok := c.namespaceToCancel[namespace]; ok {
+		cancel()
+		delete(c.namespaceToCancel, namespace)
+	}
+	delete(c.namespaceToCache, namespace)
 	return nil
 }
 
+// Namespaces implements DynamicNamespaces.
+func (c *multiNamespaceCache) Namespaces() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	namespaces := make([]string, 0, len(c.namespaceToCache))
+	for ns := range c.namespaceToCache {
+		namespaces = append(namespaces, ns)
+	}
+	sort.Strings(namespaces)
+	return namespaces
+}
+
 func (c *multiNamespaceCache) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
 	isNamespaced, err := objectutil.IsAPINamespaced(obj, c.Scheme, c.RESTMapper)
 	if err != nil {
@@ -211,7 +498,7 @@ func (c *multiNamespaceCache) Get(ctx context.Context, key client.ObjectKey, obj
 		return c.clusterCache.Get(ctx, key, obj)
 	}
 
-	cache, ok := c.namespaceToCache[key.Namespace]
+	cache, ok := c.namespacedCache(key.Namespace)
 	if !ok {
 		return fmt.Errorf("unable to get: %v because of unknown namespace for the cache", key)
 	}
@@ -234,7 +521,7 @@ func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList,
 	}
 
 	if listOpts.Namespace != corev1.NamespaceAll {
-		cache, ok := c.namespaceToCache[listOpts.Namespace]
+		cache, ok := c.namespacedCache(listOpts.Namespace)
 		if !ok {
 			return fmt.Errorf("unable to get: %v because of unknown namespace for the cache", listOpts.Namespace)
 		}
@@ -254,7 +541,7 @@ func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList,
 	limitSet := listOpts.Limit > 0
 
 	var resourceVersion string
-	for _, cache := range c.namespaceToCache {
+	for _, cache := range c.namespacedCaches() {
 		listObj := list.DeepCopyObject().(client.ObjectList)
 		err = cache.List(ctx, listObj, &listOpts)
 		if err != nil {
@@ -290,13 +577,32 @@ func (c *multiNamespaceCache) List(ctx context.Context, list client.ObjectList,
 
 // multiNamespaceInformer knows how to handle interacting with the underlying informer across multiple namespaces.
 type multiNamespaceInformer struct {
+	mu                  sync.RWMutex
 	namespaceToInformer map[string]Informer
+
+	// obj is the object the informer has been requested for, it is nil for
+	// cluster scoped objects.
+	obj client.Object
+	// getOpts are the options the informer has been requested with.
+	getOpts []InformerGetOption
+	// handlers and indexers record what has been added to the informer, so
+	// that it can be replayed on informers of namespaces added later on.
+	handlers []handlerWithResyncPeriod
+	indexers []toolscache.Indexers
+}
+
+type handlerWithResyncPeriod struct {
+	handler      toolscache.ResourceEventHandler
+	resyncPeriod *time.Duration
 }
 
 var _ Informer = &multiNamespaceInformer{}
 
 // AddEventHandler adds the handler to each namespaced informer.
 func (i *multiNamespaceInformer) AddEventHandler(handler toolscache.ResourceEventHandler) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.handlers = append(i.handlers, handlerWithResyncPeriod{handler: handler})
 	for _, informer := range i.namespaceToInformer {
 		informer.AddEventHandler(handler)
 	}
@@ -304,6 +610,9 @@ func (i *multiNamespaceInformer) AddEventHandler(handler toolscache.ResourceEven
 
 // AddEventHandlerWithResyncPeriod adds the handler with a resync period to each namespaced informer.
 func (i *multiNamespaceInformer) AddEventHandlerWithResyncPeriod(handler toolscache.ResourceEventHandler, resyncPeriod time.Duration) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.handlers = append(i.handlers, handlerWithResyncPeriod{handler: handler, resyncPeriod: &resyncPeriod})
 	for _, informer := range i.namespaceToInformer {
 		informer.AddEventHandlerWithResyncPeriod(handler, resyncPeriod)
 	}
@@ -311,17 +620,22 @@ func (i *multiNamespaceInformer) AddEventHandlerWithResyncPeriod(handler toolsca
 
 // AddIndexers adds the indexer for each namespaced informer.
 func (i *multiNamespaceInformer) AddIndexers(indexers toolscache.Indexers) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
 	for _, informer := range i.namespaceToInformer {
 		err := informer.AddIndexers(indexers)
 		if err != nil {
 			return err
 		}
 	}
+	i.indexers = append(i.indexers, indexers)
 	return nil
 }
 
 // HasSynced checks if each namespaced informer has synced.
 func (i *multiNamespaceInformer) HasSynced() bool {
+	i.mu.RLock()
+	defer i.mu.RUnlock()
 	for _, informer := range i.namespaceToInformer {
 		if ok := informer.HasSynced(); !ok {
 			return ok
@@ -329,3 +643,31 @@ func (i *multiNamespaceInformer) HasSynced() bool {
 	}
 	return true
 }
+
+// addNamespace adds the informer of a new namespace, after adding the
+// indexers and event handlers added so far to it.
+func (i *multiNamespaceInformer) addNamespace(namespace string, informer Informer) error {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	for _, indexers := range i.indexers {
+		if err := informer.AddIndexers(indexers); err != nil {
+			return err
+		}
+	}
+	for _, h := range i.handlers {
+		if h.resyncPeriod != nil {
+			informer.AddEventHandlerWithResyncPeriod(h.handler, *h.resyncPeriod)
+		} else {
+			informer.AddEventHandler(h.handler)
+		}
+	}
+	i.namespaceToInformer[namespace] = informer
+	return nil
+}
+
+// removeNamespace drops the informer of a removed namespace.
+func (i *multiNamespaceInformer) removeNamespace(namespace string) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	delete(i.namespaceToInformer, namespace)
+}
@@ -0,0 +1,180 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"sync"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	toolscache "k8s.io/client-go/tools/cache"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+)
+
+// fakeNamespacedCache hands out one fake informer per namespace and records
+// whether it is running.
+type fakeNamespacedCache struct {
+	Cache
+	informer *controllertest.FakeInformer
+
+	mu        sync.Mutex
+	running   bool
+	gets      int
+	selectors []*ObjectSelector
+}
+
+func (c *fakeNamespacedCache) GetInformer(ctx context.Context, obj client.Object) (Informer, error) {
+	return c.GetInformerWithOptions(ctx, obj)
+}
+
+func (c *fakeNamespacedCache) GetInformerWithOptions(_ context.Context, _ client.Object, opts ...InformerGetOption) (Informer, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.gets++
+	c.selectors = append(c.selectors, (*ObjectSelector)(makeGetOptions(opts).Selector))
+	return c.informer, nil
+}
+
+func (c *fakeNamespacedCache) GetInformerForKindWithOptions(context.Context, schema.GroupVersionKind, ...InformerGetOption) (Informer, error) {
+	return nil, errors.New("not implemented")
+}
+
+func (c *fakeNamespacedCache) Start(ctx context.Context) error {
+	c.setRunning(true)
+	<-ctx.Done()
+	c.setRunning(false)
+	return nil
+}
+
+func (c *fakeNamespacedCache) setRunning(running bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.running = running
+}
+
+func (c *fakeNamespacedCache) isRunning() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.running
+}
+
+var _ = Describe("multiNamespaceCache dynamic namespaces", func() {
+	var (
+		caches map[string]*fakeNamespacedCache
+		mnc    *multiNamespaceCache
+	)
+
+	BeforeEach(func() {
+		caches = map[string]*fakeNamespacedCache{}
+		newNamespacedCache := func(ns string) (Cache, error) {
+			c := &fakeNamespacedCache{informer: &controllertest.FakeInformer{}}
+			caches[ns] = c
+			return c, nil
+		}
+		initial, err := newNamespacedCache("initial")
+		Expect(err).NotTo(HaveOccurred())
+
+		mapper := apimeta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+		mapper.Add(corev1.SchemeGroupVersion.WithKind("Pod"), apimeta.RESTScopeNamespace)
+		mnc = &multiNamespaceCache{
+			namespaceToCache:   map[string]Cache{"initial": initial},
+			Scheme:             scheme.Scheme,
+			RESTMapper:         mapper,
+			clusterCache:       &fakeNamespacedCache{informer: &controllertest.FakeInformer{}},
+			newNamespacedCache: newNamespacedCache,
+			namespaceToCancel:  map[string]context.CancelFunc{},
+			informers:          map[informerKey]*multiNamespaceInformer{},
+		}
+	})
+
+	It("should extend existing informers and their handlers to added namespaces", func() {
+		informer, err := mnc.GetInformer(context.Background(), &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+
+		var added []string
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: func(obj interface{}) {
+				added = append(added, obj.(metav1.Object).GetNamespace())
+			},
+		})
+
+		Expect(mnc.AddNamespace(context.Background(), "added")).To(Succeed())
+		Expect(mnc.Namespaces()).To(Equal([]string{"added", "initial"}))
+
+		caches["initial"].informer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "initial"}})
+		caches["added"].informer.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "added"}})
+		Expect(added).To(Equal([]string{"initial", "added"}))
+	})
+
+	It("should get informers handed out before from the namespaced caches again", func() {
+		informer, err := mnc.GetInformer(context.Background(), &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caches["initial"].gets).To(Equal(1))
+
+		// the namespaced caches wait for the informer to sync once they are started
+		again, err := mnc.GetInformer(context.Background(), &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(BeIdenticalTo(informer))
+		Expect(caches["initial"].gets).To(Equal(2))
+	})
+
+	It("should pass the options of the informers to the namespaced caches", func() {
+		fooSelector := ObjectSelector{Label: labels.SelectorFromSet(labels.Set{"app": "foo"})}
+		barSelector := ObjectSelector{Label: labels.SelectorFromSet(labels.Set{"app": "bar"})}
+		_, err := mnc.GetInformerWithOptions(context.Background(), &corev1.Pod{}, WithSelector(fooSelector))
+		Expect(err).NotTo(HaveOccurred())
+		_, err = mnc.GetInformerWithOptions(context.Background(), &corev1.Pod{}, WithSelector(barSelector))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(caches["initial"].selectors).To(Equal([]*ObjectSelector{&fooSelector, &barSelector}))
+
+		By("getting the informers of namespaces added later with the options they were created with")
+		Expect(mnc.AddNamespace(context.Background(), "added")).To(Succeed())
+		Expect(caches["added"].selectors).To(Equal([]*ObjectSelector{&fooSelector}))
+	})
+
+	It("should start and stop the caches of namespaces changed while running", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(mnc.Start(ctx)).To(Succeed())
+		}()
+		Eventually(caches["initial"].isRunning).Should(BeTrue())
+
+		Expect(mnc.AddNamespace(ctx, "added")).To(Succeed())
+		Eventually(caches["added"].isRunning).Should(BeTrue())
+
+		Expect(mnc.RemoveNamespace("added")).To(Succeed())
+		Eventually(caches["added"].isRunning).Should(BeFalse())
+		Expect(caches["initial"].isRunning()).To(BeTrue())
+		Expect(mnc.Namespaces()).To(Equal([]string{"initial"}))
+
+		err := mnc.Get(ctx, client.ObjectKey{Namespace: "added", Name: "foo"}, &corev1.Pod{})
+		Expect(err).To(HaveOccurred())
+	})
+})
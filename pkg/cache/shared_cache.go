@@ -0,0 +1,402 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// SharedCache is a cache shared by several managers or clusters of the same
+// process, so that they don't run duplicate watches against the same cluster.
+// Each of them gets its own handle through NewCache:
+//
+//  shared, err := cache.NewSharedCache(cfg, cache.Options{})
+//  mgr1, err := manager.New(cfg, manager.Options{NewCache: shared.NewCache})
+//  mgr2, err := manager.New(cfg, manager.Options{NewCache: shared.NewCache})
+//
+// The cache runs as long as any of the handles is running. Informers are
+// reference-counted: removing an informer through a handle, or stopping a
+// handle, only removes the informer once no other handle uses it.
+// A shared cache can't be restarted once all of its handles have stopped,
+// a new one must be created instead.
+type SharedCache struct {
+	cache     Cache
+	config    *rest.Config
+	scheme    *runtime.Scheme
+	namespace string
+	resync    *time.Duration
+
+	mu       sync.Mutex
+	running  int
+	stopped  bool
+	cancel   context.CancelFunc
+	startErr chan error
+	// refs counts the handles using each informer.
+	refs map[sharedInformerKey]int
+}
+
+// sharedInformerKey identifies the informers of a shared cache, the
+// representation is part of it because structured, unstructured and
+// metadata-only objects of the same GVK are backed by different informers.
+type sharedInformerKey struct {
+	gvk            schema.GroupVersionKind
+	representation string
+}
+
+// NewSharedCache creates a cache that can be shared by several managers,
+// see SharedCache.
+func NewSharedCache(config *rest.Config, opts Options) (*SharedCache, error) {
+	opts, err := defaultOpts(config, opts)
+	if err != nil {
+		return nil, err
+	}
+	c, err := New(config, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &SharedCache{
+		cache:     c,
+		config:    config,
+		scheme:    opts.Scheme,
+		namespace: opts.Namespace,
+		resync:    opts.Resync,
+		refs:      map[sharedInformerKey]int{},
+	}, nil
+}
+
+// NewCache returns a new handle of the shared cache, it implements NewCacheFunc.
+// The handles use the config and options the shared cache has been created with:
+// NewCache returns an error if it is given another cluster, scheme, namespace or
+// resync period, or any other option, and ignores the mapper, which maps the
+// kinds of the same cluster. It also returns an error once the shared cache has
+// been stopped.
+func (s *SharedCache) NewCache(config *rest.Config, opts Options) (Cache, error) {
+	if err := s.checkOptions(config, opts); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	stopped := s.stopped
+	s.mu.Unlock()
+	if stopped {
+		return nil, errSharedCacheStopped
+	}
+	return &sharedCacheHandle{
+		Cache:  s.cache,
+		shared: s,
+		refs:   map[sharedInformerKey]client.Object{},
+	}, nil
+}
+
+// checkOptions returns an error if the arguments of NewCache differ from the
+// ones the shared cache has been created with.
+func (s *SharedCache) checkOptions(config *rest.Config, opts Options) error {
+	if config != nil && s.config != nil && config.Host != s.config.Host {
+		return fmt.Errorf("the shared cache is for the cluster at %q, not %q", s.config.Host, config.Host)
+	}
+	if opts.Scheme != nil && opts.Scheme != s.scheme {
+		return errors.New("the shared cache has been created with another scheme")
+	}
+	if opts.Namespace != s.namespace {
+		return fmt.Errorf("the shared cache is restricted to the namespace %q, not %q", s.namespace, opts.Namespace)
+	}
+	if opts.Resync != nil && s.resync != nil && *opts.Resync != *s.resync {
+		return fmt.Errorf("the shared cache resyncs every %s, not %s", *s.resync, *opts.Resync)
+	}
+	opts.Scheme, opts.Mapper, opts.Namespace, opts.Resync = nil, nil, "", nil
+	if !reflect.DeepEqual(opts, Options{}) {
+		return errors.New("the options of a shared cache can only be set by NewSharedCache")
+	}
+	return nil
+}
+
+var errSharedCacheStopped = errors.New("the shared cache has been stopped already")
+
+// start starts the underlying cache when the first handle starts.
+func (s *SharedCache) start() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return errSharedCacheStopped
+	}
+	s.running++
+	if s.running == 1 && s.cancel == nil {
+		var ctx context.Context
+		ctx, s.cancel = context.WithCancel(context.Background())
+		s.startErr = make(chan error, 1)
+		go func() {
+			s.startErr <- s.cache.Start(ctx)
+		}()
+	}
+	return nil
+}
+
+// stop stops the underlying cache when the last handle stops.
+func (s *SharedCache) stop() error {
+	s.mu.Lock()
+	s.running--
+	if s.running > 0 {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	s.cancel()
+	s.mu.Unlock()
+	return <-s.startErr
+}
+
+// acquire records that a handle uses the informer of key.
+func (s *SharedCache) acquire(key sharedInformerKey) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs[key]++
+}
+
+// release records that a handle stopped using the informer of key, and
+// returns whether it isn't used by any handle anymore.
+func (s *SharedCache) release(key sharedInformerKey) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.refs[key]--
+	if s.refs[key] > 0 {
+		return false
+	}
+	delete(s.refs, key)
+	return true
+}
+
+// sharedCacheHandle is the Cache of a single user of a SharedCache.
+type sharedCacheHandle struct {
+	Cache
+	shared *SharedCache
+
+	mu sync.Mutex
+	// refs are the informers used by this handle, with the object to remove them with.
+	refs map[sharedInformerKey]client.Object
+}
+
+var _ Cache = &sharedCacheHandle{}
+var _ InformerRemover = &sharedCacheHandle{}
+var _ KindSyncChecker = &sharedCacheHandle{}
+var _ InformerGetterWithOptions = &sharedCacheHandle{}
+
+// Start runs the shared cache until ctx is done and no other handle is running anymore.
+func (h *sharedCacheHandle) Start(ctx context.Context) error {
+	if err := h.shared.start(); err != nil {
+		return err
+	}
+	<-ctx.Done()
+
+	h.mu.Lock()
+	refs := h.refs
+	h.refs = map[sharedInformerKey]client.Object{}
+	h.mu.Unlock()
+	for key, obj := range refs {
+		if err := h.releaseInformer(context.Background(), key, obj); err != nil {
+			log.Error(err, "failed to remove informer of shared cache", "type", obj)
+		}
+	}
+	return h.shared.stop()
+}
+
+// NeedLeaderElection implements the LeaderElectionRunnable interface
+// to indicate that this can be started without requiring the leader lock.
+func (h *sharedCacheHandle) NeedLeaderElection() bool {
+	return false
+}
+
+// Get implements client.Reader.
+func (h *sharedCacheHandle) Get(ctx context.Context, key client.ObjectKey, obj client.Object) error {
+	if err := h.use(obj); err != nil {
+		return err
+	}
+	return h.Cache.Get(ctx, key, obj)
+}
+
+// List implements client.Reader.
+func (h *sharedCacheHandle) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := h.use(list); err != nil {
+		return err
+	}
+	return h.Cache.List(ctx, list, opts...)
+}
+
+// GetInformer implements Informers.
+func (h *sharedCacheHandle) GetInformer(ctx context.Context, obj client.Object) (Informer, error) {
+	return h.GetInformerWithOptions(ctx, obj)
+}
+
+// GetInformerWithOptions implements InformerGetterWithOptions.
+func (h *sharedCacheHandle) GetInformerWithOptions(ctx context.Context, obj client.Object, opts ...InformerGetOption) (Informer, error) {
+	if err := h.use(obj); err != nil {
+		return nil, err
+	}
+	return getInformer(ctx, h.Cache, obj, opts)
+}
+
+// GetInformerForKind implements Informers.
+func (h *sharedCacheHandle) GetInformerForKind(ctx context.Context, gvk schema.GroupVersionKind) (Informer, error) {
+	return h.GetInformerForKindWithOptions(ctx, gvk)
+}
+
+// GetInformerForKindWithOptions implements InformerGetterWithOptions.
+func (h *sharedCacheHandle) GetInformerForKindWithOptions(ctx context.Context, gvk schema.GroupVersionKind, opts ...InformerGetOption) (Informer, error) {
+	obj, err := h.shared.scheme.New(gvk)
+	if err != nil {
+		// the kinds unknown to the scheme are only reachable as unstructured
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		return h.GetInformerWithOptions(ctx, u, opts...)
+	}
+	if err := h.use(obj); err != nil {
+		return nil, err
+	}
+	return getInformerForKind(ctx, h.Cache, gvk, opts)
+}
+
+// IndexField implements client.FieldIndexer.
+func (h *sharedCacheHandle) IndexField(ctx context.Context, obj client.Object, field string, extractValue client.IndexerFunc) error {
+	if err := h.use(obj); err != nil {
+		return err
+	}
+	return h.Cache.IndexField(ctx, obj, field, extractValue)
+}
+
+// RemoveInformer stops using the informer for obj, and removes it once no
+// other handle uses it.
+func (h *sharedCacheHandle) RemoveInformer(ctx context.Context, obj client.Object) error {
+	key, err := h.keyFor(obj)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	if _, used := h.refs[key]; !used {
+		h.mu.Unlock()
+		return nil
+	}
+	delete(h.refs, key)
+	h.mu.Unlock()
+	return h.releaseInformer(ctx, key, obj)
+}
+
+// releaseInformer stops using the informer of key, and removes it using obj
+// once no other handle uses it.
+func (h *sharedCacheHandle) releaseInformer(ctx context.Context, key sharedInformerKey, obj client.Object) error {
+	if !h.shared.release(key) {
+		return nil
+	}
+	return removeInformer(ctx, h.Cache, obj)
+}
+
+// HasSyncedFor implements KindSyncChecker.
+func (h *sharedCacheHandle) HasSyncedFor(gvk schema.GroupVersionKind) bool {
+	return hasSyncedFor(h.Cache, gvk)
+}
+
+func (h *sharedCacheHandle) dump(includeObjects bool) []InformerDump {
+	if d, ok := h.Cache.(dumper); ok {
+		return d.dump(includeObjects)
+	}
+	return nil
+}
+
+func (h *sharedCacheHandle) checkSynced(objs ...client.Object) error {
+	if checker, ok := h.Cache.(readinessChecker); ok {
+		return checker.checkSynced(objs...)
+	}
+	return nil
+}
+
+// use records that this handle uses the informer for obj, which may be a list.
+func (h *sharedCacheHandle) use(obj runtime.Object) error {
+	key, err := h.keyFor(obj)
+	if err != nil {
+		return err
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, used := h.refs[key]; used {
+		return nil
+	}
+	itemObj, err := h.itemObject(obj, key.gvk)
+	if err != nil {
+		return err
+	}
+	h.refs[key] = itemObj
+	h.shared.acquire(key)
+	return nil
+}
+
+// keyFor returns the key of the informer for obj, which may be a list.
+func (h *sharedCacheHandle) keyFor(obj runtime.Object) (sharedInformerKey, error) {
+	gvk, err := apiutil.GVKForObject(obj, h.shared.scheme)
+	if err != nil {
+		return sharedInformerKey{}, err
+	}
+	if apimeta.IsListType(obj) {
+		gvk.Kind = strings.TrimSuffix(gvk.Kind, "List")
+	}
+	representation := "structured"
+	switch obj.(type) {
+	case *unstructured.Unstructured, *unstructured.UnstructuredList:
+		representation = "unstructured"
+	case *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
+		representation = "metadata"
+	}
+	return sharedInformerKey{gvk: gvk, representation: representation}, nil
+}
+
+// itemObject returns an object to remove the informer for obj with.
+func (h *sharedCacheHandle) itemObject(obj runtime.Object, gvk schema.GroupVersionKind) (client.Object, error) {
+	switch obj.(type) {
+	case *unstructured.Unstructured, *unstructured.UnstructuredList:
+		u := &unstructured.Unstructured{}
+		u.SetGroupVersionKind(gvk)
+		return u, nil
+	case *metav1.PartialObjectMetadata, *metav1.PartialObjectMetadataList:
+		m := &metav1.PartialObjectMetadata{}
+		m.SetGroupVersionKind(gvk)
+		return m, nil
+	}
+	if cObj, ok := obj.(client.Object); ok && !apimeta.IsListType(obj) {
+		return cObj, nil
+	}
+	item, err := h.shared.scheme.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	cObj, ok := item.(client.Object)
+	if !ok {
+		return nil, errors.New("the items of the list are not client.Objects")
+	}
+	return cObj, nil
+}
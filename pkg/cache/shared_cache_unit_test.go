@@ -0,0 +1,136 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package cache
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
+)
+
+// removalRecordingCache records the informers removed from it.
+type removalRecordingCache struct {
+	fakeNamespacedCache
+	removed []client.Object
+}
+
+func (c *removalRecordingCache) RemoveInformer(_ context.Context, obj client.Object) error {
+	c.removed = append(c.removed, obj)
+	return nil
+}
+
+var _ = Describe("SharedCache", func() {
+	var (
+		underlying *removalRecordingCache
+		shared     *SharedCache
+	)
+
+	BeforeEach(func() {
+		underlying = &removalRecordingCache{fakeNamespacedCache: fakeNamespacedCache{informer: &controllertest.FakeInformer{}}}
+		shared = &SharedCache{cache: underlying, scheme: scheme.Scheme, refs: map[sharedInformerKey]int{}}
+	})
+
+	It("should run the cache as long as any handle is running", func() {
+		first, err := shared.NewCache(nil, Options{})
+		Expect(err).NotTo(HaveOccurred())
+		second, err := shared.NewCache(nil, Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		firstCtx, cancelFirst := context.WithCancel(context.Background())
+		firstDone := make(chan error)
+		go func() { firstDone <- first.Start(firstCtx) }()
+		secondCtx, cancelSecond := context.WithCancel(context.Background())
+		secondDone := make(chan error)
+		go func() { secondDone <- second.Start(secondCtx) }()
+		Eventually(underlying.isRunning).Should(BeTrue())
+
+		cancelFirst()
+		Eventually(firstDone).Should(Receive(BeNil()))
+		Consistently(underlying.isRunning).Should(BeTrue())
+
+		cancelSecond()
+		Eventually(secondDone).Should(Receive(BeNil()))
+		Eventually(underlying.isRunning).Should(BeFalse())
+
+		By("refusing to restart")
+		_, err = shared.NewCache(nil, Options{})
+		Expect(err).To(MatchError(errSharedCacheStopped))
+		Expect(first.Start(context.Background())).To(MatchError(errSharedCacheStopped))
+	})
+
+	It("should reject the options it can't honor", func() {
+		resync := time.Hour
+		shared.config = &rest.Config{Host: "https://cluster-a"}
+		shared.resync = &resync
+
+		_, err := shared.NewCache(&rest.Config{Host: "https://cluster-a"}, Options{Scheme: scheme.Scheme, Mapper: apimeta.NewDefaultRESTMapper(nil), Resync: &resync})
+		Expect(err).NotTo(HaveOccurred())
+
+		otherResync := time.Minute
+		for _, opts := range []Options{
+			{Scheme: runtime.NewScheme()},
+			{Namespace: "default"},
+			{Resync: &otherResync},
+			{SelectorsByObject: SelectorsByObject{&corev1.Pod{}: {}}},
+		} {
+			_, err := shared.NewCache(nil, opts)
+			Expect(err).To(HaveOccurred())
+		}
+		_, err = shared.NewCache(&rest.Config{Host: "https://cluster-b"}, Options{})
+		Expect(err).To(MatchError(ContainSubstring("cluster-b")))
+	})
+
+	It("should get the informers of kinds unknown to the scheme as unstructured", func() {
+		handle, err := shared.NewCache(nil, Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		gvk := schema.GroupVersionKind{Group: "example.com", Version: "v1", Kind: "Database"}
+		_, err = handle.GetInformerForKind(context.Background(), gvk)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(shared.refs).To(HaveKey(sharedInformerKey{gvk: gvk, representation: "unstructured"}))
+	})
+
+	It("should only remove informers no handle uses anymore", func() {
+		first, err := shared.NewCache(nil, Options{})
+		Expect(err).NotTo(HaveOccurred())
+		second, err := shared.NewCache(nil, Options{})
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = first.GetInformer(context.Background(), &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+		_, err = second.GetInformer(context.Background(), &corev1.Pod{})
+		Expect(err).NotTo(HaveOccurred())
+
+		Expect(first.(InformerRemover).RemoveInformer(context.Background(), &corev1.Pod{})).To(Succeed())
+		Expect(underlying.removed).To(BeEmpty())
+
+		Expect(second.(InformerRemover).RemoveInformer(context.Background(), &corev1.Pod{})).To(Succeed())
+		Expect(underlying.removed).To(HaveLen(1))
+	})
+})
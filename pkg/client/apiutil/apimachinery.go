@@ -32,6 +32,7 @@ import (
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/restmapper"
+	"k8s.io/client-go/util/flowcontrol"
 )
 
 var (
@@ -114,11 +115,39 @@ func GVKForObject(obj runtime.Object, scheme *runtime.Scheme) (schema.GroupVersi
 	return gvks[0], nil
 }
 
+// RESTClientOption tunes the rest.Config a REST client is built from.
+type RESTClientOption func(*rest.Config)
+
+// WithContentType sets the content type used to talk to the API server,
+// e.g. runtime.ContentTypeJSON to opt out of protobuf for a built-in type.
+func WithContentType(contentType string) RESTClientOption {
+	return func(cfg *rest.Config) {
+		cfg.ContentType = contentType
+	}
+}
+
+// WithNegotiatedSerializer sets the serializer used to encode requests and
+// decode responses, instead of the default one built from the codecs.
+func WithNegotiatedSerializer(s runtime.NegotiatedSerializer) RESTClientOption {
+	return func(cfg *rest.Config) {
+		cfg.NegotiatedSerializer = s
+	}
+}
+
+// WithRateLimiter makes the REST client use its own rate limiter instead of
+// the one (or the QPS and Burst) configured in the base config.
+func WithRateLimiter(rateLimiter flowcontrol.RateLimiter) RESTClientOption {
+	return func(cfg *rest.Config) {
+		cfg.RateLimiter = rateLimiter
+	}
+}
+
 // RESTClientForGVK constructs a new rest.Interface capable of accessing the resource associated
 // with the given GroupVersionKind. The REST client will be configured to use the negotiated serializer from
 // baseConfig, if set, otherwise a default serializer will be set.
-func RESTClientForGVK(gvk schema.GroupVersionKind, isUnstructured bool, baseConfig *rest.Config, codecs serializer.CodecFactory) (rest.Interface, error) {
-	return rest.RESTClientFor(createRestConfig(gvk, isUnstructured, baseConfig, codecs))
+// The given options are applied on top of baseConfig, before any defaulting takes place.
+func RESTClientForGVK(gvk schema.GroupVersionKind, isUnstructured bool, baseConfig *rest.Config, codecs serializer.CodecFactory, opts ...RESTClientOption) (rest.Interface, error) {
+	return rest.RESTClientFor(createRestConfig(gvk, isUnstructured, baseConfig, codecs, opts...))
 }
 
 // serializerWithDecodedGVK is a CodecFactory that overrides the DecoderToVersion of a WithoutConversionCodecFactory
@@ -135,10 +164,13 @@ func (f serializerWithDecodedGVK) DecoderToVersion(serializer runtime.Decoder, _
 }
 
 // createRestConfig copies the base config and updates needed fields for a new rest config.
-func createRestConfig(gvk schema.GroupVersionKind, isUnstructured bool, baseConfig *rest.Config, codecs serializer.CodecFactory) *rest.Config {
+func createRestConfig(gvk schema.GroupVersionKind, isUnstructured bool, baseConfig *rest.Config, codecs serializer.CodecFactory, opts ...RESTClientOption) *rest.Config {
 	gv := gvk.GroupVersion()
 
 	cfg := rest.CopyConfig(baseConfig)
+	for _, opt := range opts {
+		opt(cfg)
+	}
 	cfg.GroupVersion = &gv
 	if gvk.Group == "" {
 		cfg.APIPath = "/api"
@@ -0,0 +1,88 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiutil_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/util/flowcontrol"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+var _ = Describe("RESTClientForGVK", func() {
+	var (
+		server      *httptest.Server
+		contentType chan string
+	)
+
+	BeforeEach(func() {
+		contentType = make(chan string, 1)
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			contentType <- r.Header.Get("Content-Type")
+			w.Header().Set("Content-Type", runtime.ContentTypeJSON)
+			w.WriteHeader(http.StatusCreated)
+			_, _ = w.Write([]byte(`{"apiVersion":"v1","kind":"ConfigMap"}`))
+		}))
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	post := func(client rest.Interface) {
+		cm := &corev1.ConfigMap{}
+		Expect(client.Post().Resource("configmaps").Body(cm).Do(context.Background()).Error()).To(Succeed())
+	}
+
+	It("should default to protobuf for built-in types", func() {
+		client, err := apiutil.RESTClientForGVK(corev1.SchemeGroupVersion.WithKind("ConfigMap"), false,
+			&rest.Config{Host: server.URL}, serializer.NewCodecFactory(scheme.Scheme))
+		Expect(err).NotTo(HaveOccurred())
+
+		post(client)
+		Expect(<-contentType).To(Equal(runtime.ContentTypeProtobuf))
+	})
+
+	It("should use the content type from the options", func() {
+		client, err := apiutil.RESTClientForGVK(corev1.SchemeGroupVersion.WithKind("ConfigMap"), false,
+			&rest.Config{Host: server.URL}, serializer.NewCodecFactory(scheme.Scheme),
+			apiutil.WithContentType(runtime.ContentTypeJSON))
+		Expect(err).NotTo(HaveOccurred())
+
+		post(client)
+		Expect(<-contentType).To(Equal(runtime.ContentTypeJSON))
+	})
+
+	It("should use the rate limiter from the options", func() {
+		limiter := flowcontrol.NewFakeAlwaysRateLimiter()
+		client, err := apiutil.RESTClientForGVK(corev1.SchemeGroupVersion.WithKind("ConfigMap"), false,
+			&rest.Config{Host: server.URL}, serializer.NewCodecFactory(scheme.Scheme),
+			apiutil.WithRateLimiter(limiter))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(client.GetRateLimiter()).To(BeIdenticalTo(limiter))
+	})
+})
@@ -0,0 +1,119 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiutil
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/restmapper"
+)
+
+// illegalCacheDirChars matches the characters we don't want to see in a
+// cache directory name derived from a server address.
+var illegalCacheDirChars = regexp.MustCompile(`[^(\w/\.)]`)
+
+// discoveryDiskCache persists the discovered API group resources of a single
+// server on disk, so that they can be reused across process restarts.
+//
+// It is not safe for concurrent use; the dynamicRESTMapper only calls it while
+// holding its lock (or during construction).
+type discoveryDiskCache struct {
+	path string
+	ttl  time.Duration
+
+	// consulted records whether the on-disk data has been used already.
+	// Every load after the first one is a reload caused by a lookup miss,
+	// meaning the cached data didn't match the server anymore.
+	consulted bool
+}
+
+// newDiscoveryDiskCache returns a cache for the server at host, stored below cacheDir.
+func newDiscoveryDiskCache(cacheDir, host string, ttl time.Duration) *discoveryDiskCache {
+	schemelessHost := strings.TrimPrefix(strings.TrimPrefix(host, "https://"), "http://")
+	safeHost := illegalCacheDirChars.ReplaceAllString(schemelessHost, "_")
+	return &discoveryDiskCache{
+		path: filepath.Join(cacheDir, safeHost, "apigroupresources.json"),
+		ttl:  ttl,
+	}
+}
+
+// getAPIGroupResources returns the cached group resources if they are fresh and
+// haven't been invalidated, and otherwise calls fetch and caches its result.
+func (c *discoveryDiskCache) getAPIGroupResources(fetch func() ([]*restmapper.APIGroupResources, error)) ([]*restmapper.APIGroupResources, error) {
+	if !c.consulted {
+		c.consulted = true
+		if cached, ok := c.read(); ok {
+			return cached, nil
+		}
+	}
+
+	groupResources, err := fetch()
+	if err != nil {
+		return nil, err
+	}
+	// the cache is best-effort, failing to write it only costs
+	// a full discovery on the next start.
+	_ = c.write(groupResources)
+	return groupResources, nil
+}
+
+// read returns the cached group resources, if present and younger than the TTL.
+func (c *discoveryDiskCache) read() ([]*restmapper.APIGroupResources, bool) {
+	info, err := os.Stat(c.path)
+	if err != nil || time.Since(info.ModTime()) > c.ttl {
+		return nil, false
+	}
+	b, err := ioutil.ReadFile(c.path)
+	if err != nil {
+		return nil, false
+	}
+	var groupResources []*restmapper.APIGroupResources
+	if err := json.Unmarshal(b, &groupResources); err != nil {
+		return nil, false
+	}
+	return groupResources, true
+}
+
+// write atomically replaces the cached group resources.
+func (c *discoveryDiskCache) write(groupResources []*restmapper.APIGroupResources) error {
+	b, err := json.Marshal(groupResources)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(c.path), 0750); err != nil {
+		return err
+	}
+	f, err := ioutil.TempFile(filepath.Dir(c.path), filepath.Base(c.path)+".")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) //nolint:errcheck
+	if _, err := f.Write(b); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path)
+}
@@ -18,7 +18,9 @@ package apiutil
 
 import (
 	"errors"
+	"sort"
 	"sync"
+	"time"
 
 	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -36,6 +38,13 @@ type dynamicRESTMapper struct {
 	limiter      *rate.Limiter
 	newMapper    func() (meta.RESTMapper, error)
 
+	cacheDir string
+	cacheTTL time.Duration
+
+	// preferredVersions maps a group to the versions that should be
+	// favored, in order, when resolving a mapping without explicit versions.
+	preferredVersions map[string][]string
+
 	lazy bool
 	// Used for lazy init.
 	initOnce sync.Once
@@ -71,6 +80,38 @@ func WithCustomMapper(newMapper func() (meta.RESTMapper, error)) DynamicRESTMapp
 	}
 }
 
+// WithDiskCache makes the RESTMapper store the discovered REST mappings in
+// cacheDir (much like kubectl's ~/.kube/cache) and reuse them on start as long
+// as they are younger than ttl, so that restarts don't pay the full discovery
+// cost on large clusters.
+//
+// The cached mappings are discarded as soon as a lookup misses, and refreshed
+// from the server.  This has no effect if combined with WithCustomMapper.
+func WithDiskCache(cacheDir string, ttl time.Duration) DynamicRESTMapperOption {
+	return func(drm *dynamicRESTMapper) error {
+		drm.cacheDir = cacheDir
+		drm.cacheTTL = ttl
+		return nil
+	}
+}
+
+// WithPreferredVersions makes RESTMapping and RESTMappings favor the given
+// GroupVersions, in the given order, over the server's priority ordering when
+// no explicit version is requested.  This is useful to force e.g. the v1beta1
+// version of a group during a migration.  Kinds that don't exist in any of the
+// preferred versions are resolved as usual.
+func WithPreferredVersions(gvs ...schema.GroupVersion) DynamicRESTMapperOption {
+	return func(drm *dynamicRESTMapper) error {
+		if drm.preferredVersions == nil {
+			drm.preferredVersions = make(map[string][]string, len(gvs))
+		}
+		for _, gv := range gvs {
+			drm.preferredVersions[gv.Group] = append(drm.preferredVersions[gv.Group], gv.Version)
+		}
+		return nil
+	}
+}
+
 // NewDynamicRESTMapper returns a dynamic RESTMapper for cfg. The dynamic
 // RESTMapper dynamically discovers resource types at runtime. opts
 // configure the RESTMapper.
@@ -81,19 +122,31 @@ func NewDynamicRESTMapper(cfg *rest.Config, opts ...DynamicRESTMapperOption) (me
 	}
 	drm := &dynamicRESTMapper{
 		limiter: rate.NewLimiter(rate.Limit(defaultRefillRate), defaultLimitSize),
-		newMapper: func() (meta.RESTMapper, error) {
-			groupResources, err := restmapper.GetAPIGroupResources(client)
-			if err != nil {
-				return nil, err
-			}
-			return restmapper.NewDiscoveryRESTMapper(groupResources), nil
-		},
 	}
 	for _, opt := range opts {
 		if err = opt(drm); err != nil {
 			return nil, err
 		}
 	}
+	if drm.newMapper == nil {
+		getGroupResources := func() ([]*restmapper.APIGroupResources, error) {
+			return restmapper.GetAPIGroupResources(client)
+		}
+		if drm.cacheDir != "" {
+			diskCache := newDiscoveryDiskCache(drm.cacheDir, cfg.Host, drm.cacheTTL)
+			fetch := getGroupResources
+			getGroupResources = func() ([]*restmapper.APIGroupResources, error) {
+				return diskCache.getAPIGroupResources(fetch)
+			}
+		}
+		drm.newMapper = func() (meta.RESTMapper, error) {
+			groupResources, err := getGroupResources()
+			if err != nil {
+				return nil, err
+			}
+			return restmapper.NewDiscoveryRESTMapper(groupResources), nil
+		}
+	}
 	if !drm.lazy {
 		if err := drm.setStaticMapper(); err != nil {
 			return nil, err
@@ -190,6 +243,31 @@ func (drm *dynamicRESTMapper) checkAndReload(needsReloadErr error, checkNeedsRel
 	return checkNeedsReload()
 }
 
+// preferredVersionsFor returns the preferred versions to try for gk, if any.
+// Explicitly requested versions always win over preferences.
+func (drm *dynamicRESTMapper) preferredVersionsFor(gk schema.GroupKind, requested []string) []string {
+	if len(requested) > 0 {
+		return nil
+	}
+	return drm.preferredVersions[gk.Group]
+}
+
+// sortByPreference moves the mappings for the preferred versions to the front,
+// in order of preference, keeping the relative order of all other mappings.
+func sortByPreference(mappings []*meta.RESTMapping, preferred []string) {
+	rank := func(m *meta.RESTMapping) int {
+		for i, v := range preferred {
+			if m.GroupVersionKind.Version == v {
+				return i
+			}
+		}
+		return len(preferred)
+	}
+	sort.SliceStable(mappings, func(i, j int) bool {
+		return rank(mappings[i]) < rank(mappings[j])
+	})
+}
+
 // TODO: wrap reload errors on NoKindMatchError with go 1.13 errors.
 
 func (drm *dynamicRESTMapper) KindFor(resource schema.GroupVersionResource) (schema.GroupVersionKind, error) {
@@ -252,6 +330,12 @@ func (drm *dynamicRESTMapper) RESTMapping(gk schema.GroupKind, versions ...strin
 	var mapping *meta.RESTMapping
 	err := drm.checkAndReload(&meta.NoKindMatchError{}, func() error {
 		var err error
+		if preferred := drm.preferredVersionsFor(gk, versions); len(preferred) > 0 {
+			mapping, err = drm.staticMapper.RESTMapping(gk, preferred...)
+			if !meta.IsNoMatchError(err) {
+				return err
+			}
+		}
 		mapping, err = drm.staticMapper.RESTMapping(gk, versions...)
 		return err
 	})
@@ -266,7 +350,13 @@ func (drm *dynamicRESTMapper) RESTMappings(gk schema.GroupKind, versions ...stri
 	err := drm.checkAndReload(&meta.NoKindMatchError{}, func() error {
 		var err error
 		mappings, err = drm.staticMapper.RESTMappings(gk, versions...)
-		return err
+		if err != nil {
+			return err
+		}
+		if preferred := drm.preferredVersionsFor(gk, versions); len(preferred) > 0 {
+			sortByPreference(mappings, preferred)
+		}
+		return nil
 	})
 	return mappings, err
 }
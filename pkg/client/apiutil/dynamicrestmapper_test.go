@@ -17,6 +17,12 @@ limitations under the License.
 package apiutil_test
 
 import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sync/atomic"
 	"time"
 
 	. "github.com/onsi/ginkgo"
@@ -26,6 +32,7 @@ import (
 	"golang.org/x/time/rate"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
 
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
@@ -265,6 +272,139 @@ var _ = Describe("Dynamic REST Mapper", func() {
 	})
 })
 
+var _ = Describe("Dynamic REST Mapper with preferred versions", func() {
+	var mapper meta.RESTMapper
+
+	v1GV := schema.GroupVersion{Group: targetGVK.Group, Version: "v1"}
+	v1beta1GV := targetGVK.GroupVersion()
+	onlyV1GVK := v1GV.WithKind("OnlyV1CR")
+
+	BeforeEach(func() {
+		var err error
+		mapper, err = apiutil.NewDynamicRESTMapper(cfg,
+			apiutil.WithPreferredVersions(v1beta1GV),
+			apiutil.WithCustomMapper(func() (meta.RESTMapper, error) {
+				baseMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{v1GV, v1beta1GV})
+				baseMapper.Add(v1GV.WithKind(targetGVK.Kind), meta.RESTScopeNamespace)
+				baseMapper.Add(targetGVK, meta.RESTScopeNamespace)
+				baseMapper.Add(onlyV1GVK, meta.RESTScopeNamespace)
+				return baseMapper, nil
+			}))
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should favor the preferred version when none is requested", func() {
+		mapping, err := mapper.RESTMapping(targetGVK.GroupKind())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.GroupVersionKind).To(Equal(targetGVK))
+	})
+
+	It("should honor explicitly requested versions", func() {
+		mapping, err := mapper.RESTMapping(targetGVK.GroupKind(), "v1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.GroupVersionKind.Version).To(Equal("v1"))
+	})
+
+	It("should fall back to the default ordering for kinds missing from the preferred version", func() {
+		mapping, err := mapper.RESTMapping(onlyV1GVK.GroupKind())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.GroupVersionKind).To(Equal(onlyV1GVK))
+	})
+
+	It("should sort mappings of the preferred version first", func() {
+		mappings, err := mapper.RESTMappings(targetGVK.GroupKind())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mappings).To(HaveLen(2))
+		Expect(mappings[0].GroupVersionKind).To(Equal(targetGVK))
+		Expect(mappings[1].GroupVersionKind.Version).To(Equal("v1"))
+	})
+})
+
+var _ = Describe("Dynamic REST Mapper with a disk cache", func() {
+	var (
+		server      *httptest.Server
+		serverCfg   *rest.Config
+		cacheDir    string
+		requests    int64
+		serveSecond int32
+	)
+
+	BeforeEach(func() {
+		atomic.StoreInt64(&requests, 0)
+		atomic.StoreInt32(&serveSecond, 0)
+
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt64(&requests, 1)
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/api":
+				fmt.Fprint(w, `{"kind":"APIVersions","versions":[]}`)
+			case "/apis":
+				fmt.Fprintf(w, `{"kind":"APIGroupList","groups":[{"name":%[1]q,"versions":[{"groupVersion":%[2]q,"version":%[3]q}],"preferredVersion":{"groupVersion":%[2]q,"version":%[3]q}}]}`,
+					targetGVK.Group, targetGVK.GroupVersion().String(), targetGVK.Version)
+			case "/apis/" + targetGVK.GroupVersion().String():
+				resources := fmt.Sprintf(`{"name":%q,"namespaced":true,"kind":%q,"verbs":["get"]}`, targetGVR.Resource, targetGVK.Kind)
+				if atomic.LoadInt32(&serveSecond) == 1 {
+					resources += fmt.Sprintf(`,{"name":%q,"namespaced":true,"kind":%q,"verbs":["get"]}`, secondGVR.Resource, secondGVK.Kind)
+				}
+				fmt.Fprintf(w, `{"kind":"APIResourceList","groupVersion":%q,"resources":[%s]}`, targetGVK.GroupVersion().String(), resources)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+			}
+		}))
+		serverCfg = &rest.Config{Host: server.URL}
+
+		var err error
+		cacheDir, err = ioutil.TempDir("", "dynamicrestmapper-cache")
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	AfterEach(func() {
+		server.Close()
+		Expect(os.RemoveAll(cacheDir)).To(Succeed())
+	})
+
+	It("should reuse cached discovery information across mappers", func() {
+		By("discovering once to populate the cache")
+		_, err := apiutil.NewDynamicRESTMapper(serverCfg, apiutil.WithDiskCache(cacheDir, time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(atomic.LoadInt64(&requests)).NotTo(BeZero())
+
+		By("creating a second mapper that shouldn't hit the server")
+		atomic.StoreInt64(&requests, 0)
+		mapper, err := apiutil.NewDynamicRESTMapper(serverCfg, apiutil.WithDiskCache(cacheDir, time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapper.RESTMapping(targetGVK.GroupKind(), targetGVK.Version)).To(Equal(&targetMapping))
+		Expect(atomic.LoadInt64(&requests)).To(BeZero())
+	})
+
+	It("should refresh the cache when a lookup misses", func() {
+		mapper, err := apiutil.NewDynamicRESTMapper(serverCfg, apiutil.WithDiskCache(cacheDir, time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+
+		By("serving a new kind and looking it up")
+		atomic.StoreInt32(&serveSecond, 1)
+		Expect(mapper.RESTMapping(secondGVK.GroupKind(), secondGVK.Version)).To(Equal(&secondMapping))
+
+		By("creating a new mapper that should see the new kind from the cache")
+		atomic.StoreInt64(&requests, 0)
+		mapper, err = apiutil.NewDynamicRESTMapper(serverCfg, apiutil.WithDiskCache(cacheDir, time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapper.RESTMapping(secondGVK.GroupKind(), secondGVK.Version)).To(Equal(&secondMapping))
+		Expect(atomic.LoadInt64(&requests)).To(BeZero())
+	})
+
+	It("should ignore cached information older than the TTL", func() {
+		_, err := apiutil.NewDynamicRESTMapper(serverCfg, apiutil.WithDiskCache(cacheDir, time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+
+		atomic.StoreInt64(&requests, 0)
+		_, err = apiutil.NewDynamicRESTMapper(serverCfg, apiutil.WithDiskCache(cacheDir, 0))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(atomic.LoadInt64(&requests)).NotTo(BeZero())
+	})
+})
+
 func beNoMatchError() types.GomegaMatcher {
 	return noMatchErrorMatcher{}
 }
@@ -0,0 +1,269 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiutil
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	k8syaml "k8s.io/apimachinery/pkg/util/yaml"
+	"k8s.io/apimachinery/pkg/version"
+	"sigs.k8s.io/yaml"
+)
+
+// builtinClusterScopedKinds lists the built-in kinds that are not namespaced.
+// The scheme carries no scope information, so anything registered in it that
+// is not listed here is assumed to be namespaced.
+var builtinClusterScopedKinds = sets.NewString(
+	"APIService",
+	"CSIDriver",
+	"CSINode",
+	"CertificateSigningRequest",
+	"ClusterRole",
+	"ClusterRoleBinding",
+	"ComponentStatus",
+	"CustomResourceDefinition",
+	"FlowSchema",
+	"IngressClass",
+	"MutatingWebhookConfiguration",
+	"Namespace",
+	"Node",
+	"PersistentVolume",
+	"PodSecurityPolicy",
+	"PriorityClass",
+	"PriorityLevelConfiguration",
+	"RuntimeClass",
+	"SelfSubjectAccessReview",
+	"SelfSubjectRulesReview",
+	"StorageClass",
+	"SubjectAccessReview",
+	"TokenReview",
+	"ValidatingWebhookConfiguration",
+	"VolumeAttachment",
+)
+
+// metaOnlyKinds are the kinds that metav1.AddToGroupVersion registers in
+// every group version; they are not backed by a REST resource.
+var metaOnlyKinds = sets.NewString(
+	"CreateOptions",
+	"DeleteOptions",
+	"GetOptions",
+	"ListOptions",
+	"PatchOptions",
+	"Status",
+	"UpdateOptions",
+	"WatchEvent",
+)
+
+// NewOfflineRESTMapper constructs a RESTMapper without talking to an API server.
+// Mappings for the types registered in scheme are derived from the scheme itself,
+// and mappings for custom resources are read from the CustomResourceDefinition
+// manifests (JSON or YAML, possibly multi-document) found anywhere under fsys.
+//
+// It is meant for tools that need to resolve mappings ahead of time, such as
+// dry-run tooling, template renderers and unit tests. Use os.DirFS to read the
+// manifests from a directory on disk.
+func NewOfflineRESTMapper(scheme *runtime.Scheme, fsys fs.FS) (meta.RESTMapper, error) {
+	crds, err := readCRDsFromFS(fsys)
+	if err != nil {
+		return nil, err
+	}
+
+	var groupVersions []schema.GroupVersion
+	for _, crd := range crds {
+		groupVersions = append(groupVersions, crd.groupVersions()...)
+	}
+	if scheme != nil {
+		groupVersions = append(groupVersions, scheme.PrioritizedVersionsAllGroups()...)
+	}
+
+	mapper := meta.NewDefaultRESTMapper(groupVersions)
+	if scheme != nil {
+		for gvk := range scheme.AllKnownTypes() {
+			// skip internal versions, List types and the meta types
+			// that are registered in every group version
+			if gvk.Version == runtime.APIVersionInternal || strings.HasSuffix(gvk.Kind, "List") || metaOnlyKinds.Has(gvk.Kind) {
+				continue
+			}
+			scope := meta.RESTScopeNamespace
+			if builtinClusterScopedKinds.Has(gvk.Kind) {
+				scope = meta.RESTScopeRoot
+			}
+			mapper.Add(gvk, scope)
+		}
+	}
+
+	// CRDs are added last so that they take precedence over whatever
+	// the scheme might have guessed for the same kinds.
+	for _, crd := range crds {
+		for _, gv := range crd.groupVersions() {
+			gvk := gv.WithKind(crd.kind)
+			mapper.AddSpecific(gvk, gv.WithResource(crd.plural), gv.WithResource(crd.singular), crd.scope)
+		}
+	}
+
+	return mapper, nil
+}
+
+// offlineCRD is the subset of a CustomResourceDefinition needed to build
+// REST mappings for it.
+type offlineCRD struct {
+	group    string
+	kind     string
+	plural   string
+	singular string
+	versions []string
+	scope    meta.RESTScope
+}
+
+// groupVersions returns the served versions of the CRD, highest priority first.
+func (c offlineCRD) groupVersions() []schema.GroupVersion {
+	gvs := make([]schema.GroupVersion, 0, len(c.versions))
+	for _, v := range c.versions {
+		gvs = append(gvs, schema.GroupVersion{Group: c.group, Version: v})
+	}
+	return gvs
+}
+
+// readCRDsFromFS walks fsys and extracts every CustomResourceDefinition found
+// in its JSON and YAML files. Documents of any other kind are ignored.
+func readCRDsFromFS(fsys fs.FS) ([]offlineCRD, error) {
+	if fsys == nil {
+		return nil, nil
+	}
+
+	crdExts := sets.NewString(".json", ".yaml", ".yml")
+
+	var crds []offlineCRD
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || !crdExts.Has(path.Ext(p)) {
+			return nil
+		}
+
+		b, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		reader := k8syaml.NewYAMLReader(bufio.NewReader(bytes.NewReader(b)))
+		for {
+			doc, err := reader.Read()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return fmt.Errorf("unable to read %q: %w", p, err)
+			}
+
+			obj := &unstructured.Unstructured{}
+			if err := yaml.Unmarshal(doc, &obj.Object); err != nil {
+				return fmt.Errorf("unable to decode %q: %w", p, err)
+			}
+			if obj.Object == nil || obj.GetKind() != "CustomResourceDefinition" {
+				continue
+			}
+			crd, err := offlineCRDFor(obj)
+			if err != nil {
+				return fmt.Errorf("invalid CustomResourceDefinition %q in %q: %w", obj.GetName(), p, err)
+			}
+			crds = append(crds, crd)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return crds, nil
+}
+
+// offlineCRDFor extracts the REST mapping information from an apiextensions.k8s.io/v1
+// or v1beta1 CustomResourceDefinition.
+func offlineCRDFor(obj *unstructured.Unstructured) (offlineCRD, error) {
+	crd := offlineCRD{scope: meta.RESTScopeNamespace}
+
+	var err error
+	if crd.group, _, err = unstructured.NestedString(obj.Object, "spec", "group"); err != nil {
+		return crd, err
+	}
+	if crd.kind, _, err = unstructured.NestedString(obj.Object, "spec", "names", "kind"); err != nil {
+		return crd, err
+	}
+	if crd.plural, _, err = unstructured.NestedString(obj.Object, "spec", "names", "plural"); err != nil {
+		return crd, err
+	}
+	if crd.singular, _, err = unstructured.NestedString(obj.Object, "spec", "names", "singular"); err != nil {
+		return crd, err
+	}
+	if crd.group == "" || crd.kind == "" || crd.plural == "" {
+		return crd, fmt.Errorf("spec.group, spec.names.kind and spec.names.plural must be set")
+	}
+	if crd.singular == "" {
+		crd.singular = strings.ToLower(crd.kind)
+	}
+
+	scope, _, err := unstructured.NestedString(obj.Object, "spec", "scope")
+	if err != nil {
+		return crd, err
+	}
+	if scope == "Cluster" {
+		crd.scope = meta.RESTScopeRoot
+	}
+
+	versions, _, err := unstructured.NestedSlice(obj.Object, "spec", "versions")
+	if err != nil {
+		return crd, err
+	}
+	for _, v := range versions {
+		versionSpec, ok := v.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if served, found, _ := unstructured.NestedBool(versionSpec, "served"); found && !served {
+			continue
+		}
+		if name, _, _ := unstructured.NestedString(versionSpec, "name"); name != "" {
+			crd.versions = append(crd.versions, name)
+		}
+	}
+	// apiextensions.k8s.io/v1beta1 allows a single top-level version instead.
+	if legacyVersion, _, _ := unstructured.NestedString(obj.Object, "spec", "version"); legacyVersion != "" && !sets.NewString(crd.versions...).Has(legacyVersion) {
+		crd.versions = append(crd.versions, legacyVersion)
+	}
+	if len(crd.versions) == 0 {
+		return crd, fmt.Errorf("no served versions")
+	}
+
+	// order the versions the same way the API server would for discovery
+	sort.SliceStable(crd.versions, func(i, j int) bool {
+		return version.CompareKubeAwareVersionStrings(crd.versions[i], crd.versions[j]) > 0
+	})
+
+	return crd, nil
+}
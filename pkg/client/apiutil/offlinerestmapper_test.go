@@ -0,0 +1,134 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package apiutil_test
+
+import (
+	"testing/fstest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+const offlineCRDs = `
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: frigates.ship.example.com
+spec:
+  group: ship.example.com
+  names:
+    kind: Frigate
+    plural: frigates
+  scope: Namespaced
+  versions:
+  - name: v1beta1
+    served: true
+    storage: false
+  - name: v1
+    served: true
+    storage: true
+  - name: v1alpha1
+    served: false
+    storage: false
+---
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: harbors.ship.example.com
+spec:
+  group: ship.example.com
+  names:
+    kind: Harbor
+    plural: harbors
+    singular: harbor
+  scope: Cluster
+  versions:
+  - name: v1
+    served: true
+    storage: true
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: not-a-crd
+`
+
+var _ = Describe("Offline REST Mapper", func() {
+	var mapper meta.RESTMapper
+
+	BeforeEach(func() {
+		var err error
+		mapper, err = apiutil.NewOfflineRESTMapper(scheme.Scheme, fstest.MapFS{
+			"crds/ship.yaml": {Data: []byte(offlineCRDs)},
+			"README.md":      {Data: []byte("# ignored")},
+		})
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should map custom resources read from CRD manifests", func() {
+		mapping, err := mapper.RESTMapping(schema.GroupKind{Group: "ship.example.com", Kind: "Frigate"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.Resource).To(Equal(schema.GroupVersionResource{Group: "ship.example.com", Version: "v1", Resource: "frigates"}))
+		Expect(mapping.Scope.Name()).To(Equal(meta.RESTScopeNameNamespace))
+
+		mapping, err = mapper.RESTMapping(schema.GroupKind{Group: "ship.example.com", Kind: "Harbor"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.Scope.Name()).To(Equal(meta.RESTScopeNameRoot))
+	})
+
+	It("should only map served versions", func() {
+		_, err := mapper.RESTMapping(schema.GroupKind{Group: "ship.example.com", Kind: "Frigate"}, "v1beta1")
+		Expect(err).NotTo(HaveOccurred())
+
+		_, err = mapper.RESTMapping(schema.GroupKind{Group: "ship.example.com", Kind: "Frigate"}, "v1alpha1")
+		Expect(meta.IsNoMatchError(err)).To(BeTrue())
+	})
+
+	It("should map built-in types from the scheme", func() {
+		mapping, err := mapper.RESTMapping(schema.GroupKind{Group: "apps", Kind: "Deployment"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.Resource.Resource).To(Equal("deployments"))
+		Expect(mapping.Scope.Name()).To(Equal(meta.RESTScopeNameNamespace))
+
+		mapping, err = mapper.RESTMapping(schema.GroupKind{Kind: "Namespace"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(mapping.Scope.Name()).To(Equal(meta.RESTScopeNameRoot))
+	})
+
+	It("should reject CRDs without served versions", func() {
+		_, err := apiutil.NewOfflineRESTMapper(nil, fstest.MapFS{
+			"broken.yaml": {Data: []byte(`
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: sloops.ship.example.com
+spec:
+  group: ship.example.com
+  names:
+    kind: Sloop
+    plural: sloops
+  scope: Namespaced
+`)},
+		})
+		Expect(err).To(HaveOccurred())
+	})
+})
@@ -60,6 +60,11 @@ type Options struct {
 	// Opts is used to configure the warning handler responsible for
 	// surfacing and handling warnings messages sent by the API server.
 	Opts WarningHandlerOptions
+
+	// RESTClientOptions, if provided, tunes the REST clients used for the
+	// given GroupVersionKinds, e.g. to force JSON instead of protobuf, or
+	// to give a chatty type its own rate limiter.
+	RESTClientOptions map[schema.GroupVersionKind][]apiutil.RESTClientOption
 }
 
 // New returns a new Client using the provided config and Options.
@@ -118,6 +123,8 @@ func newClient(config *rest.Config, options Options) (*client, error) {
 		mapper: options.Mapper,
 		codecs: serializer.NewCodecFactory(options.Scheme),
 
+		restClientOptions: options.RESTClientOptions,
+
 		structuredResourceByType:   make(map[schema.GroupVersionKind]*resourceMeta),
 		unstructuredResourceByType: make(map[schema.GroupVersionKind]*resourceMeta),
 	}
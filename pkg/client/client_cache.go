@@ -44,6 +44,9 @@ type clientCache struct {
 	// codecs are used to create a REST client for a gvk
 	codecs serializer.CodecFactory
 
+	// restClientOptions tune the REST clients created for specific gvks
+	restClientOptions map[schema.GroupVersionKind][]apiutil.RESTClientOption
+
 	// structuredResourceByType caches structured type metadata
 	structuredResourceByType map[schema.GroupVersionKind]*resourceMeta
 	// unstructuredResourceByType caches unstructured type metadata
@@ -59,7 +62,7 @@ func (c *clientCache) newResource(gvk schema.GroupVersionKind, isList, isUnstruc
 		gvk.Kind = gvk.Kind[:len(gvk.Kind)-4]
 	}
 
-	client, err := apiutil.RESTClientForGVK(gvk, isUnstructured, c.config, c.codecs)
+	client, err := apiutil.RESTClientForGVK(gvk, isUnstructured, c.config, c.codecs, c.restClientOptions[gvk]...)
 	if err != nil {
 		return nil, err
 	}
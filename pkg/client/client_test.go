@@ -3298,6 +3298,63 @@ var _ = Describe("DelegatingClient", func() {
 			})
 		})
 	})
+	Describe("uncached read policies", func() {
+		var (
+			cachedReader *fakeSyncedReader
+			cl           client.Client
+		)
+
+		BeforeEach(func() {
+			cachedReader = &fakeSyncedReader{synced: map[schema.GroupVersionKind]bool{
+				appsv1.SchemeGroupVersion.WithKind("Deployment"): true,
+			}}
+			var err error
+			cl, err = client.New(cfg, client.Options{})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
+		It("should read types without synced informers live", func() {
+			dReader, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
+				CacheReader:               cachedReader,
+				Client:                    cl,
+				DefaultUncachedReadPolicy: client.UncachedReadLive,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dReader.List(context.Background(), &corev1.NamespaceList{})).To(Succeed())
+			Expect(cachedReader.Called).To(Equal(0))
+
+			Expect(dReader.List(context.Background(), &appsv1.DeploymentList{})).To(Succeed())
+			Expect(cachedReader.Called).To(Equal(1))
+		})
+
+		It("should apply the policy of the type", func() {
+			dReader, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
+				CacheReader: cachedReader,
+				Client:      cl,
+				UncachedReadPolicyByObject: map[client.Object]client.UncachedReadPolicy{
+					&corev1.Secret{}: client.UncachedReadError,
+				},
+				DefaultUncachedReadPolicy: client.UncachedReadLive,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = dReader.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "secret"}, &corev1.Secret{})
+			Expect(err).To(Equal(&client.ErrNotCached{GroupVersionKind: corev1.SchemeGroupVersion.WithKind("Secret")}))
+			Expect(cachedReader.Called).To(Equal(0))
+		})
+
+		It("should read from the cache by default", func() {
+			dReader, err := client.NewDelegatingClient(client.NewDelegatingClientInput{
+				CacheReader: cachedReader,
+				Client:      cl,
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(dReader.List(context.Background(), &corev1.NamespaceList{})).To(Succeed())
+			Expect(cachedReader.Called).To(Equal(1))
+		})
+	})
 })
 
 var _ = Describe("Patch", func() {
@@ -3483,3 +3540,12 @@ func (f *fakeReader) List(ctx context.Context, list client.ObjectList, opts ...c
 	f.Called++
 	return nil
 }
+
+type fakeSyncedReader struct {
+	fakeReader
+	synced map[schema.GroupVersionKind]bool
+}
+
+func (f *fakeSyncedReader) HasSyncedFor(gvk schema.GroupVersionKind) bool {
+	return f.synced[gvk]
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package client
+
+import (
+	"net/url"
+	"strings"
+)
+
+// IndexKey is a composite index value made of several parts, such as the
+// namespace and the name of a referenced object.
+type IndexKey []string
+
+// String encodes the key into a single index value. Every part is escaped,
+// so keys made of different parts never collide.
+func (k IndexKey) String() string {
+	parts := make([]string, len(k))
+	for i, part := range k {
+		parts[i] = url.QueryEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
+
+// CompositeIndexerFunc knows how to take an object and turn it into a series
+// of composite keys.
+type CompositeIndexerFunc func(Object) []IndexKey
+
+// IndexerFunc returns an IndexerFunc indexing the objects by their encoded
+// composite keys, to be passed to FieldIndexer.IndexField.
+func (f CompositeIndexerFunc) IndexerFunc() IndexerFunc {
+	return func(obj Object) []string {
+		keys := f(obj)
+		values := make([]string, 0, len(keys))
+		for _, key := range keys {
+			values = append(values, key.String())
+		}
+		return values
+	}
+}
+
+// MatchingIndexKey filters the list/delete operation on the objects indexed
+// under the given composite key for field, see CompositeIndexerFunc. Since the
+// key is specific to the index, it only works for lists served by a cache.
+func MatchingIndexKey(field string, key ...string) MatchingFields {
+	return MatchingFields{field: IndexKey(key).String()}
+}
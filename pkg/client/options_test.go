@@ -20,6 +20,7 @@ import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/labels"
@@ -66,6 +67,19 @@ var _ = Describe("ListOptions", func() {
 		o.ApplyToList(newListOpts)
 		Expect(newListOpts).To(Equal(o))
 	})
+	It("Should set UnsafeDisableDeepCopy", func() {
+		definitelyTrue := true
+		o := &client.ListOptions{UnsafeDisableDeepCopy: &definitelyTrue}
+		newListOpts := &client.ListOptions{}
+		o.ApplyToList(newListOpts)
+		Expect(newListOpts).To(Equal(o))
+	})
+	It("Should set UnsafeDisableDeepCopy through option", func() {
+		listOpts := &client.ListOptions{}
+		client.UnsafeDisableDeepCopy.ApplyToList(listOpts)
+		Expect(listOpts.UnsafeDisableDeepCopy).ToNot(BeNil())
+		Expect(*listOpts.UnsafeDisableDeepCopy).To(BeTrue())
+	})
 	It("Should not set anything", func() {
 		o := &client.ListOptions{}
 		newListOpts := &client.ListOptions{}
@@ -229,3 +243,21 @@ var _ = Describe("MatchingLabels", func() {
 		Expect(err.Error()).To(Equal(expectedErrMsg))
 	})
 })
+
+var _ = Describe("MatchingIndexKey", func() {
+	It("should match the values of composite indexer functions", func() {
+		indexer := client.CompositeIndexerFunc(func(obj client.Object) []client.IndexKey {
+			return []client.IndexKey{{obj.GetNamespace(), obj.GetName()}}
+		}).IndexerFunc()
+		values := indexer(&corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "ns", Name: "a/b"}})
+
+		o := &client.ListOptions{}
+		o.ApplyOptions([]client.ListOption{client.MatchingIndexKey("secretRef", "ns", "a/b")})
+		Expect(o.FieldSelector.Matches(fields.Set{"secretRef": values[0]})).To(BeTrue())
+	})
+
+	It("should not let keys made of different parts collide", func() {
+		Expect(client.IndexKey{"a/b", "c"}.String()).NotTo(Equal(client.IndexKey{"a", "b/c"}.String()))
+		Expect(client.IndexKey{"ns", "name"}.String()).To(Equal("ns/name"))
+	})
+})
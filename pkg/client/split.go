@@ -18,6 +18,7 @@ package client
 
 import (
 	"context"
+	"fmt"
 	"strings"
 
 	"k8s.io/apimachinery/pkg/api/meta"
@@ -34,6 +35,46 @@ type NewDelegatingClientInput struct {
 	Client            Client
 	UncachedObjects   []Object
 	CacheUnstructured bool
+
+	// UncachedReadPolicyByObject decides, per type, how reads of types that
+	// the cache has no synced informer for are served.
+	// It is only honoured if the CacheReader implements
+	// HasSyncedFor(schema.GroupVersionKind) bool, like the caches of the cache
+	// package do.
+	UncachedReadPolicyByObject map[Object]UncachedReadPolicy
+
+	// DefaultUncachedReadPolicy is the policy for all the types
+	// that are not in UncachedReadPolicyByObject.
+	// Defaults to UncachedReadFromCache.
+	DefaultUncachedReadPolicy UncachedReadPolicy
+}
+
+// UncachedReadPolicy decides how a delegating client serves reads of a type
+// that its cache has no synced informer for.
+type UncachedReadPolicy string
+
+const (
+	// UncachedReadFromCache reads from the cache anyways, which usually
+	// starts a new informer for the type and waits for it to sync.
+	UncachedReadFromCache UncachedReadPolicy = "FromCache"
+
+	// UncachedReadLive reads from the API server until the cache
+	// has a synced informer for the type.
+	UncachedReadLive UncachedReadPolicy = "Live"
+
+	// UncachedReadError fails the read with an ErrNotCached.
+	UncachedReadError UncachedReadPolicy = "Error"
+)
+
+// ErrNotCached is returned by delegating clients when reading a type that is
+// not cached, if the UncachedReadError policy applies to it.
+type ErrNotCached struct {
+	GroupVersionKind schema.GroupVersionKind
+}
+
+// Error returns the error.
+func (e *ErrNotCached) Error() string {
+	return fmt.Sprintf("%s is not cached", e.GroupVersionKind)
 }
 
 // NewDelegatingClient creates a new delegating client.
@@ -51,6 +92,15 @@ func NewDelegatingClient(in NewDelegatingClientInput) (Client, error) {
 		uncachedGVKs[gvk] = struct{}{}
 	}
 
+	uncachedReadPolicies := map[schema.GroupVersionKind]UncachedReadPolicy{}
+	for obj, policy := range in.UncachedReadPolicyByObject {
+		gvk, err := apiutil.GVKForObject(obj, in.Client.Scheme())
+		if err != nil {
+			return nil, err
+		}
+		uncachedReadPolicies[gvk] = policy
+	}
+
 	return &delegatingClient{
 		scheme: in.Client.Scheme(),
 		mapper: in.Client.RESTMapper(),
@@ -60,6 +110,9 @@ func NewDelegatingClient(in NewDelegatingClientInput) (Client, error) {
 			scheme:            in.Client.Scheme(),
 			uncachedGVKs:      uncachedGVKs,
 			cacheUnstructured: in.CacheUnstructured,
+
+			uncachedReadPolicies:      uncachedReadPolicies,
+			defaultUncachedReadPolicy: in.DefaultUncachedReadPolicy,
 		},
 		Writer:       in.Client,
 		StatusClient: in.Client,
@@ -97,6 +150,14 @@ type delegatingReader struct {
 	uncachedGVKs      map[schema.GroupVersionKind]struct{}
 	scheme            *runtime.Scheme
 	cacheUnstructured bool
+
+	uncachedReadPolicies      map[schema.GroupVersionKind]UncachedReadPolicy
+	defaultUncachedReadPolicy UncachedReadPolicy
+}
+
+// syncChecker is implemented by cache readers that know which types they have synced.
+type syncChecker interface {
+	HasSyncedFor(gvk schema.GroupVersionKind) bool
 }
 
 func (d *delegatingReader) shouldBypassCache(obj runtime.Object) (bool, error) {
@@ -115,9 +176,30 @@ func (d *delegatingReader) shouldBypassCache(obj runtime.Object) (bool, error) {
 	if !d.cacheUnstructured {
 		_, isUnstructured := obj.(*unstructured.Unstructured)
 		_, isUnstructuredList := obj.(*unstructured.UnstructuredList)
-		return isUnstructured || isUnstructuredList, nil
+		if isUnstructured || isUnstructuredList {
+			return true, nil
+		}
+	}
+	return d.shouldReadLive(gvk)
+}
+
+// shouldReadLive applies the uncached read policy of the gvk.
+func (d *delegatingReader) shouldReadLive(gvk schema.GroupVersionKind) (bool, error) {
+	policy, found := d.uncachedReadPolicies[gvk]
+	if !found {
+		policy = d.defaultUncachedReadPolicy
+	}
+	if policy == "" || policy == UncachedReadFromCache {
+		return false, nil
+	}
+	checker, ok := d.CacheReader.(syncChecker)
+	if !ok || checker.HasSyncedFor(gvk) {
+		return false, nil
+	}
+	if policy == UncachedReadError {
+		return false, &ErrNotCached{GroupVersionKind: gvk}
 	}
-	return false, nil
+	return true, nil
 }
 
 // Get retrieves an obj for a given object key from the Kubernetes Cluster.
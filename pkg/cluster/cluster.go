@@ -126,9 +126,20 @@ type Options struct {
 	// Use this to customize the event correlator and spam filter
 	//
 	// Deprecated: using this may cause goroutine leaks if the lifetime of your manager or controllers
-	// is shorter than the lifetime of your process.
+	// is shorter than the lifetime of your process. Use EventCorrelatorOptions instead.
 	EventBroadcaster record.EventBroadcaster
 
+	// EventCorrelatorOptions configures the spam filter and the aggregation of the Events
+	// emitted by the manager, e.g. to raise the rate limits of controllers emitting many Events,
+	// which are dropped silently otherwise. It is ignored if EventBroadcaster is set.
+	EventCorrelatorOptions record.CorrelatorOptions
+
+	// EventSink, if set, receives the Events emitted by the manager instead of the Kubernetes API.
+	EventSink record.EventSink
+
+	// DisableEventLogging stops logging the Events emitted by the manager.
+	DisableEventLogging bool
+
 	// makeBroadcaster allows deferring the creation of the broadcaster to
 	// avoid leaking goroutines if we never call Start on this manager.  It also
 	// returns whether or not this is a "owned" broadcaster, and as such should be
@@ -136,7 +147,7 @@ type Options struct {
 	makeBroadcaster intrec.EventBroadcasterProducer
 
 	// Dependency injection for testing
-	newRecorderProvider func(config *rest.Config, scheme *runtime.Scheme, logger logr.Logger, makeBroadcaster intrec.EventBroadcasterProducer) (*intrec.Provider, error)
+	newRecorderProvider func(config *rest.Config, scheme *runtime.Scheme, logger logr.Logger, makeBroadcaster intrec.EventBroadcasterProducer, options intrec.Options) (*intrec.Provider, error)
 }
 
 // Option can be used to manipulate Options.
@@ -186,7 +197,10 @@ func New(config *rest.Config, opts ...Option) (Cluster, error) {
 	// Create the recorder provider to inject event recorders for the components.
 	// TODO(directxman12): the log for the event provider should have a context (name, tags, etc) specific
 	// to the particular controller that it's being injected into, rather than a generic one like is here.
-	recorderProvider, err := options.newRecorderProvider(config, options.Scheme, options.Logger.WithName("events"), options.makeBroadcaster)
+	recorderProvider, err := options.newRecorderProvider(config, options.Scheme, options.Logger.WithName("events"), options.makeBroadcaster, intrec.Options{
+		Sink:           options.EventSink,
+		DisableLogging: options.DisableEventLogging,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -237,7 +251,7 @@ func setOptionsDefaults(options Options) Options {
 	if options.EventBroadcaster == nil {
 		// defer initialization to avoid leaking by default
 		options.makeBroadcaster = func() (record.EventBroadcaster, bool) {
-			return record.NewBroadcaster(), true
+			return record.NewBroadcasterWithCorrelatorOptions(options.EventCorrelatorOptions), true
 		}
 	} else {
 		options.makeBroadcaster = func() (record.EventBroadcaster, bool) {
@@ -19,10 +19,14 @@ package controller
 import (
 	"context"
 	"fmt"
+	"hash/fnv"
+	"strconv"
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/internal/controller"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -43,15 +47,141 @@ type Options struct {
 	// RateLimiter is used to limit how frequently requests may be queued.
 	// Defaults to MaxOfRateLimiter which has both overall and per-item rate limiting.
 	// The overall is a token bucket and the per-item is exponential.
+	// Use ratelimiter.New to tune the delays and the rate of the default one.
 	RateLimiter ratelimiter.RateLimiter
 
+	// NewQueue constructs the queue of the controller from its name and its RateLimiter,
+	// e.g. to use an instrumented or persistent queue. It is called when the controller
+	// starts. Defaults to workqueue.NewNamedRateLimitingQueue.
+	NewQueue func(controllerName string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface
+
 	// Log is the logger used for this controller and passed to each reconciliation
 	// request via the context field.
 	Log logr.Logger
 
+	// LogConstructor is used to construct the logger of every reconcile from its
+	// request, e.g. to add the kind of the reconciled objects to its values. The
+	// logger is passed to the Reconciler in the context, with a reconcileID value
+	// unique to the reconcile, so that all the log lines of a reconcile can be
+	// correlated. Defaults to Log with the name and namespace of the request.
+	LogConstructor func(request *reconcile.Request) logr.Logger
+
 	// CacheSyncTimeout refers to the time limit set to wait for syncing caches.
 	// Defaults to 2 minutes if not set.
 	CacheSyncTimeout time.Duration
+
+	// ReconcileTimeout is the deadline of the context passed to every call to the
+	// Reconciler, so that a stuck reconcile doesn't occupy a worker forever, as long
+	// as the Reconciler honors the context. Reconciles exceeding it are counted in
+	// the controller_runtime_reconcile_timeouts_total metric.
+	// Defaults to 0, which means no deadline.
+	ReconcileTimeout time.Duration
+
+	// RecoverPanic makes the controller recover the panics of the Reconciler and
+	// handle them like returned errors, logging their stack and counting them in
+	// the controller_runtime_reconcile_panics_total metric, so that a malformed
+	// object can't crash the whole manager. Defaults to false, or to true when the
+	// manager has the Recover PanicPolicy.
+	RecoverPanic bool
+
+	// DebounceWindow, if positive, delays the processing of the requests enqueued by
+	// the event handlers of the controller by this duration. The events received for
+	// an object during the window are coalesced into a single reconcile, e.g. when its
+	// status flaps or its owned objects are updated in a cascade. Requeues requested
+	// by the Reconciler aren't delayed. Defaults to 0, which means no delay.
+	DebounceWindow time.Duration
+
+	// RequeueAfterJitterFactor, if positive, adds a random duration of up to
+	// RequeueAfterJitterFactor*RequeueAfter to the RequeueAfter of the results of
+	// the Reconciler, so that the objects requeued with the same interval aren't
+	// all reconciled at the same time. Defaults to 0, which means no jitter.
+	RequeueAfterJitterFactor float64
+
+	// StartSpan, if set, is called at the start of every reconcile to start a tracing
+	// span, e.g. an OpenTelemetry span:
+	//
+	//  StartSpan: func(ctx context.Context, req reconcile.Request) (context.Context, func(reconcile.Result, error)) {
+	//    ctx, span := tracer.Start(ctx, "Reconcile", trace.WithAttributes(
+	//      attribute.String("namespace", req.Namespace), attribute.String("name", req.Name)))
+	//    return ctx, func(_ reconcile.Result, err error) {
+	//      if err != nil {
+	//        span.RecordError(err)
+	//      }
+	//      span.End()
+	//    }
+	//  }
+	//
+	// The returned context is passed to the Reconciler, so that the span is propagated
+	// to the requests of the clients using it, as long as the transport of the rest
+	// config of the manager is instrumented. The returned function is called with the
+	// result and the error of the reconcile once it is done. The events triggering a
+	// reconcile aren't known, as the events received for an object are coalesced into
+	// a single request by the queue.
+	StartSpan func(ctx context.Context, req reconcile.Request) (context.Context, func(reconcile.Result, error))
+
+	// StuckReconcileThreshold, if positive, makes the controller report the reconciles
+	// running longer than this duration: it logs the request and a dump of the goroutines,
+	// and counts them in the controller_runtime_stuck_reconciles metric until they return.
+	// This helps finding deadlocked or slow reconciles before the queue backs up.
+	// Defaults to 0, which means no reports.
+	StuckReconcileThreshold time.Duration
+
+	// ShardFilter, if set, makes the controller reconcile only the requests for which
+	// it returns true, so that the replicas of a deployment split the objects between
+	// them instead of idling behind leader election. The requests are filtered once
+	// mapped by the event handlers, so that an object and the objects it owns are
+	// reconciled by the same replica. Use ShardByHash to split the objects into a
+	// fixed number of shards, and set NeedLeaderElection to false so that all the
+	// replicas run the controller. To split objects by label instead, restrict the
+	// cache of every replica with a label selector.
+	ShardFilter func(request reconcile.Request) bool
+
+	// MaxRetries, if positive, is the number of times a request whose reconcile fails
+	// is retried with backoff. Once exceeded, the request is dropped until an event is
+	// received for it, it is logged, counted in the
+	// controller_runtime_reconcile_dead_letters_total metric and passed to DeadLetter.
+	// Defaults to 0, which means the requests are retried forever.
+	MaxRetries int
+
+	// DeadLetter is called with the requests dropped after MaxRetries retries and the
+	// error of their last reconcile, e.g. to emit an event, to set a condition on the
+	// object or to push the request to a channel.
+	DeadLetter func(ctx context.Context, request reconcile.Request, err error)
+
+	// SyncPeriod, if positive, makes the controller receive update events for all the
+	// objects of its Kind sources at this period, so that they are reconciled again, e.g.
+	// to sweep external resources periodically. Unlike the SyncPeriod of the manager, it
+	// doesn't resync the informers of the other controllers. It is the ResyncPeriod of
+	// the Kind sources watched by the controller which don't set theirs.
+	// Defaults to 0, which means the objects are only resynced with the informers.
+	SyncPeriod time.Duration
+
+	// WarmupBeforeLeaderElection makes the controller start its watches and sync their
+	// caches while its manager is a standby replica, before it is elected. The events
+	// are queued but not reconciled until the manager is elected, so that a failover
+	// reconciles right away instead of syncing the caches of the controller first.
+	// Defaults to false.
+	WarmupBeforeLeaderElection bool
+
+	// Priority, if set, returns the priority of the requests enqueued for the event of
+	// an object, e.g. PriorityFromAnnotation. The requests of the highest priority are
+	// reconciled first, and the requests of the same priority in the order they were
+	// enqueued; a request waiting in the queue keeps the highest priority it was
+	// enqueued with. Unless NewQueue is set too, the controller uses a priority queue,
+	// which doesn't report the workqueue metrics.
+	Priority func(obj client.Object) int
+
+	// SkipNameValidation allows adding the controller to a manager along with another
+	// controller of the same name. The name of a controller labels its workqueue and
+	// reconcile metrics, so controllers sharing a name report to the same metrics.
+	// Defaults to false, so that New returns an error if a controller with the same
+	// name was already added to the manager.
+	SkipNameValidation bool
+
+	// NeedLeaderElection indicates whether the controller needs to use leader election.
+	// Controllers that don't need it run on every replica of the manager, e.g.
+	// to keep a local state up to date. Defaults to true.
+	NeedLeaderElection *bool
 }
 
 // Controller implements a Kubernetes API.  A Controller manages a work queue fed reconcile.Requests
@@ -78,6 +208,32 @@ type Controller interface {
 	GetLogger() logr.Logger
 }
 
+// QueueStats describes the backlog of the queue of a controller.
+type QueueStats = controller.QueueStats
+
+// QueueInspector exposes the backlog of the queue of a controller, e.g. to admin
+// endpoints or autoscalers. It is implemented by the controllers returned by New
+// and NewUnmanaged:
+//
+//  if inspector, ok := c.(controller.QueueInspector); ok {
+//    stats := inspector.QueueStats()
+//  }
+type QueueInspector interface {
+	// QueueStats returns the length of the queue and the age of its oldest request.
+	// The stats are empty until the controller is started.
+	QueueStats() QueueStats
+
+	// NumRequeues returns the number of times a request has been retried with
+	// backoff since its last successful reconcile.
+	NumRequeues(request reconcile.Request) int
+}
+
+var _ QueueInspector = &controller.Controller{}
+
+var _ manager.WarmupRunnable = &controller.Controller{}
+
+var _ manager.UniquelyNamedRunnable = &controller.Controller{}
+
 // New returns a new Controller registered with the Manager.  The Manager will ensure that shared Caches have
 // been synced before the Controller is Started.
 func New(name string, mgr manager.Manager, options Options) (Controller, error) {
@@ -92,6 +248,12 @@ func New(name string, mgr manager.Manager, options Options) (Controller, error)
 
 // NewUnmanaged returns a new controller without adding it to the manager. The
 // caller is responsible for starting the returned controller.
+//
+// The controller runs from the call to Start until the context passed to Start
+// is done; its workers finish their reconciles and its queue is shut down before
+// Start returns. A controller can only be started once, so a controller that runs
+// conditionally, e.g. only while its CRD exists, is created anew every time it
+// must start.
 func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller, error) {
 	if options.Reconciler == nil {
 		return nil, fmt.Errorf("must specify Reconciler")
@@ -105,6 +267,16 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 		options.Log = mgr.GetLogger()
 	}
 
+	log := options.Log.WithName("controller").WithName(name)
+	if options.LogConstructor == nil {
+		options.LogConstructor = func(req *reconcile.Request) logr.Logger {
+			if req == nil {
+				return log
+			}
+			return log.WithValues("name", req.Name, "namespace", req.Namespace)
+		}
+	}
+
 	if options.MaxConcurrentReconciles <= 0 {
 		options.MaxConcurrentReconciles = 1
 	}
@@ -117,6 +289,27 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 		options.RateLimiter = workqueue.DefaultControllerRateLimiter()
 	}
 
+	if recoverPanic := mgr.GetControllerOptions().RecoverPanic; !options.RecoverPanic && recoverPanic != nil {
+		options.RecoverPanic = *recoverPanic
+	}
+
+	if options.NewQueue == nil && options.Priority != nil {
+		options.NewQueue = func(controllerName string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+			return controller.NewPriorityQueue(rateLimiter, controllerName)
+		}
+	}
+
+	rateLimiter := options.RateLimiter
+	var delays *controller.DelayRecorder
+	if options.NewQueue == nil {
+		// the queue stats count the requests retried with backoff from the default queue
+		delays = controller.NewDelayRecorder(options.RateLimiter)
+		rateLimiter = delays
+		options.NewQueue = func(controllerName string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+			return workqueue.NewNamedRateLimitingQueue(rateLimiter, controllerName)
+		}
+	}
+
 	// Inject dependencies into Reconciler
 	if err := mgr.SetFields(options.Reconciler); err != nil {
 		return nil, err
@@ -126,12 +319,63 @@ func NewUnmanaged(name string, mgr manager.Manager, options Options) (Controller
 	return &controller.Controller{
 		Do: options.Reconciler,
 		MakeQueue: func() workqueue.RateLimitingInterface {
-			return workqueue.NewNamedRateLimitingQueue(options.RateLimiter, name)
+			return options.NewQueue(name, rateLimiter)
 		},
-		MaxConcurrentReconciles: options.MaxConcurrentReconciles,
-		CacheSyncTimeout:        options.CacheSyncTimeout,
-		SetFields:               mgr.SetFields,
-		Name:                    name,
-		Log:                     options.Log.WithName("controller").WithName(name),
+		DelayRecorder:              delays,
+		MaxConcurrentReconciles:    options.MaxConcurrentReconciles,
+		CacheSyncTimeout:           options.CacheSyncTimeout,
+		ReconcileTimeout:           options.ReconcileTimeout,
+		RecoverPanic:               options.RecoverPanic,
+		DebounceWindow:             options.DebounceWindow,
+		RequeueAfterJitterFactor:   options.RequeueAfterJitterFactor,
+		StartSpan:                  options.StartSpan,
+		StuckReconcileThreshold:    options.StuckReconcileThreshold,
+		ShardFilter:                options.ShardFilter,
+		MaxRetries:                 options.MaxRetries,
+		DeadLetter:                 options.DeadLetter,
+		SyncPeriod:                 options.SyncPeriod,
+		WarmupBeforeLeaderElection: options.WarmupBeforeLeaderElection,
+		SkipNameValidation:         options.SkipNameValidation,
+		Priority:                   options.Priority,
+		SetFields:                  mgr.SetFields,
+		Name:                       name,
+		Log:                        log,
+		LogConstructor:             options.LogConstructor,
+		LeaderElected:              options.NeedLeaderElection,
 	}, nil
 }
+
+// ReconcileIDFromContext returns the reconcileID of the reconcile the context was
+// passed to, or an empty UID if it wasn't passed to a reconcile.
+func ReconcileIDFromContext(ctx context.Context) types.UID {
+	return controller.ReconcileIDFromContext(ctx)
+}
+
+// ShardByHash returns a ShardFilter selecting the requests of the shard with the
+// given index out of count shards. The requests are assigned to the shards by the
+// hash of their namespace and name, so that all the replicas using the same count
+// agree on the shard of every request; e.g. the replica with the ordinal i of a
+// StatefulSet of n replicas uses ShardByHash(i, n).
+func ShardByHash(index, count int) func(request reconcile.Request) bool {
+	return func(request reconcile.Request) bool {
+		if count <= 1 {
+			return true
+		}
+		h := fnv.New32a()
+		_, _ = h.Write([]byte(request.NamespacedName.String()))
+		return int(h.Sum32()%uint32(count)) == index
+	}
+}
+
+// PriorityFromAnnotation returns a Priority reading the priority of an object from
+// the integer value of its annotation with the given key. The objects without the
+// annotation, or with a value that isn't an integer, have the priority 0.
+func PriorityFromAnnotation(key string) func(obj client.Object) int {
+	return func(obj client.Object) int {
+		priority, err := strconv.Atoi(obj.GetAnnotations()[key])
+		if err != nil {
+			return 0
+		}
+		return priority
+	}
+}
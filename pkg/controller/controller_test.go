@@ -25,12 +25,15 @@ import (
 	. "github.com/onsi/gomega"
 	"go.uber.org/goleak"
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/util/workqueue"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/source"
@@ -90,6 +93,21 @@ var _ = Describe("controller.Controller", func() {
 			close(done)
 		})
 
+		It("should return an error if two controllers are registered with the same name", func() {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = controller.New("c1", m, controller.Options{Reconciler: rec})
+			Expect(err).NotTo(HaveOccurred())
+
+			_, err = controller.New("c1", m, controller.Options{Reconciler: rec})
+			Expect(err).To(MatchError(ContainSubstring(`a runnable named "c1" was already added`)))
+
+			By("skipping the validation of the name")
+			_, err = controller.New("c1", m, controller.Options{Reconciler: rec, SkipNameValidation: true})
+			Expect(err).NotTo(HaveOccurred())
+		})
+
 		It("should not leak goroutines when stopped", func() {
 			currentGRs := goleak.IgnoreCurrent()
 
@@ -150,6 +168,102 @@ var _ = Describe("controller.Controller", func() {
 			clientTransport.CloseIdleConnections()
 			Eventually(func() error { return goleak.Find(currentGRs) }).Should(Succeed())
 		})
+
+		It("should construct its queue with NewQueue when started", func() {
+			m, err := manager.New(cfg, manager.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			rateLimiter := workqueue.NewItemExponentialFailureRateLimiter(time.Millisecond, time.Second)
+			var queueName string
+			var queueRateLimiter ratelimiter.RateLimiter
+			c, err := controller.NewUnmanaged("new-queue-controller", m, controller.Options{
+				Reconciler:  rec,
+				RateLimiter: rateLimiter,
+				NewQueue: func(controllerName string, rateLimiter ratelimiter.RateLimiter) workqueue.RateLimitingInterface {
+					queueName, queueRateLimiter = controllerName, rateLimiter
+					return workqueue.NewRateLimitingQueue(rateLimiter)
+				},
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(queueName).To(BeEmpty())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(c.Start(ctx)).To(Succeed())
+			Expect(queueName).To(Equal("new-queue-controller"))
+			Expect(queueRateLimiter).To(BeIdenticalTo(rateLimiter))
+		})
+
+		It("should recover the panics of the Reconciler with the Recover panic policy of the manager", func() {
+			m, err := manager.New(cfg, manager.Options{PanicPolicy: manager.PanicPolicyRecover})
+			Expect(err).NotTo(HaveOccurred())
+
+			reconciled := make(chan struct{}, 10)
+			c, err := controller.NewUnmanaged("recover-panic-controller", m, controller.Options{
+				Reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+					reconciled <- struct{}{}
+					panic("expected panic")
+				}),
+			})
+			Expect(err).NotTo(HaveOccurred())
+
+			watchChan := make(chan event.GenericEvent, 1)
+			watchChan <- event.GenericEvent{Object: &corev1.Pod{}}
+			Expect(c.Watch(&source.Channel{Source: watchChan}, &handler.EnqueueRequestForObject{})).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			stopped := make(chan error)
+			go func() {
+				stopped <- c.Start(ctx)
+			}()
+			Eventually(reconciled).Should(Receive())
+			Eventually(reconciled).Should(Receive())
+			cancel()
+			Eventually(stopped).Should(Receive(BeNil()))
+		})
+	})
+})
+
+var _ = Describe("ShardByHash", func() {
+	It("should assign every request to exactly one shard", func() {
+		shards := []func(reconcile.Request) bool{
+			controller.ShardByHash(0, 3),
+			controller.ShardByHash(1, 3),
+			controller.ShardByHash(2, 3),
+		}
+		counts := make([]int, len(shards))
+		for i := 0; i < 300; i++ {
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: fmt.Sprintf("obj-%d", i)}}
+			matches := 0
+			for j, inShard := range shards {
+				if inShard(req) {
+					matches++
+					counts[j]++
+				}
+			}
+			Expect(matches).To(Equal(1))
+		}
+		for _, count := range counts {
+			Expect(count).To(BeNumerically(">", 0))
+		}
+	})
+
+	It("should select all the requests if there is a single shard", func() {
+		Expect(controller.ShardByHash(0, 1)(reconcile.Request{})).To(BeTrue())
+	})
+})
+
+var _ = Describe("PriorityFromAnnotation", func() {
+	It("should read the priority of an object from its annotation", func() {
+		priority := controller.PriorityFromAnnotation("example.com/priority")
+		obj := &corev1.Pod{}
+		Expect(priority(obj)).To(Equal(0))
+
+		obj.SetAnnotations(map[string]string{"example.com/priority": "7"})
+		Expect(priority(obj)).To(Equal(7))
+
+		obj.SetAnnotations(map[string]string{"example.com/priority": "urgent"})
+		Expect(priority(obj)).To(Equal(0))
 	})
 })
 
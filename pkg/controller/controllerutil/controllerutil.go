@@ -120,6 +120,24 @@ func SetOwnerReference(owner, object metav1.Object, scheme *runtime.Scheme) erro
 	return nil
 }
 
+// SetOwnerAnnotation is a helper method to make sure the given object refers to owner with the
+// annotation of the given key, for the owners which can't be set in the OwnerReferences of the
+// object, e.g. an owner in another namespace. Unlike an OwnerReference, the annotation doesn't make
+// the object garbage collected with its owner. The owners are enqueued from the annotation by
+// handler.EnqueueRequestForAnnotationOwner.
+func SetOwnerAnnotation(owner, object metav1.Object, key string) {
+	value := owner.GetName()
+	if owner.GetNamespace() != object.GetNamespace() {
+		value = owner.GetNamespace() + "/" + value
+	}
+	annotations := object.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[key] = value
+	object.SetAnnotations(annotations)
+}
+
 func upsertOwnerRef(ref metav1.OwnerReference, object metav1.Object) {
 	owners := object.GetOwnerReferences()
 	if idx := indexOwnerRef(owners, ref); idx == -1 {
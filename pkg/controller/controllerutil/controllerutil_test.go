@@ -105,6 +105,22 @@ var _ = Describe("Controllerutil", func() {
 		})
 	})
 
+	Describe("SetOwnerAnnotation", func() {
+		It("should set the name of an owner in the namespace of the object", func() {
+			rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Annotations: map[string]string{"foo": "bar"}}}
+			dep := &extensionsv1beta1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default"}}
+			controllerutil.SetOwnerAnnotation(dep, rs, "example.com/owner")
+			Expect(rs.GetAnnotations()).To(Equal(map[string]string{"foo": "bar", "example.com/owner": "foo"}))
+		})
+
+		It("should set the namespace and name of an owner in another namespace", func() {
+			rs := &appsv1.ReplicaSet{ObjectMeta: metav1.ObjectMeta{Namespace: "default"}}
+			dep := &extensionsv1beta1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "parents"}}
+			controllerutil.SetOwnerAnnotation(dep, rs, "example.com/owner")
+			Expect(rs.GetAnnotations()).To(HaveKeyWithValue("example.com/owner", "parents/foo"))
+		})
+	})
+
 	Describe("SetControllerReference", func() {
 		It("should set the OwnerReference if it can find the group version kind", func() {
 			rs := &appsv1.ReplicaSet{}
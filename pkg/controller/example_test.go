@@ -19,6 +19,7 @@ package controller_test
 import (
 	"context"
 	"os"
+	"time"
 
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
@@ -28,6 +29,7 @@ import (
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
 	"sigs.k8s.io/controller-runtime/pkg/manager/signals"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 )
@@ -53,6 +55,25 @@ func ExampleNew() {
 	}
 }
 
+// This example creates a new Controller named "pod-controller" retrying failing Pods for up to an hour
+// between attempts, instead of the default of 1000 seconds.
+func ExampleNew_rateLimiter() {
+	_, err := controller.New("pod-controller", mgr, controller.Options{
+		Reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+			// Your business logic to implement the API by creating, updating, deleting objects goes here.
+			return reconcile.Result{}, nil
+		}),
+		RateLimiter: ratelimiter.New(ratelimiter.Options{
+			BaseDelay: time.Second,
+			MaxDelay:  time.Hour,
+		}),
+	})
+	if err != nil {
+		log.Error(err, "unable to create pod-controller")
+		os.Exit(1)
+	}
+}
+
 // This example starts a new Controller named "pod-controller" to Watch Pods and call a no-op Reconciler.
 func ExampleController() {
 	// mgr is a manager.Manager
@@ -163,3 +184,44 @@ func ExampleNewUnmanaged() {
 	// Stop our controller.
 	cancel()
 }
+
+// This example runs a controller again after it was stopped. A controller can only
+// be started once, so a new one is created every time it must run.
+func ExampleNewUnmanaged_restart() {
+	// mgr is a manager.Manager
+
+	// run blocks and runs a new pod-controller until ctx is done.
+	run := func(ctx context.Context) error {
+		c, err := controller.NewUnmanaged("pod-controller", mgr, controller.Options{
+			Reconciler: reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				return reconcile.Result{}, nil
+			}),
+		})
+		if err != nil {
+			return err
+		}
+		if err := c.Watch(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestForObject{}); err != nil {
+			return err
+		}
+		return c.Start(ctx)
+	}
+
+	// Run the controller, e.g. while a CRD it needs is installed, then stop it
+	// by cancelling its context.
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		if err := run(ctx); err != nil {
+			log.Error(err, "cannot run pod-controller")
+		}
+	}()
+	cancel()
+
+	// Run it again later with a new context.
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		if err := run(ctx); err != nil {
+			log.Error(err, "cannot run pod-controller")
+		}
+	}()
+}
@@ -0,0 +1,56 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"strings"
+
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EnqueueRequestForAnnotationOwner enqueues a Request for the owner an object refers to
+// with the annotation of the given key, for the owners which can't be set in the
+// OwnerReferences of the object, e.g. an owner in another namespace.
+//
+// The value of the annotation is the "<namespace>/<name>" of the owner, or its name if
+// it is in the namespace of the object, or if it is cluster-scoped and the object too.
+// The objects without the annotation are ignored.
+func EnqueueRequestForAnnotationOwner(key string) EventHandler {
+	return EnqueueRequestsFromMapFunc(func(obj client.Object) []reconcile.Request {
+		owner, ok := OwnerFromAnnotation(obj, key)
+		if !ok {
+			return nil
+		}
+		return []reconcile.Request{{NamespacedName: owner}}
+	})
+}
+
+// OwnerFromAnnotation returns the owner obj refers to with the annotation of the given
+// key, as read by EnqueueRequestForAnnotationOwner, and false if obj has no such owner.
+func OwnerFromAnnotation(obj client.Object, key string) (types.NamespacedName, bool) {
+	value, ok := obj.GetAnnotations()[key]
+	if !ok || value == "" {
+		return types.NamespacedName{}, false
+	}
+	owner := types.NamespacedName{Namespace: obj.GetNamespace(), Name: value}
+	if i := strings.Index(value, "/"); i >= 0 {
+		owner = types.NamespacedName{Namespace: value[:i], Name: value[i+1:]}
+	}
+	return owner, owner.Name != ""
+}
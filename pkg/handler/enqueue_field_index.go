@@ -0,0 +1,105 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"context"
+	"fmt"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// EnqueueRequestsFromFieldIndex enqueues Requests for the objects referencing the object in the Event
+// through a field index, e.g. for the Deployments mounting a Secret.  On each Event, the objects of the
+// type of list in the namespace of the object in the Event whose indexKey field is indexed with the name
+// of the object are listed from the cache and reconciled.
+//
+// The index must be registered with the FieldIndexer of the manager before the manager is started:
+//
+//  mgr.GetFieldIndexer().IndexField(ctx, &appsv1.Deployment{}, "spec.secretNames", secretNames)
+//  ...
+//  Watches(&source.Kind{Type: &corev1.Secret{}},
+//      handler.EnqueueRequestsFromFieldIndex(&appsv1.DeploymentList{}, "spec.secretNames"))
+//
+// Listing failures are logged like with EnqueueRequestsFromContextMapFunc.
+func EnqueueRequestsFromFieldIndex(list client.ObjectList, indexKey string) EventHandler {
+	e := &enqueueRequestsFromFieldIndex{
+		list:     list,
+		indexKey: indexKey,
+	}
+	e.enqueueRequestsFromContextMapFunc = &enqueueRequestsFromContextMapFunc{
+		toRequests: e.toRequests,
+		ctx:        context.Background(),
+	}
+	return e
+}
+
+var _ EventHandler = &enqueueRequestsFromFieldIndex{}
+
+type enqueueRequestsFromFieldIndex struct {
+	*enqueueRequestsFromContextMapFunc
+
+	// list is the type of the referencing objects, it is copied on each listing
+	list client.ObjectList
+
+	// indexKey is the field index of the referencing objects
+	indexKey string
+
+	// reader lists the referencing objects, it is the cache of the manager
+	reader client.Reader
+}
+
+func (e *enqueueRequestsFromFieldIndex) toRequests(ctx context.Context, object client.Object) ([]reconcile.Request, error) {
+	if e.reader == nil {
+		return nil, fmt.Errorf("no cache to list %T by the field index %q", e.list, e.indexKey)
+	}
+
+	list := e.list.DeepCopyObject().(client.ObjectList)
+	if err := e.reader.List(ctx, list,
+		client.InNamespace(object.GetNamespace()),
+		client.MatchingFields{e.indexKey: object.GetName()}); err != nil {
+		return nil, err
+	}
+
+	items, err := meta.ExtractList(list)
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]reconcile.Request, 0, len(items))
+	for _, item := range items {
+		accessor, err := meta.Accessor(item)
+		if err != nil {
+			return requests, err
+		}
+		requests = append(requests, reconcile.Request{NamespacedName: types.NamespacedName{
+			Namespace: accessor.GetNamespace(),
+			Name:      accessor.GetName(),
+		}})
+	}
+	return requests, nil
+}
+
+// InjectCache implements inject.Cache, the referencing objects are listed from the cache, which
+// holds the field indexes.
+func (e *enqueueRequestsFromFieldIndex) InjectCache(c cache.Cache) error {
+	e.reader = c
+	return nil
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package handler
 
 import (
+	"context"
+
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 )
@@ -95,3 +98,90 @@ func (e *enqueueRequestsFromMapFunc) InjectFunc(f inject.Func) error {
 	}
 	return f(e.toRequests)
 }
+
+// ContextMapFunc is the signature required for enqueueing requests with a function which
+// looks up the requests, e.g. with a client.Reader, and may fail.
+// This type is usually used with EnqueueRequestsFromContextMapFunc when registering an event handler.
+type ContextMapFunc func(context.Context, client.Object) ([]reconcile.Request, error)
+
+// EnqueueRequestsFromContextMapFunc enqueues Requests like EnqueueRequestsFromMapFunc, with a
+// function receiving a context, which is canceled once the manager stops, and returning an error.
+// The errors are logged, and the Requests returned along with an error are enqueued anyway.
+func EnqueueRequestsFromContextMapFunc(fn ContextMapFunc) EventHandler {
+	return &enqueueRequestsFromContextMapFunc{
+		toRequests: fn,
+		ctx:        context.Background(),
+	}
+}
+
+var _ EventHandler = &enqueueRequestsFromContextMapFunc{}
+
+var contextMapLog = logf.RuntimeLog.WithName("eventhandler").WithName("EnqueueRequestsFromContextMapFunc")
+
+type enqueueRequestsFromContextMapFunc struct {
+	// Mapper transforms the argument into a slice of keys to be reconciled
+	toRequests ContextMapFunc
+
+	// ctx is passed to toRequests, it is canceled once the manager stops
+	ctx context.Context
+}
+
+// Create implements EventHandler.
+func (e *enqueueRequestsFromContextMapFunc) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(q, evt.Object, reqs)
+}
+
+// Update implements EventHandler.
+func (e *enqueueRequestsFromContextMapFunc) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(q, evt.ObjectOld, reqs)
+	e.mapAndEnqueue(q, evt.ObjectNew, reqs)
+}
+
+// Delete implements EventHandler.
+func (e *enqueueRequestsFromContextMapFunc) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(q, evt.Object, reqs)
+}
+
+// Generic implements EventHandler.
+func (e *enqueueRequestsFromContextMapFunc) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	reqs := map[reconcile.Request]empty{}
+	e.mapAndEnqueue(q, evt.Object, reqs)
+}
+
+func (e *enqueueRequestsFromContextMapFunc) mapAndEnqueue(q workqueue.RateLimitingInterface, object client.Object, reqs map[reconcile.Request]empty) {
+	requests, err := e.toRequests(e.ctx, object)
+	if err != nil {
+		contextMapLog.Error(err, "Failed to map an object to the requests to reconcile",
+			"namespace", object.GetNamespace(), "name", object.GetName())
+	}
+	for _, req := range requests {
+		_, ok := reqs[req]
+		if !ok {
+			q.Add(req)
+			reqs[req] = empty{}
+		}
+	}
+}
+
+// InjectFunc implements inject.Injector.
+func (e *enqueueRequestsFromContextMapFunc) InjectFunc(f inject.Func) error {
+	if f == nil {
+		return nil
+	}
+	return f(e.toRequests)
+}
+
+// InjectStopChannel implements inject.Stoppable, the context passed to the function is
+// canceled once stop is closed.
+func (e *enqueueRequestsFromContextMapFunc) InjectStopChannel(stop <-chan struct{}) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		<-stop
+		cancel()
+	}()
+	e.ctx = ctx
+	return nil
+}
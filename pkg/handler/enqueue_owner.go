@@ -42,12 +42,14 @@ var log = logf.RuntimeLog.WithName("eventhandler").WithName("EnqueueRequestForOw
 //
 // - a source.Kind Source with Type of Pod.
 //
-// - a handler.EnqueueRequestForOwner EventHandler with an OwnerType of ReplicaSet and IsController set to true.
+// - a handler.EnqueueRequestForOwner EventHandler with an OwnerType of ReplicaSet and IsController set to true,
+// e.g. handler.NewEnqueueRequestForOwner(&appsv1.ReplicaSet{}, handler.OnlyControllerOwner()).
 type EnqueueRequestForOwner struct {
 	// OwnerType is the type of the Owner object to look for in OwnerReferences.  Only Group and Kind are compared.
 	OwnerType runtime.Object
 
 	// IsController if set will only look at the first OwnerReference with Controller: true.
+	// NewEnqueueRequestForOwner sets it with the OnlyControllerOwner option.
 	IsController bool
 
 	// groupKind is the cached Group and Kind from OwnerType
@@ -57,6 +59,29 @@ type EnqueueRequestForOwner struct {
 	mapper meta.RESTMapper
 }
 
+// OwnerOption modifies an EnqueueRequestForOwner created by NewEnqueueRequestForOwner.
+type OwnerOption func(e *EnqueueRequestForOwner)
+
+// OnlyControllerOwner makes the EnqueueRequestForOwner enqueue a Request for the controller
+// OwnerReference of an object only, not for its other owners.
+func OnlyControllerOwner() OwnerOption {
+	return func(e *EnqueueRequestForOwner) {
+		e.IsController = true
+	}
+}
+
+// NewEnqueueRequestForOwner returns an EnqueueRequestForOwner enqueueing Requests for every
+// owner of type ownerType of an object, or only for its controller with OnlyControllerOwner:
+//
+//  handler.NewEnqueueRequestForOwner(&appsv1.ReplicaSet{}, handler.OnlyControllerOwner())
+func NewEnqueueRequestForOwner(ownerType runtime.Object, opts ...OwnerOption) *EnqueueRequestForOwner {
+	e := &EnqueueRequestForOwner{OwnerType: ownerType}
+	for _, opt := range opts {
+		opt(e)
+	}
+	return e
+}
+
 // Create implements EventHandler.
 func (e *EnqueueRequestForOwner) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
 	reqs := map[reconcile.Request]empty{}
@@ -125,7 +150,7 @@ func (e *EnqueueRequestForOwner) getOwnerReconcileRequest(object metav1.Object,
 		if err != nil {
 			log.Error(err, "Could not parse OwnerReference APIVersion",
 				"api version", ref.APIVersion)
-			return
+			continue
 		}
 
 		// Compare the OwnerReference Group and Kind against the OwnerType Group and Kind specified by the user.
@@ -139,10 +164,10 @@ func (e *EnqueueRequestForOwner) getOwnerReconcileRequest(object metav1.Object,
 			}}
 
 			// if owner is not namespaced then we should set the namespace to the empty
-			mapping, err := e.mapper.RESTMapping(e.groupKind, refGV.Version)
+			mapping, err := e.restMapping(refGV.Version)
 			if err != nil {
 				log.Error(err, "Could not retrieve rest mapping", "kind", e.groupKind)
-				return
+				continue
 			}
 			if mapping.Scope.Name() != meta.RESTScopeNameRoot {
 				request.Namespace = object.GetNamespace()
@@ -153,6 +178,17 @@ func (e *EnqueueRequestForOwner) getOwnerReconcileRequest(object metav1.Object,
 	}
 }
 
+// restMapping returns the RESTMapping of the OwnerType in the version of an OwnerReference, or
+// in the preferred version of the OwnerType if the version of the OwnerReference isn't served
+// anymore, since the scope of a kind is the same in all its versions.
+func (e *EnqueueRequestForOwner) restMapping(version string) (*meta.RESTMapping, error) {
+	mapping, err := e.mapper.RESTMapping(e.groupKind, version)
+	if err == nil || !meta.IsNoMatchError(err) {
+		return mapping, err
+	}
+	return e.mapper.RESTMapping(e.groupKind)
+}
+
 // getOwnersReferences returns the OwnerReferences for an object as specified by the EnqueueRequestForOwner
 // - if IsController is true: only take the Controller OwnerReference (if found)
 // - if IsController is false: take all OwnerReferences.
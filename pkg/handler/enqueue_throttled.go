@@ -0,0 +1,117 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package handler
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// ThrottleUpdates throttles the Requests enqueued by handler on update events, for chatty objects
+// whose frequent updates (e.g. the heartbeats of Nodes) don't all need to be reconciled right away:
+// a Request is added to the queue at most once every interval on update events, and the Requests
+// enqueued sooner are added once the interval has passed, so that a burst of updates is reconciled
+// once at its start and once at its end.  Create, delete and generic events are not throttled.
+//
+//  Watches(&source.Kind{Type: &corev1.Node{}},
+//      handler.ThrottleUpdates(&handler.EnqueueRequestForObject{}, 30*time.Second))
+func ThrottleUpdates(handler EventHandler, interval time.Duration) EventHandler {
+	return &throttledUpdates{
+		EventHandler: handler,
+		interval:     interval,
+		added:        map[reconcile.Request]time.Time{},
+	}
+}
+
+var _ EventHandler = &throttledUpdates{}
+
+type throttledUpdates struct {
+	EventHandler
+
+	// interval is the minimum time between two additions of a Request on update events
+	interval time.Duration
+
+	// added is the time each Request was, or is to be, added on an update event. The Requests
+	// added more than an interval ago are pruned once every interval.
+	added  map[reconcile.Request]time.Time
+	pruned time.Time
+	lock   sync.Mutex
+}
+
+// Update implements EventHandler.
+func (t *throttledUpdates) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	t.EventHandler.Update(evt, &throttledQueue{RateLimitingInterface: q, throttle: t})
+}
+
+// InjectFunc implements inject.Injector, the fields are injected into the wrapped handler.
+func (t *throttledUpdates) InjectFunc(f inject.Func) error {
+	if f == nil {
+		return nil
+	}
+	return f(t.EventHandler)
+}
+
+// delay returns how long to wait before adding req to the queue, and records when it is added.
+func (t *throttledUpdates) delay(req reconcile.Request) time.Duration {
+	now := time.Now()
+
+	t.lock.Lock()
+	defer t.lock.Unlock()
+	if now.Sub(t.pruned) >= t.interval {
+		for r, at := range t.added {
+			if now.Sub(at) >= t.interval {
+				delete(t.added, r)
+			}
+		}
+		t.pruned = now
+	}
+
+	last, ok := t.added[req]
+	switch {
+	case !ok || now.Sub(last) >= t.interval:
+		t.added[req] = now
+		return 0
+	case now.Before(last):
+		// the Request is already to be added, the queue coalesces both additions
+		return last.Sub(now)
+	default:
+		next := last.Add(t.interval)
+		t.added[req] = next
+		return next.Sub(now)
+	}
+}
+
+// throttledQueue delays the Requests added to it as decided by throttle.
+type throttledQueue struct {
+	workqueue.RateLimitingInterface
+	throttle *throttledUpdates
+}
+
+func (q *throttledQueue) Add(item interface{}) {
+	if req, ok := item.(reconcile.Request); ok {
+		if d := q.throttle.delay(req); d > 0 {
+			q.RateLimitingInterface.AddAfter(item, d)
+			return
+		}
+	}
+	q.RateLimitingInterface.Add(item)
+}
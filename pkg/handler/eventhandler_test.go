@@ -17,6 +17,10 @@
 package handler_test
 
 import (
+	"context"
+	"fmt"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	appsv1 "k8s.io/api/apps/v1"
@@ -24,15 +28,18 @@ import (
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllertest"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 )
 
 var _ = Describe("Eventhandler", func() {
@@ -314,6 +321,85 @@ var _ = Describe("Eventhandler", func() {
 		})
 	})
 
+	Describe("EnqueueRequestsFromContextMapFunc", func() {
+		It("should enqueue the Requests returned by the function, even along with an error", func() {
+			instance := handler.EnqueueRequestsFromContextMapFunc(func(ctx context.Context, a client.Object) ([]reconcile.Request, error) {
+				defer GinkgoRecover()
+				Expect(ctx).NotTo(BeNil())
+				Expect(a).To(Equal(pod))
+				return []reconcile.Request{
+					{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}},
+				}, fmt.Errorf("expected error")
+			})
+
+			instance.Create(event.CreateEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "foo", Name: "bar"}}))
+		})
+
+		It("should cancel the context of the function once the manager stops", func() {
+			var fnCtx context.Context
+			instance := handler.EnqueueRequestsFromContextMapFunc(func(ctx context.Context, _ client.Object) ([]reconcile.Request, error) {
+				fnCtx = ctx
+				return nil, nil
+			})
+			stop := make(chan struct{})
+			Expect(inject.StopChannelInto(stop, instance)).To(BeTrue())
+
+			instance.Generic(event.GenericEvent{Object: pod}, q)
+			Expect(fnCtx.Err()).NotTo(HaveOccurred())
+			close(stop)
+			Eventually(fnCtx.Done()).Should(BeClosed())
+			Expect(q.Len()).To(Equal(0))
+		})
+	})
+
+	Describe("EnqueueRequestsFromFieldIndex", func() {
+		var cache *indexedCache
+		BeforeEach(func() {
+			cache = &indexedCache{
+				FakeInformers: &informertest.FakeInformers{},
+				items: []appsv1.Deployment{
+					{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "foo"}},
+					{ObjectMeta: metav1.ObjectMeta{Namespace: "biz", Name: "bar"}},
+				},
+			}
+		})
+
+		It("should enqueue Requests for the objects indexed with the name of the object", func() {
+			instance := handler.EnqueueRequestsFromFieldIndex(&appsv1.DeploymentList{}, "spec.secretNames")
+			Expect(inject.CacheInto(cache, instance)).To(BeTrue())
+
+			instance.Create(event.CreateEvent{Object: pod}, q)
+			Expect(cache.opts.Namespace).To(Equal("biz"))
+			Expect(cache.opts.FieldSelector.String()).To(Equal("spec.secretNames=baz"))
+			Expect(q.Len()).To(Equal(2))
+
+			i1, _ := q.Get()
+			i2, _ := q.Get()
+			Expect([]interface{}{i1, i2}).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "foo"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "bar"}},
+			))
+		})
+
+		It("should enqueue nothing if listing the objects fails", func() {
+			instance := handler.EnqueueRequestsFromFieldIndex(&appsv1.DeploymentList{}, "spec.secretNames")
+			cache.err = fmt.Errorf("expected error")
+			Expect(inject.CacheInto(cache, instance)).To(BeTrue())
+
+			instance.Delete(event.DeleteEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(0))
+		})
+
+		It("should enqueue nothing without a cache", func() {
+			instance := handler.EnqueueRequestsFromFieldIndex(&appsv1.DeploymentList{}, "spec.secretNames")
+			instance.Generic(event.GenericEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(0))
+		})
+	})
+
 	Describe("EnqueueRequestForOwner", func() {
 		It("should enqueue a Request with the Owner of the object in the CreateEvent.", func() {
 			instance := handler.EnqueueRequestForOwner{
@@ -695,6 +781,119 @@ var _ = Describe("Eventhandler", func() {
 			})
 		})
 
+		Context("created with NewEnqueueRequestForOwner", func() {
+			var rsMapper meta.RESTMapper
+			BeforeEach(func() {
+				restMapper := meta.NewDefaultRESTMapper(nil)
+				restMapper.Add(appsv1.SchemeGroupVersion.WithKind("ReplicaSet"), meta.RESTScopeNamespace)
+				rsMapper = restMapper
+				pod.OwnerReferences = []metav1.OwnerReference{
+					{
+						Name:       "foo1-parent",
+						Kind:       "ReplicaSet",
+						APIVersion: "apps/v1",
+					},
+					{
+						Name:       "foo2-parent",
+						Kind:       "ReplicaSet",
+						APIVersion: "apps/v1",
+						Controller: &t,
+					},
+				}
+			})
+
+			It("should enqueue reconcile.Requests for all owners by default.", func() {
+				instance := handler.NewEnqueueRequestForOwner(&appsv1.ReplicaSet{})
+				Expect(instance.InjectScheme(scheme.Scheme)).To(Succeed())
+				Expect(instance.InjectMapper(rsMapper)).To(Succeed())
+				instance.Create(event.CreateEvent{Object: pod}, q)
+				Expect(q.Len()).To(Equal(2))
+			})
+
+			It("should enqueue a reconcile.Request for the controller only with OnlyControllerOwner.", func() {
+				instance := handler.NewEnqueueRequestForOwner(&appsv1.ReplicaSet{}, handler.OnlyControllerOwner())
+				Expect(instance.InjectScheme(scheme.Scheme)).To(Succeed())
+				Expect(instance.InjectMapper(rsMapper)).To(Succeed())
+				instance.Create(event.CreateEvent{Object: pod}, q)
+				Expect(q.Len()).To(Equal(1))
+
+				i, _ := q.Get()
+				Expect(i).To(Equal(reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: pod.GetNamespace(), Name: "foo2-parent"}}))
+			})
+		})
+
+		Context("with a cluster-scoped OwnerType", func() {
+			var nodeMapper meta.RESTMapper
+			BeforeEach(func() {
+				restMapper := meta.NewDefaultRESTMapper([]schema.GroupVersion{corev1.SchemeGroupVersion})
+				restMapper.Add(corev1.SchemeGroupVersion.WithKind("Node"), meta.RESTScopeRoot)
+				nodeMapper = restMapper
+			})
+
+			It("should enqueue a reconcile.Request without a namespace.", func() {
+				instance := handler.NewEnqueueRequestForOwner(&corev1.Node{})
+				Expect(instance.InjectScheme(scheme.Scheme)).To(Succeed())
+				Expect(instance.InjectMapper(nodeMapper)).To(Succeed())
+				pod.OwnerReferences = []metav1.OwnerReference{
+					{
+						Name:       "node-1",
+						Kind:       "Node",
+						APIVersion: "v1",
+					},
+				}
+				instance.Create(event.CreateEvent{Object: pod}, q)
+				Expect(q.Len()).To(Equal(1))
+
+				i, _ := q.Get()
+				Expect(i).To(Equal(reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: "", Name: "node-1"}}))
+			})
+
+			It("should use the scope of the OwnerType if the OwnerReference version isn't mapped.", func() {
+				instance := handler.NewEnqueueRequestForOwner(&corev1.Node{})
+				Expect(instance.InjectScheme(scheme.Scheme)).To(Succeed())
+				Expect(instance.InjectMapper(nodeMapper)).To(Succeed())
+				pod.OwnerReferences = []metav1.OwnerReference{
+					{
+						Name:       "node-1",
+						Kind:       "Node",
+						APIVersion: "v1beta1",
+					},
+				}
+				instance.Create(event.CreateEvent{Object: pod}, q)
+				Expect(q.Len()).To(Equal(1))
+
+				i, _ := q.Get()
+				Expect(i).To(Equal(reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: "", Name: "node-1"}}))
+			})
+
+			It("should not drop the remaining OwnerReferences if one can't be parsed.", func() {
+				instance := handler.NewEnqueueRequestForOwner(&corev1.Node{})
+				Expect(instance.InjectScheme(scheme.Scheme)).To(Succeed())
+				Expect(instance.InjectMapper(nodeMapper)).To(Succeed())
+				pod.OwnerReferences = []metav1.OwnerReference{
+					{
+						Name:       "node-1",
+						Kind:       "Node",
+						APIVersion: "v1/bad/version",
+					},
+					{
+						Name:       "node-2",
+						Kind:       "Node",
+						APIVersion: "v1",
+					},
+				}
+				instance.Create(event.CreateEvent{Object: pod}, q)
+				Expect(q.Len()).To(Equal(1))
+
+				i, _ := q.Get()
+				Expect(i).To(Equal(reconcile.Request{
+					NamespacedName: types.NamespacedName{Namespace: "", Name: "node-2"}}))
+			})
+		})
+
 		Context("with a nil object", func() {
 			It("should do nothing.", func() {
 				instance := handler.EnqueueRequestForOwner{
@@ -803,6 +1002,91 @@ var _ = Describe("Eventhandler", func() {
 		})
 	})
 
+	Describe("EnqueueRequestForAnnotationOwner", func() {
+		var instance handler.EventHandler
+		BeforeEach(func() {
+			instance = handler.EnqueueRequestForAnnotationOwner("example.com/owner")
+		})
+
+		It("should enqueue a Request for the owner in the namespace of the object", func() {
+			pod.SetAnnotations(map[string]string{"example.com/owner": "foo-parent"})
+			instance.Create(event.CreateEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "biz", Name: "foo-parent"}}))
+		})
+
+		It("should enqueue a Request for the owner in another namespace", func() {
+			pod.SetAnnotations(map[string]string{"example.com/owner": "foo/foo-parent"})
+			instance.Delete(event.DeleteEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Namespace: "foo", Name: "foo-parent"}}))
+		})
+
+		It("should enqueue a Request for a cluster-scoped owner", func() {
+			pod.SetAnnotations(map[string]string{"example.com/owner": "/foo-parent"})
+			instance.Generic(event.GenericEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+
+			i, _ := q.Get()
+			Expect(i).To(Equal(reconcile.Request{
+				NamespacedName: types.NamespacedName{Name: "foo-parent"}}))
+		})
+
+		It("should enqueue nothing for an object without the annotation", func() {
+			pod.SetAnnotations(map[string]string{"example.com/other": "foo-parent"})
+			instance.Create(event.CreateEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(0))
+		})
+	})
+
+	Describe("ThrottleUpdates", func() {
+		It("should add the Requests enqueued on update events at most once every interval", func() {
+			q := workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			defer q.ShutDown()
+			instance := handler.ThrottleUpdates(&handler.EnqueueRequestForObject{}, 200*time.Millisecond)
+			req := reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "biz", Name: "baz"}}
+
+			By("adding the Request of the first update right away")
+			instance.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+			i, _ := q.Get()
+			Expect(i).To(Equal(req))
+			q.Done(i)
+
+			By("adding the Requests of the next updates once the interval has passed")
+			instance.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: pod}, q)
+			instance.Update(event.UpdateEvent{ObjectOld: pod, ObjectNew: pod}, q)
+			Expect(q.Len()).To(Equal(0))
+			Eventually(q.Len).Should(Equal(1))
+			i, _ = q.Get()
+			Expect(i).To(Equal(req))
+			q.Done(i)
+			Consistently(q.Len, 300*time.Millisecond).Should(Equal(0))
+
+			By("not throttling the other events")
+			instance.Create(event.CreateEvent{Object: pod}, q)
+			Expect(q.Len()).To(Equal(1))
+		})
+
+		It("should inject the fields into the wrapped handler", func() {
+			owner := &handler.EnqueueRequestForOwner{OwnerType: &appsv1.ReplicaSet{}}
+			instance := handler.ThrottleUpdates(owner, time.Second)
+			injected := false
+			Expect(inject.InjectorInto(func(i interface{}) error {
+				Expect(i).To(BeIdenticalTo(owner))
+				injected = true
+				return nil
+			}, instance)).To(BeTrue())
+			Expect(injected).To(BeTrue())
+		})
+	})
+
 	Describe("Funcs", func() {
 		failingFuncs := handler.Funcs{
 			CreateFunc: func(event.CreateEvent, workqueue.RateLimitingInterface) {
@@ -928,3 +1212,21 @@ var _ = Describe("Eventhandler", func() {
 		})
 	})
 })
+
+// indexedCache lists Deployments as if they matched any field selector.
+type indexedCache struct {
+	*informertest.FakeInformers
+	items []appsv1.Deployment
+	err   error
+	opts  *client.ListOptions
+}
+
+func (c *indexedCache) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	c.opts = &client.ListOptions{}
+	c.opts.ApplyOptions(opts)
+	if c.err != nil {
+		return c.err
+	}
+	list.(*appsv1.DeploymentList).Items = c.items
+	return nil
+}
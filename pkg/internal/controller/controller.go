@@ -20,12 +20,19 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"runtime"
+	"runtime/debug"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/types"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/apimachinery/pkg/util/wait"
 	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	ctrlmetrics "sigs.k8s.io/controller-runtime/pkg/internal/controller/metrics"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
@@ -55,6 +62,10 @@ type Controller struct {
 	// leads to goroutine leaks if something calls controller.New repeatedly.
 	MakeQueue func() workqueue.RateLimitingInterface
 
+	// DelayRecorder, if set, wraps the rate limiter of the queue made by MakeQueue,
+	// so that the queue stats count the requests retried with backoff.
+	DelayRecorder *DelayRecorder
+
 	// Queue is an listeningQueue that listens for events from Informers and adds object keys to
 	// the Queue for processing
 	Queue workqueue.RateLimitingInterface
@@ -69,6 +80,24 @@ type Controller struct {
 	// Started is true if the Controller has been Started
 	Started bool
 
+	// sourcesStarted is true once the event sources of the Controller have been
+	// started, by Start or Warmup.
+	sourcesStarted bool
+
+	// WarmupBeforeLeaderElection makes Warmup start the event sources of the Controller.
+	WarmupBeforeLeaderElection bool
+
+	// Priority, if set, returns the priority of the requests added by the event handlers
+	// from the object of the event, if the queue implements Prioritizer.
+	Priority func(obj client.Object) int
+
+	// prioritizer is the queue of the Controller, if it implements Prioritizer.
+	prioritizer Prioritizer
+
+	// SkipNameValidation makes UniqueName return an empty name, so that the Controller
+	// can be added to a manager with another controller of the same name.
+	SkipNameValidation bool
+
 	// ctx is the context that was passed to Start() and used when starting watches.
 	//
 	// According to the docs, contexts should not be stored in a struct: https://golang.org/pkg/context,
@@ -80,11 +109,63 @@ type Controller struct {
 	// Defaults to 2 minutes if not set.
 	CacheSyncTimeout time.Duration
 
+	// ReconcileTimeout is the deadline of the context of every call to the Reconciler.
+	// Defaults to 0, which means no deadline.
+	ReconcileTimeout time.Duration
+
+	// RecoverPanic indicates whether the panics of the Reconciler are recovered
+	// and returned as errors.
+	RecoverPanic bool
+
+	// DebounceWindow delays the requests added by the event handlers, so that the
+	// events received for an object during the window result in a single reconcile.
+	DebounceWindow time.Duration
+
+	// RequeueAfterJitterFactor, if positive, adds a random duration of up to
+	// RequeueAfterJitterFactor*RequeueAfter to the RequeueAfter of the results.
+	RequeueAfterJitterFactor float64
+
+	// StartSpan, if set, starts a tracing span for every reconcile. The returned function
+	// ends the span with the result of the reconcile.
+	StartSpan func(ctx context.Context, req reconcile.Request) (context.Context, func(reconcile.Result, error))
+
+	// StuckReconcileThreshold, if positive, is the duration after which a running
+	// reconcile is reported as stuck.
+	StuckReconcileThreshold time.Duration
+
+	// ShardFilter, if set, drops the requests added by the event handlers for which it
+	// returns false, so that they are reconciled by other replicas.
+	ShardFilter func(req reconcile.Request) bool
+
+	// MaxRetries, if positive, is the number of times a failing request is retried
+	// before being dropped and passed to DeadLetter.
+	MaxRetries int
+
+	// DeadLetter is called with the requests dropped after MaxRetries retries, and
+	// the error of their last reconcile.
+	DeadLetter func(ctx context.Context, req reconcile.Request, err error)
+
+	// SyncPeriod, if positive, is the ResyncPeriod of the Kind sources of the controller
+	// which don't set theirs.
+	SyncPeriod time.Duration
+
+	// inspectedQueue holds the *inspectedQueue wrapping Queue once the controller is
+	// started, it is read without holding mu.
+	inspectedQueue atomic.Value
+
 	// startWatches maintains a list of sources, handlers, and predicates to start when the controller is started.
 	startWatches []watchDescription
 
 	// Log is used to log messages to users during reconciliation, or for example when a watch is started.
 	Log logr.Logger
+
+	// LogConstructor is used to construct the logger of every reconcile from its request.
+	// Defaults to Log with the name and namespace of the request.
+	LogConstructor func(request *reconcile.Request) logr.Logger
+
+	// LeaderElected indicates whether the controller is leader elected or always running.
+	// Defaults to true.
+	LeaderElected *bool
 }
 
 // watchDescription contains all the information necessary to start a watch.
@@ -96,8 +177,100 @@ type watchDescription struct {
 
 // Reconcile implements reconcile.Reconciler.
 func (c *Controller) Reconcile(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
-	log := c.Log.WithValues("name", req.Name, "namespace", req.Namespace)
-	ctx = logf.IntoContext(ctx, log)
+	ctx, _ = c.reconcileContext(ctx, req)
+	return c.reconcile(ctx, req)
+}
+
+// reconcileContext returns the context of a reconcile of req, with a new reconcileID
+// and the logger of the reconcile.
+func (c *Controller) reconcileContext(ctx context.Context, req reconcile.Request) (context.Context, logr.Logger) {
+	var log logr.Logger
+	if c.LogConstructor != nil {
+		log = c.LogConstructor(&req)
+	} else {
+		log = c.Log.WithValues("name", req.Name, "namespace", req.Namespace)
+	}
+	reconcileID := uuid.NewUUID()
+	log = log.WithValues("reconcileID", reconcileID)
+	ctx = context.WithValue(ctx, reconcileIDKey{}, reconcileID)
+	return logf.IntoContext(ctx, log), log
+}
+
+// watchReconcile reports the reconcile of req as stuck if it runs longer than
+// StuckReconcileThreshold, until the returned function is called.
+func (c *Controller) watchReconcile(ctx context.Context, req reconcile.Request) func() {
+	var stuck bool
+	var mu sync.Mutex
+	timer := time.AfterFunc(c.StuckReconcileThreshold, func() {
+		mu.Lock()
+		defer mu.Unlock()
+		stuck = true
+		ctrlmetrics.StuckReconciles.WithLabelValues(c.Name).Inc()
+		logf.FromContext(ctx).Info("Reconcile is running longer than the stuck reconcile threshold",
+			"threshold", c.StuckReconcileThreshold, "goroutines", string(goroutineDump()))
+	})
+	return func() {
+		timer.Stop()
+		mu.Lock()
+		defer mu.Unlock()
+		if stuck {
+			ctrlmetrics.StuckReconciles.WithLabelValues(c.Name).Dec()
+		}
+	}
+}
+
+// goroutineDump returns the stacks of all the goroutines.
+func goroutineDump() []byte {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			return buf[:n]
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+}
+
+// requeueAfter returns the delay of a request requeued after d, with its jitter.
+func (c *Controller) requeueAfter(d time.Duration) time.Duration {
+	if c.RequeueAfterJitterFactor <= 0 {
+		return d
+	}
+	return wait.Jitter(d, c.RequeueAfterJitterFactor)
+}
+
+// reconcileIDKey is the key of the reconcileID in the context of a reconcile.
+type reconcileIDKey struct{}
+
+// ReconcileIDFromContext returns the reconcileID of the reconcile of ctx, or an
+// empty UID if ctx isn't the context of a reconcile.
+func ReconcileIDFromContext(ctx context.Context) types.UID {
+	reconcileID, _ := ctx.Value(reconcileIDKey{}).(types.UID)
+	return reconcileID
+}
+
+// reconcile calls the Reconciler in the span started by StartSpan, and turns its panics
+// into errors if RecoverPanic is set.
+func (c *Controller) reconcile(ctx context.Context, req reconcile.Request) (result reconcile.Result, err error) {
+	if c.StartSpan != nil {
+		var endSpan func(reconcile.Result, error)
+		ctx, endSpan = c.StartSpan(ctx, req)
+		defer func() {
+			endSpan(result, err)
+		}()
+	}
+	if c.StuckReconcileThreshold > 0 {
+		defer c.watchReconcile(ctx, req)()
+	}
+	if c.RecoverPanic {
+		defer func() {
+			if r := recover(); r != nil {
+				ctrlmetrics.ReconcilePanics.WithLabelValues(c.Name).Inc()
+				err = fmt.Errorf("panic: %v [recovered]", r)
+				logf.FromContext(ctx).Error(err, "Observed a panic in reconciler", "stack", string(debug.Stack()))
+			}
+		}()
+	}
 	return c.Do.Reconcile(ctx, req)
 }
 
@@ -110,6 +283,12 @@ func (c *Controller) Watch(src source.Source, evthdler handler.EventHandler, prc
 	if err := c.SetFields(src); err != nil {
 		return err
 	}
+	if kind, ok := src.(*source.Kind); ok && kind.ResyncPeriod == 0 && c.SyncPeriod > 0 {
+		// resync a copy, the Kind of the caller may be watched by other controllers too
+		withResync := *kind
+		withResync.ResyncPeriod = c.SyncPeriod
+		src = &withResync
+	}
 	if err := c.SetFields(evthdler); err != nil {
 		return err
 	}
@@ -122,13 +301,78 @@ func (c *Controller) Watch(src source.Source, evthdler handler.EventHandler, prc
 	// Controller hasn't started yet, store the watches locally and return.
 	//
 	// These watches are going to be held on the controller struct until the manager or user calls Start(...).
-	if !c.Started {
+	if !c.sourcesStarted {
 		c.startWatches = append(c.startWatches, watchDescription{src: src, handler: evthdler, predicates: prct})
 		return nil
 	}
 
 	c.Log.Info("Starting EventSource", "source", src)
-	return src.Start(c.ctx, evthdler, c.Queue, prct...)
+	return src.Start(c.ctx, c.eventHandler(evthdler), c.eventQueue(), prct...)
+}
+
+// eventHandler returns the handler adding the requests of evthdler to the queue.
+func (c *Controller) eventHandler(evthdler handler.EventHandler) handler.EventHandler {
+	if c.Priority == nil || c.prioritizer == nil {
+		return evthdler
+	}
+	return &prioritizingHandler{EventHandler: evthdler, controller: c, prioritizer: c.prioritizer}
+}
+
+// eventQueue returns the queue the event handlers add requests to.
+func (c *Controller) eventQueue() workqueue.RateLimitingInterface {
+	queue := c.Queue
+	if c.DebounceWindow > 0 {
+		queue = &debouncingQueue{RateLimitingInterface: queue, window: c.DebounceWindow}
+	}
+	if c.ShardFilter != nil {
+		queue = &shardQueue{RateLimitingInterface: queue, filter: c.ShardFilter}
+	}
+	return queue
+}
+
+// debouncingQueue delays the items added to it by window. The delaying queue keeps
+// a single entry per waiting item, so the items added again during the window are
+// coalesced.
+type debouncingQueue struct {
+	workqueue.RateLimitingInterface
+	window time.Duration
+}
+
+// Add implements workqueue.Interface.
+func (q *debouncingQueue) Add(item interface{}) {
+	q.AddAfter(item, q.window)
+}
+
+// shardQueue drops the requests which don't belong to the shard of the controller.
+type shardQueue struct {
+	workqueue.RateLimitingInterface
+	filter func(req reconcile.Request) bool
+}
+
+func (q *shardQueue) inShard(item interface{}) bool {
+	req, ok := item.(reconcile.Request)
+	return !ok || q.filter(req)
+}
+
+// Add implements workqueue.Interface.
+func (q *shardQueue) Add(item interface{}) {
+	if q.inShard(item) {
+		q.RateLimitingInterface.Add(item)
+	}
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (q *shardQueue) AddAfter(item interface{}, duration time.Duration) {
+	if q.inShard(item) {
+		q.RateLimitingInterface.AddAfter(item, duration)
+	}
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (q *shardQueue) AddRateLimited(item interface{}) {
+	if q.inShard(item) {
+		q.RateLimitingInterface.AddRateLimited(item)
+	}
 }
 
 // Start implements controller.Controller.
@@ -137,20 +381,12 @@ func (c *Controller) Start(ctx context.Context) error {
 	// but lock outside to get proper handling of the queue shutdown
 	c.mu.Lock()
 	if c.Started {
+		c.mu.Unlock()
 		return errors.New("controller was started more than once. This is likely to be caused by being added to a manager multiple times")
 	}
 
 	c.initMetrics()
 
-	// Set the internal context.
-	c.ctx = ctx
-
-	c.Queue = c.MakeQueue()
-	go func() {
-		<-ctx.Done()
-		c.Queue.ShutDown()
-	}()
-
 	wg := &sync.WaitGroup{}
 	err := func() error {
 		defer c.mu.Unlock()
@@ -160,14 +396,16 @@ func (c *Controller) Start(ctx context.Context) error {
 
 		// NB(directxman12): launch the sources *before* trying to wait for the
 		// caches to sync so that they have a chance to register their intendeded
-		// caches.
-		for _, watch := range c.startWatches {
-			c.Log.Info("Starting EventSource", "source", watch.src)
-
-			if err := watch.src.Start(ctx, watch.handler, c.Queue, watch.predicates...); err != nil {
+		// caches. They may have been launched by Warmup already.
+		if !c.sourcesStarted {
+			if err := c.startEventSources(ctx); err != nil {
 				return err
 			}
 		}
+		go func() {
+			<-ctx.Done()
+			c.Queue.ShutDown()
+		}()
 
 		// Start the SharedIndexInformer factories to begin populating the SharedIndexInformer caches
 		c.Log.Info("Starting Controller")
@@ -230,6 +468,59 @@ func (c *Controller) Start(ctx context.Context) error {
 	return nil
 }
 
+// UniqueName implements manager.UniquelyNamedRunnable.
+func (c *Controller) UniqueName() string {
+	if c.SkipNameValidation {
+		return ""
+	}
+	return c.Name
+}
+
+// Warmup implements manager.WarmupRunnable. It starts the event sources of the
+// controller if WarmupBeforeLeaderElection is set, so that their caches are synced
+// and their events queued when the controller is started.
+func (c *Controller) Warmup(ctx context.Context) error {
+	if !c.WarmupBeforeLeaderElection {
+		return nil
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.Started || c.sourcesStarted {
+		return nil
+	}
+	c.Log.Info("Warming up Controller")
+	return c.startEventSources(ctx)
+}
+
+// startEventSources creates the queue of the controller, and starts its event
+// sources. It must be called with mu held.
+func (c *Controller) startEventSources(ctx context.Context) error {
+	// Set the internal context.
+	c.ctx = ctx
+
+	madeQueue := c.MakeQueue()
+	c.prioritizer, _ = madeQueue.(Prioritizer)
+	queue := newInspectedQueue(madeQueue, c.DelayRecorder)
+	c.inspectedQueue.Store(queue)
+	c.Queue = queue
+	go func() {
+		<-ctx.Done()
+		c.Queue.ShutDown()
+	}()
+
+	eventQueue := c.eventQueue()
+	for _, watch := range c.startWatches {
+		c.Log.Info("Starting EventSource", "source", watch.src)
+
+		if err := watch.src.Start(ctx, c.eventHandler(watch.handler), eventQueue, watch.predicates...); err != nil {
+			return err
+		}
+	}
+	c.sourcesStarted = true
+	return nil
+}
+
 // processNextWorkItem will read a single work item off the workqueue and
 // attempt to process it, by calling the reconcileHandler.
 func (c *Controller) processNextWorkItem(ctx context.Context) bool {
@@ -264,11 +555,16 @@ const (
 func (c *Controller) initMetrics() {
 	ctrlmetrics.ActiveWorkers.WithLabelValues(c.Name).Set(0)
 	ctrlmetrics.ReconcileErrors.WithLabelValues(c.Name).Add(0)
+	ctrlmetrics.TerminalReconcileErrors.WithLabelValues(c.Name).Add(0)
+	ctrlmetrics.DeadLetters.WithLabelValues(c.Name).Add(0)
+	ctrlmetrics.ReconcileTimeouts.WithLabelValues(c.Name).Add(0)
+	ctrlmetrics.ReconcilePanics.WithLabelValues(c.Name).Add(0)
 	ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelError).Add(0)
 	ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelRequeueAfter).Add(0)
 	ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelRequeue).Add(0)
 	ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelSuccess).Add(0)
 	ctrlmetrics.WorkerCount.WithLabelValues(c.Name).Set(float64(c.MaxConcurrentReconciles))
+	ctrlmetrics.StuckReconciles.WithLabelValues(c.Name).Set(0)
 }
 
 func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
@@ -290,15 +586,39 @@ func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
 		return
 	}
 
-	log := c.Log.WithValues("name", req.Name, "namespace", req.Namespace)
-	ctx = logf.IntoContext(ctx, log)
+	ctx, log := c.reconcileContext(ctx, req)
+
+	reconcileCtx := ctx
+	if c.ReconcileTimeout > 0 {
+		var cancel context.CancelFunc
+		reconcileCtx, cancel = context.WithTimeout(ctx, c.ReconcileTimeout)
+		defer cancel()
+	}
 
 	// RunInformersAndControllers the syncHandler, passing it the Namespace/Name string of the
 	// resource to be synced.
-	result, err := c.Do.Reconcile(ctx, req)
+	result, err := c.reconcile(reconcileCtx, req)
+	if c.ReconcileTimeout > 0 && ctx.Err() == nil && errors.Is(reconcileCtx.Err(), context.DeadlineExceeded) {
+		ctrlmetrics.ReconcileTimeouts.WithLabelValues(c.Name).Inc()
+		log.Info("Reconcile exceeded its timeout", "timeout", c.ReconcileTimeout)
+	}
 	switch {
 	case err != nil:
-		c.Queue.AddRateLimited(req)
+		switch {
+		case errors.Is(err, reconcile.TerminalError(nil)):
+			// Terminal errors aren't retried, forget the failures of the request.
+			c.Queue.Forget(obj)
+			ctrlmetrics.TerminalReconcileErrors.WithLabelValues(c.Name).Inc()
+		case c.MaxRetries > 0 && c.Queue.NumRequeues(req) >= c.MaxRetries:
+			c.Queue.Forget(obj)
+			ctrlmetrics.DeadLetters.WithLabelValues(c.Name).Inc()
+			log.Error(err, "Dropping request after exceeding the max retries", "maxRetries", c.MaxRetries)
+			if c.DeadLetter != nil {
+				c.DeadLetter(ctx, req, err)
+			}
+		default:
+			c.Queue.AddRateLimited(req)
+		}
 		ctrlmetrics.ReconcileErrors.WithLabelValues(c.Name).Inc()
 		ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelError).Inc()
 		log.Error(err, "Reconciler error")
@@ -308,7 +628,7 @@ func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
 		// We need to drive to stable reconcile loops before queuing due
 		// to result.RequestAfter
 		c.Queue.Forget(obj)
-		c.Queue.AddAfter(req, result.RequeueAfter)
+		c.Queue.AddAfter(req, c.requeueAfter(result.RequeueAfter))
 		ctrlmetrics.ReconcileTotal.WithLabelValues(c.Name, labelRequeueAfter).Inc()
 	case result.Requeue:
 		c.Queue.AddRateLimited(req)
@@ -321,6 +641,14 @@ func (c *Controller) reconcileHandler(ctx context.Context, obj interface{}) {
 	}
 }
 
+// NeedLeaderElection implements the manager.LeaderElectionRunnable interface.
+func (c *Controller) NeedLeaderElection() bool {
+	if c.LeaderElected == nil {
+		return true
+	}
+	return *c.LeaderElected
+}
+
 // GetLogger returns this controller's logger.
 func (c *Controller) GetLogger() logr.Logger {
 	return c.Log
@@ -23,6 +23,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/prometheus/client_golang/prometheus"
@@ -88,6 +89,325 @@ var _ = Describe("controller", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(result).To(Equal(reconcile.Result{Requeue: true}))
 		})
+
+		It("should construct a logger and a reconcileID for every reconcile", func() {
+			var loggedRequests []reconcile.Request
+			ctrl.LogConstructor = func(req *reconcile.Request) logr.Logger {
+				loggedRequests = append(loggedRequests, *req)
+				return ctrl.Log
+			}
+			var reconcileIDs []types.UID
+			ctrl.Do = reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+				reconcileIDs = append(reconcileIDs, ReconcileIDFromContext(ctx))
+				return reconcile.Result{}, nil
+			})
+
+			for i := 0; i < 2; i++ {
+				_, err := ctrl.Reconcile(context.Background(), request)
+				Expect(err).NotTo(HaveOccurred())
+			}
+			Expect(loggedRequests).To(Equal([]reconcile.Request{request, request}))
+			Expect(reconcileIDs).To(HaveLen(2))
+			Expect(reconcileIDs[0]).NotTo(BeEmpty())
+			Expect(reconcileIDs[1]).NotTo(BeEmpty())
+			Expect(reconcileIDs[0]).NotTo(Equal(reconcileIDs[1]))
+			Expect(ReconcileIDFromContext(context.Background())).To(BeEmpty())
+		})
+	})
+
+	Describe("QueueStats", func() {
+		It("should be empty until the controller is started", func() {
+			Expect(ctrl.QueueStats()).To(Equal(QueueStats{}))
+			Expect(ctrl.NumRequeues(request)).To(Equal(0))
+		})
+
+		It("should return the length of the queue and the age of its oldest due request", func() {
+			now := time.Now()
+			q := newInspectedQueue(workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()), nil)
+			defer q.ShutDown()
+			q.now = func() time.Time { return now }
+			ctrl.inspectedQueue.Store(q)
+
+			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "first"}})
+			q.AddAfter(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "later"}}, time.Hour)
+			now = now.Add(10 * time.Second)
+			q.Add(reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "second"}})
+			now = now.Add(5 * time.Second)
+			Expect(ctrl.QueueStats()).To(Equal(QueueStats{Length: 2, OldestItemAge: 15 * time.Second}))
+
+			By("forgetting the requests taken from the queue")
+			item, _ := q.Get()
+			Expect(item.(reconcile.Request).Name).To(Equal("first"))
+			Expect(ctrl.QueueStats()).To(Equal(QueueStats{Length: 1, OldestItemAge: 5 * time.Second}))
+
+			By("counting the retries of the requests")
+			q.AddRateLimited(item)
+			Expect(ctrl.NumRequeues(item.(reconcile.Request))).To(Equal(1))
+		})
+
+		It("should count the requests retried with backoff once they are due", func() {
+			now := time.Now()
+			delays := NewDelayRecorder(workqueue.NewItemExponentialFailureRateLimiter(time.Minute, time.Hour))
+			q := newInspectedQueue(workqueue.NewRateLimitingQueue(delays), delays)
+			defer q.ShutDown()
+			q.now = func() time.Time { return now }
+			ctrl.inspectedQueue.Store(q)
+
+			q.AddRateLimited(request)
+			Expect(ctrl.QueueStats().OldestItemAge).To(BeZero())
+			now = now.Add(90 * time.Second)
+			Expect(ctrl.QueueStats().OldestItemAge).To(Equal(30 * time.Second))
+			Expect(ctrl.NumRequeues(request)).To(Equal(1))
+		})
+
+		It("should keep the times the requests added with a delay are due once they are taken", func() {
+			now := time.Now()
+			q := newInspectedQueue(workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()), nil)
+			defer q.ShutDown()
+			q.now = func() time.Time { return now }
+			ctrl.inspectedQueue.Store(q)
+
+			q.AddAfter(request, time.Minute)
+			q.Add(request)
+			item, _ := q.Get()
+			q.Done(item)
+			Expect(ctrl.QueueStats().OldestItemAge).To(BeZero())
+
+			now = now.Add(2 * time.Minute)
+			Expect(ctrl.QueueStats().OldestItemAge).To(Equal(time.Minute))
+		})
+
+		It("should keep the earliest time of the requests added with a delay several times", func() {
+			now := time.Now()
+			q := newInspectedQueue(workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter()), nil)
+			defer q.ShutDown()
+			q.now = func() time.Time { return now }
+			ctrl.inspectedQueue.Store(q)
+
+			By("debouncing two events into a single add")
+			q.AddAfter(request, time.Minute)
+			now = now.Add(10 * time.Second)
+			q.AddAfter(request, time.Minute)
+			now = now.Add(50 * time.Second)
+			// the delaying queue adds the request once, at its earliest time
+			q.RateLimitingInterface.Add(request)
+			item, _ := q.Get()
+			q.Done(item)
+			Expect(ctrl.QueueStats()).To(Equal(QueueStats{}))
+			now = now.Add(time.Hour)
+			Expect(ctrl.QueueStats()).To(Equal(QueueStats{}))
+
+			By("coalescing a RequeueAfter and a later retry with backoff")
+			delays := NewDelayRecorder(workqueue.NewItemExponentialFailureRateLimiter(2*time.Minute, time.Hour))
+			q = newInspectedQueue(workqueue.NewRateLimitingQueue(delays), delays)
+			defer q.ShutDown()
+			q.now = func() time.Time { return now }
+			ctrl.inspectedQueue.Store(q)
+			q.AddAfter(request, time.Minute)
+			q.AddRateLimited(request)
+			now = now.Add(90 * time.Second)
+			Expect(ctrl.QueueStats().OldestItemAge).To(Equal(30 * time.Second))
+			now = now.Add(time.Hour)
+			q.RateLimitingInterface.Add(request)
+			item, _ = q.Get()
+			q.Done(item)
+			Expect(ctrl.QueueStats()).To(Equal(QueueStats{}))
+		})
+	})
+
+	Describe("Priority", func() {
+		var q workqueue.RateLimitingInterface
+		requestFor := func(name string) reconcile.Request {
+			return reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: name}}
+		}
+
+		BeforeEach(func() {
+			q = NewPriorityQueue(workqueue.DefaultControllerRateLimiter(), "")
+		})
+
+		AfterEach(func() {
+			q.ShutDown()
+		})
+
+		It("should hand out the items with the highest priority first, then in order", func() {
+			p := q.(Prioritizer)
+			q.Add(requestFor("low"))
+			p.SetPriority(requestFor("high"), 10)
+			q.Add(requestFor("high"))
+			q.Add(requestFor("low-2"))
+			p.SetPriority(requestFor("medium"), 5)
+			q.Add(requestFor("medium"))
+			Expect(q.Len()).To(Equal(4))
+
+			for _, name := range []string{"high", "medium", "low", "low-2"} {
+				item, shutdown := q.Get()
+				Expect(shutdown).To(BeFalse())
+				Expect(item).To(Equal(requestFor(name)))
+				q.Done(item)
+			}
+			Expect(q.Len()).To(Equal(0))
+		})
+
+		It("should hold an item once, with the highest priority it was added with", func() {
+			p := q.(Prioritizer)
+			q.Add(requestFor("first"))
+			q.Add(requestFor("second"))
+			p.SetPriority(requestFor("second"), 1)
+			q.Add(requestFor("second"))
+			q.Add(requestFor("second"))
+			Expect(q.Len()).To(Equal(2))
+
+			item, _ := q.Get()
+			Expect(item).To(Equal(requestFor("second")))
+			q.Done(item)
+			item, _ = q.Get()
+			Expect(item).To(Equal(requestFor("first")))
+			q.Done(item)
+			Expect(q.Len()).To(Equal(0))
+		})
+
+		It("should hand out an item added while it is processed once it is done", func() {
+			q.Add(requestFor("item"))
+			item, _ := q.Get()
+			q.Add(requestFor("item"))
+			Expect(q.Len()).To(Equal(0))
+
+			q.Done(item)
+			Expect(q.Len()).To(Equal(1))
+			item, _ = q.Get()
+			Expect(item).To(Equal(requestFor("item")))
+		})
+
+		It("should unblock Get once the queue is shut down", func() {
+			done := make(chan struct{})
+			go func() {
+				defer GinkgoRecover()
+				defer close(done)
+				_, shutdown := q.Get()
+				Expect(shutdown).To(BeTrue())
+			}()
+			q.ShutDown()
+			Eventually(done).Should(BeClosed())
+		})
+
+		It("should set the priority of the requests enqueued by the event handlers from their object", func() {
+			ctrl.Priority = func(obj client.Object) int {
+				if obj.GetLabels()["urgent"] == "true" {
+					return 1
+				}
+				return 0
+			}
+			ctrl.prioritizer = q.(Prioritizer)
+			h := ctrl.eventHandler(&handler.EnqueueRequestForObject{})
+
+			h.Create(event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "normal"}}}, q)
+			h.Create(event.CreateEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{
+				Namespace: "default", Name: "urgent", Labels: map[string]string{"urgent": "true"},
+			}}}, q)
+
+			item, _ := q.Get()
+			Expect(item).To(Equal(requestFor("urgent")))
+			item, _ = q.Get()
+			Expect(item).To(Equal(requestFor("normal")))
+		})
+
+		It("should not wrap the event handlers if the queue doesn't implement Prioritizer", func() {
+			ctrl.Priority = func(client.Object) int { return 1 }
+			h := &handler.EnqueueRequestForObject{}
+			Expect(ctrl.eventHandler(h)).To(BeIdenticalTo(h))
+		})
+	})
+
+	Describe("StuckReconcileThreshold", func() {
+		It("should report the reconciles running longer than the threshold until they return", func() {
+			var stuckReconciles dto.Metric
+			ctrlmetrics.StuckReconciles.Reset()
+			ctrl.StuckReconcileThreshold = 10 * time.Millisecond
+			release := make(chan struct{})
+			ctrl.Do = reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				<-release
+				return reconcile.Result{}, nil
+			})
+			stuck := func() float64 {
+				Expect(ctrlmetrics.StuckReconciles.WithLabelValues(ctrl.Name).Write(&stuckReconciles)).To(Succeed())
+				return stuckReconciles.GetGauge().GetValue()
+			}
+
+			reconcileDone := make(chan error)
+			go func() {
+				_, err := ctrl.Reconcile(context.Background(), request)
+				reconcileDone <- err
+			}()
+			Eventually(stuck).Should(Equal(1.0))
+
+			close(release)
+			Eventually(reconcileDone).Should(Receive(BeNil()))
+			Expect(stuck()).To(Equal(0.0))
+		})
+	})
+
+	Describe("StartSpan", func() {
+		It("should reconcile in the started span and end it with the result", func() {
+			type spanKey struct{}
+			var endedResult reconcile.Result
+			var endedErr error
+			ctrl.StartSpan = func(ctx context.Context, req reconcile.Request) (context.Context, func(reconcile.Result, error)) {
+				Expect(req).To(Equal(request))
+				return context.WithValue(ctx, spanKey{}, "span"), func(result reconcile.Result, err error) {
+					endedResult, endedErr = result, err
+				}
+			}
+			ctrl.Do = reconcile.Func(func(ctx context.Context, _ reconcile.Request) (reconcile.Result, error) {
+				Expect(ctx.Value(spanKey{})).To(Equal("span"))
+				return reconcile.Result{Requeue: true}, fmt.Errorf("expected error")
+			})
+
+			_, err := ctrl.Reconcile(context.Background(), request)
+			Expect(err).To(MatchError("expected error"))
+			Expect(endedResult).To(Equal(reconcile.Result{Requeue: true}))
+			Expect(endedErr).To(MatchError("expected error"))
+		})
+
+		It("should end the span with the recovered panics", func() {
+			var endedErr error
+			ctrl.RecoverPanic = true
+			ctrl.StartSpan = func(ctx context.Context, _ reconcile.Request) (context.Context, func(reconcile.Result, error)) {
+				return ctx, func(_ reconcile.Result, err error) {
+					endedErr = err
+				}
+			}
+			ctrl.Do = reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				panic("expected panic")
+			})
+
+			_, err := ctrl.Reconcile(context.Background(), request)
+			Expect(err).To(HaveOccurred())
+			Expect(endedErr).To(MatchError(ContainSubstring("expected panic")))
+		})
+	})
+
+	Describe("RecoverPanic", func() {
+		It("should turn panics of the Reconciler into errors", func() {
+			var reconcilePanics dto.Metric
+			ctrlmetrics.ReconcilePanics.Reset()
+			ctrl.RecoverPanic = true
+			ctrl.Do = reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				panic("expected panic")
+			})
+
+			_, err := ctrl.Reconcile(context.Background(), request)
+			Expect(err).To(MatchError(ContainSubstring("expected panic")))
+			Expect(ctrlmetrics.ReconcilePanics.WithLabelValues(ctrl.Name).Write(&reconcilePanics)).To(Succeed())
+			Expect(reconcilePanics.GetCounter().GetValue()).To(Equal(1.0))
+		})
+
+		It("should not recover panics by default", func() {
+			ctrl.Do = reconcile.Func(func(context.Context, reconcile.Request) (reconcile.Result, error) {
+				panic("expected panic")
+			})
+
+			Expect(func() { _, _ = ctrl.Reconcile(context.Background(), request) }).To(PanicWith("expected panic"))
+		})
 	})
 
 	Describe("Start", func() {
@@ -197,6 +517,100 @@ var _ = Describe("controller", func() {
 			close(done)
 		})
 
+		It("should coalesce the events received during the debounce window", func() {
+			ctrl.DebounceWindow = 200 * time.Millisecond
+			ctrl.Do = reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				reconciled <- req
+				return reconcile.Result{}, nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ch := make(chan event.GenericEvent, 3)
+			ins := &source.Channel{Source: ch}
+			Expect(inject.StopChannelInto(ctx.Done(), ins)).To(BeTrue())
+			ctrl.startWatches = []watchDescription{{src: ins, handler: &handler.EnqueueRequestForObject{}}}
+
+			p := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"}}
+			for i := 0; i < 3; i++ {
+				ch <- event.GenericEvent{Object: p}
+			}
+
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+
+			Eventually(reconciled).Should(Receive(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "bar"}})))
+			Consistently(reconciled).ShouldNot(Receive())
+		})
+
+		It("should only reconcile the requests of its shard", func() {
+			ctrl.ShardFilter = func(req reconcile.Request) bool {
+				return req.Name == "in-shard"
+			}
+			ctrl.Do = reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				reconciled <- req
+				return reconcile.Result{}, nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ch := make(chan event.GenericEvent, 2)
+			ins := &source.Channel{Source: ch}
+			Expect(inject.StopChannelInto(ctx.Done(), ins)).To(BeTrue())
+			ctrl.startWatches = []watchDescription{{src: ins, handler: &handler.EnqueueRequestForObject{}}}
+
+			ch <- event.GenericEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "not-in-shard", Namespace: "bar"}}}
+			ch <- event.GenericEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "in-shard", Namespace: "bar"}}}
+
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+
+			Eventually(reconciled).Should(Receive(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Name: "in-shard", Namespace: "bar"}})))
+			Consistently(reconciled).ShouldNot(Receive())
+		})
+
+		It("should queue the events from Warmup, and reconcile them once started", func() {
+			ctrl.WarmupBeforeLeaderElection = true
+			ctrl.MakeQueue = func() workqueue.RateLimitingInterface {
+				return workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())
+			}
+			ctrl.Do = reconcile.Func(func(_ context.Context, req reconcile.Request) (reconcile.Result, error) {
+				reconciled <- req
+				return reconcile.Result{}, nil
+			})
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			ch := make(chan event.GenericEvent, 1)
+			ins := &source.Channel{Source: ch}
+			Expect(inject.StopChannelInto(ctx.Done(), ins)).To(BeTrue())
+			ctrl.startWatches = []watchDescription{{src: ins, handler: &handler.EnqueueRequestForObject{}}}
+			ch <- event.GenericEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"}}}
+
+			Expect(ctrl.Warmup(ctx)).To(Succeed())
+			Eventually(func() int { return ctrl.QueueStats().Length }).Should(Equal(1))
+			Consistently(reconciled).ShouldNot(Receive())
+
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).To(Succeed())
+			}()
+			Eventually(reconciled).Should(Receive(Equal(reconcile.Request{NamespacedName: types.NamespacedName{Name: "foo", Namespace: "bar"}})))
+		})
+
+		It("should not start its sources from Warmup unless WarmupBeforeLeaderElection is set", func() {
+			ctrl.startWatches = []watchDescription{{src: &source.Channel{}, handler: &handler.EnqueueRequestForObject{}}}
+			Expect(ctrl.Warmup(context.Background())).To(Succeed())
+			Expect(ctrl.sourcesStarted).To(BeFalse())
+		})
+
 		It("should error when channel is passed as a source but stop channel is not injected", func(done Done) {
 			ch := make(chan event.GenericEvent)
 			ctx, cancel := context.WithCancel(context.TODO())
@@ -280,6 +694,31 @@ var _ = Describe("controller", func() {
 			Expect(err.Error()).To(Equal("controller was started more than once. This is likely to be caused by being added to a manager multiple times"))
 		})
 
+		It("should not hold its lock after being started more than once", func() {
+			ctx, cancel := context.WithCancel(context.Background())
+			cancel()
+			Expect(ctrl.Start(ctx)).To(Succeed())
+			Expect(ctrl.Start(ctx)).NotTo(Succeed())
+
+			watchDone := make(chan error)
+			go func() {
+				watchDone <- ctrl.Watch(&source.Channel{Source: make(chan event.GenericEvent)}, &handler.EnqueueRequestForObject{})
+			}()
+			Eventually(watchDone).Should(Receive())
+		})
+
+	})
+
+	Describe("NeedLeaderElection", func() {
+		It("should need leader election by default", func() {
+			Expect(ctrl.NeedLeaderElection()).To(BeTrue())
+		})
+
+		It("should not need leader election if configured so", func() {
+			leaderElected := false
+			ctrl.LeaderElected = &leaderElected
+			Expect(ctrl.NeedLeaderElection()).To(BeFalse())
+		})
 	})
 
 	Describe("Watch", func() {
@@ -299,6 +738,21 @@ var _ = Describe("controller", func() {
 			Expect(found).To(BeTrue(), "Source not injected")
 		})
 
+		It("should set its SyncPeriod as the ResyncPeriod of the Kind sources without one", func() {
+			ctrl.SyncPeriod = 10 * time.Minute
+			ctrl.SetFields = func(interface{}) error { return nil }
+			src := &source.Kind{Type: &corev1.Pod{}}
+			Expect(ctrl.Watch(src, &handler.EnqueueRequestForObject{})).To(Succeed())
+			Expect(ctrl.startWatches[0].src.(*source.Kind).ResyncPeriod).To(Equal(10 * time.Minute))
+			By("leaving the Kind of the caller unchanged")
+			Expect(src.ResyncPeriod).To(BeZero())
+
+			srcWithResyncPeriod := &source.Kind{Type: &corev1.Pod{}, ResyncPeriod: time.Minute}
+			Expect(ctrl.Watch(srcWithResyncPeriod, &handler.EnqueueRequestForObject{})).To(Succeed())
+			Expect(ctrl.startWatches[1].src).To(BeIdenticalTo(srcWithResyncPeriod))
+			Expect(srcWithResyncPeriod.ResyncPeriod).To(Equal(time.Minute))
+		})
+
 		It("should return an error if there is an error injecting into the Source", func() {
 			src := &source.Kind{Type: &corev1.Pod{}}
 			Expect(src.InjectCache(informers)).To(Succeed())
@@ -515,6 +969,43 @@ var _ = Describe("controller", func() {
 			Eventually(func() int { return dq.NumRequeues(request) }).Should(Equal(0))
 		})
 
+		It("should drop a Request and pass it to DeadLetter after MaxRetries retries", func() {
+			// Use a queue counting the requeues of the requests.
+			dq := &DelegatingQueue{RateLimitingInterface: workqueue.NewRateLimitingQueue(workqueue.DefaultControllerRateLimiter())}
+			ctrl.MakeQueue = func() workqueue.RateLimitingInterface { return dq }
+			ctrl.MaxRetries = 1
+			deadLetters := make(chan error, 1)
+			ctrl.DeadLetter = func(_ context.Context, req reconcile.Request, err error) {
+				defer GinkgoRecover()
+				Expect(req).To(Equal(request))
+				deadLetters <- err
+			}
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+			}()
+
+			dq.Add(request)
+
+			By("Invoking Reconciler which returns an error")
+			fakeReconcile.AddResult(reconcile.Result{}, fmt.Errorf("something's wrong"))
+			Expect(<-reconciled).To(Equal(request))
+			Eventually(dq.getCounts).Should(Equal(countInfo{Trying: 1, AddRateLimited: 1}))
+
+			By("Invoking Reconciler a second time with an error")
+			fakeReconcile.AddResult(reconcile.Result{}, fmt.Errorf("still wrong"))
+			Expect(<-reconciled).To(Equal(request))
+			Eventually(deadLetters).Should(Receive(MatchError("still wrong")))
+
+			By("Removing the item from the queue without requeueing it")
+			Expect(dq.getCounts()).To(Equal(countInfo{Trying: 0, AddRateLimited: 1}))
+			Eventually(dq.Len).Should(Equal(0))
+			Eventually(func() int { return dq.NumRequeues(request) }).Should(Equal(0))
+		})
+
 		It("should requeue a Request with rate limiting if the Result sets Requeue:true and continue processing items", func() {
 			dq := &DelegatingQueue{RateLimitingInterface: ctrl.MakeQueue()}
 			ctrl.MakeQueue = func() workqueue.RateLimitingInterface { return dq }
@@ -575,6 +1066,20 @@ var _ = Describe("controller", func() {
 			Eventually(func() int { return dq.NumRequeues(request) }).Should(Equal(0))
 		})
 
+		It("should add a jitter to RequeueAfter if RequeueAfterJitterFactor is set", func() {
+			Expect(ctrl.requeueAfter(10 * time.Second)).To(Equal(10 * time.Second))
+
+			ctrl.RequeueAfterJitterFactor = 0.5
+			delays := map[time.Duration]struct{}{}
+			for i := 0; i < 100; i++ {
+				d := ctrl.requeueAfter(10 * time.Second)
+				Expect(d).To(BeNumerically(">=", 10*time.Second))
+				Expect(d).To(BeNumerically("<", 15*time.Second))
+				delays[d] = struct{}{}
+			}
+			Expect(len(delays)).To(BeNumerically(">", 1))
+		})
+
 		It("should perform error behavior if error is not nil, regardless of RequeueAfter", func() {
 			dq := &DelegatingQueue{RateLimitingInterface: ctrl.MakeQueue()}
 			ctrl.MakeQueue = func() workqueue.RateLimitingInterface { return dq }
@@ -792,6 +1297,62 @@ var _ = Describe("controller", func() {
 				close(done)
 			}, 2.0)
 
+			It("should cancel the context of reconciles exceeding the reconcile timeout", func(done Done) {
+				var reconcileTimeouts dto.Metric
+				ctrlmetrics.ReconcileTimeouts.Reset()
+				ctrl.ReconcileTimeout = 10 * time.Millisecond
+				ctrl.Do = reconcile.Func(func(ctx context.Context, req reconcile.Request) (reconcile.Result, error) {
+					<-ctx.Done()
+					reconciled <- req
+					return reconcile.Result{}, nil
+				})
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+				}()
+				queue.Add(request)
+
+				By("Invoking Reconciler which blocks until its context is cancelled")
+				Expect(<-reconciled).To(Equal(request))
+				Eventually(func() float64 {
+					Expect(ctrlmetrics.ReconcileTimeouts.WithLabelValues(ctrl.Name).Write(&reconcileTimeouts)).To(Succeed())
+					return reconcileTimeouts.GetCounter().GetValue()
+				}).Should(BeNumerically(">=", 1.0))
+
+				close(done)
+			})
+
+			It("should not requeue a request failing with a terminal error", func(done Done) {
+				var terminalErrs dto.Metric
+				ctrlmetrics.TerminalReconcileErrors.Reset()
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(ctrl.Start(ctx)).NotTo(HaveOccurred())
+				}()
+				queue.Add(request)
+
+				By("Invoking Reconciler which will give a terminal error")
+				fakeReconcile.AddResult(reconcile.Result{}, reconcile.TerminalError(fmt.Errorf("expected error: invalid spec")))
+				Expect(<-reconciled).To(Equal(request))
+				Eventually(func() float64 {
+					Expect(ctrlmetrics.TerminalReconcileErrors.WithLabelValues(ctrl.Name).Write(&terminalErrs)).To(Succeed())
+					return terminalErrs.GetCounter().GetValue()
+				}).Should(Equal(1.0))
+
+				By("Removing the item from the queue without requeueing it")
+				Eventually(queue.Len).Should(Equal(0))
+				Expect(queue.NumRequeues(request)).To(Equal(0))
+				Consistently(reconciled).ShouldNot(Receive())
+
+				close(done)
+			}, 2.0)
+
 			It("should add a reconcile time to the reconcile time histogram", func(done Done) {
 				var reconcileTime dto.Metric
 				ctrlmetrics.ReconcileTime.Reset()
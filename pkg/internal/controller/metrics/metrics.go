@@ -39,6 +39,41 @@ var (
 		Help: "Total number of reconciliation errors per controller",
 	}, []string{"controller"})
 
+	// TerminalReconcileErrors is a prometheus counter metrics which holds the total
+	// number of terminal errors from the Reconciler, which aren't retried.
+	TerminalReconcileErrors = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_terminal_reconcile_errors_total",
+		Help: "Total number of terminal reconciliation errors per controller",
+	}, []string{"controller"})
+
+	// DeadLetters is a prometheus counter metrics which holds the total number of
+	// requests dropped after failing more than the max retries of their controller.
+	DeadLetters = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_reconcile_dead_letters_total",
+		Help: "Total number of requests dropped after exceeding the max retries per controller",
+	}, []string{"controller"})
+
+	// ReconcileTimeouts is a prometheus counter metrics which holds the total
+	// number of reconciliations which exceeded the reconcile timeout of their controller.
+	ReconcileTimeouts = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_reconcile_timeouts_total",
+		Help: "Total number of reconciliations exceeding the reconcile timeout per controller",
+	}, []string{"controller"})
+
+	// ReconcilePanics is a prometheus counter metrics which holds the total
+	// number of panics of the Reconciler recovered by the controller.
+	ReconcilePanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_reconcile_panics_total",
+		Help: "Total number of reconciliation panics per controller",
+	}, []string{"controller"})
+
+	// StuckReconciles is a prometheus metric which holds the number of reconciles
+	// running longer than the stuck reconcile threshold of their controller.
+	StuckReconciles = prometheus.NewGaugeVec(prometheus.GaugeOpts{
+		Name: "controller_runtime_stuck_reconciles",
+		Help: "Number of reconciliations running longer than the stuck reconcile threshold per controller",
+	}, []string{"controller"})
+
 	// ReconcileTime is a prometheus metric which keeps track of the duration
 	// of reconciliations.
 	ReconcileTime = prometheus.NewHistogramVec(prometheus.HistogramOpts{
@@ -67,6 +102,11 @@ func init() {
 	metrics.Registry.MustRegister(
 		ReconcileTotal,
 		ReconcileErrors,
+		TerminalReconcileErrors,
+		DeadLetters,
+		ReconcileTimeouts,
+		ReconcilePanics,
+		StuckReconciles,
 		ReconcileTime,
 		WorkerCount,
 		ActiveWorkers,
@@ -0,0 +1,305 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"container/heap"
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Prioritizer is implemented by the queues which process the items with the highest
+// priority first.
+type Prioritizer interface {
+	// SetPriority sets the priority of the next addition of item to the queue. The
+	// priority of an item already waiting in the queue is raised, never lowered.
+	SetPriority(item interface{}, priority int)
+}
+
+// NewPriorityQueue returns a rate limiting queue which processes the items with the
+// highest priority first, and the items of the same priority in the order they were
+// added. It implements Prioritizer.
+func NewPriorityQueue(rateLimiter workqueue.RateLimiter, name string) workqueue.RateLimitingInterface {
+	q := &priorityQueue{
+		cond:       sync.NewCond(&sync.Mutex{}),
+		dirty:      map[interface{}]int{},
+		processing: map[interface{}]struct{}{},
+		next:       map[interface{}]int{},
+	}
+	return &priorityRateLimitingQueue{
+		DelayingInterface: workqueue.NewDelayingQueueWithCustomQueue(q, name),
+		queue:             q,
+		rateLimiter:       rateLimiter,
+	}
+}
+
+// priorityRateLimitingQueue adds rate limiting to a delaying priority queue, like
+// workqueue.NewRateLimitingQueue does to a delaying FIFO queue.
+type priorityRateLimitingQueue struct {
+	workqueue.DelayingInterface
+	queue       *priorityQueue
+	rateLimiter workqueue.RateLimiter
+}
+
+var _ Prioritizer = &priorityRateLimitingQueue{}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (q *priorityRateLimitingQueue) AddRateLimited(item interface{}) {
+	q.DelayingInterface.AddAfter(item, q.rateLimiter.When(item))
+}
+
+// Forget implements workqueue.RateLimitingInterface.
+func (q *priorityRateLimitingQueue) Forget(item interface{}) {
+	q.rateLimiter.Forget(item)
+}
+
+// NumRequeues implements workqueue.RateLimitingInterface.
+func (q *priorityRateLimitingQueue) NumRequeues(item interface{}) int {
+	return q.rateLimiter.NumRequeues(item)
+}
+
+// SetPriority implements Prioritizer.
+func (q *priorityRateLimitingQueue) SetPriority(item interface{}, priority int) {
+	q.queue.setPriority(item, priority)
+}
+
+// priorityQueue is a workqueue.Interface processing the items by priority. Like
+// workqueue.Type, it holds an item once however many times it is added, and doesn't
+// hand out an item being processed before it is done.
+type priorityQueue struct {
+	cond *sync.Cond
+
+	// entries holds the items waiting to be processed. An item whose priority was
+	// raised has stale entries, which are skipped.
+	entries priorityEntries
+	// length is the number of items waiting to be processed.
+	length int
+	// seq orders the entries of the same priority.
+	seq int64
+
+	// dirty holds the priorities of the items to be processed.
+	dirty map[interface{}]int
+	// processing holds the items being processed.
+	processing map[interface{}]struct{}
+	// next holds the priorities of the next additions of items.
+	next map[interface{}]int
+
+	shuttingDown bool
+}
+
+func (q *priorityQueue) setPriority(item interface{}, priority int) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if current, ok := q.next[item]; !ok || priority > current {
+		q.next[item] = priority
+	}
+}
+
+func (q *priorityQueue) push(item interface{}, priority int) {
+	q.seq++
+	heap.Push(&q.entries, priorityEntry{item: item, priority: priority, seq: q.seq})
+}
+
+// Add implements workqueue.Interface.
+func (q *priorityQueue) Add(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	if q.shuttingDown {
+		return
+	}
+	priority := q.next[item]
+	delete(q.next, item)
+
+	current, dirty := q.dirty[item]
+	if dirty && priority <= current {
+		return
+	}
+	q.dirty[item] = priority
+	if _, processing := q.processing[item]; processing {
+		// it is pushed once done
+		return
+	}
+	q.push(item, priority)
+	if !dirty {
+		q.length++
+		q.cond.Signal()
+	}
+}
+
+// Len implements workqueue.Interface.
+func (q *priorityQueue) Len() int {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.length
+}
+
+// Get implements workqueue.Interface.
+func (q *priorityQueue) Get() (interface{}, bool) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	for q.length == 0 && !q.shuttingDown {
+		q.cond.Wait()
+	}
+	if q.length == 0 {
+		// the queue is shutting down
+		return nil, true
+	}
+
+	for {
+		entry := heap.Pop(&q.entries).(priorityEntry)
+		priority, dirty := q.dirty[entry.item]
+		if _, processing := q.processing[entry.item]; !dirty || processing || priority != entry.priority {
+			// stale entry
+			continue
+		}
+		q.length--
+		delete(q.dirty, entry.item)
+		q.processing[entry.item] = struct{}{}
+		return entry.item, false
+	}
+}
+
+// Done implements workqueue.Interface.
+func (q *priorityQueue) Done(item interface{}) {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	delete(q.processing, item)
+	if priority, dirty := q.dirty[item]; dirty {
+		q.push(item, priority)
+		q.length++
+		q.cond.Signal()
+	}
+}
+
+// ShutDown implements workqueue.Interface.
+func (q *priorityQueue) ShutDown() {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	q.shuttingDown = true
+	q.cond.Broadcast()
+}
+
+// ShuttingDown implements workqueue.Interface.
+func (q *priorityQueue) ShuttingDown() bool {
+	q.cond.L.Lock()
+	defer q.cond.L.Unlock()
+	return q.shuttingDown
+}
+
+type priorityEntry struct {
+	item     interface{}
+	priority int
+	seq      int64
+}
+
+// priorityEntries implements heap.Interface, the entry with the highest priority,
+// then the lowest seq, first.
+type priorityEntries []priorityEntry
+
+func (e priorityEntries) Len() int { return len(e) }
+
+func (e priorityEntries) Less(i, j int) bool {
+	if e[i].priority != e[j].priority {
+		return e[i].priority > e[j].priority
+	}
+	return e[i].seq < e[j].seq
+}
+
+func (e priorityEntries) Swap(i, j int) { e[i], e[j] = e[j], e[i] }
+
+func (e *priorityEntries) Push(x interface{}) { *e = append(*e, x.(priorityEntry)) }
+
+func (e *priorityEntries) Pop() interface{} {
+	old := *e
+	entry := old[len(old)-1]
+	*e = old[:len(old)-1]
+	return entry
+}
+
+// prioritizingHandler sets the priority of the requests added by its EventHandler to
+// the priority of the object of the event.
+type prioritizingHandler struct {
+	handler.EventHandler
+	controller  *Controller
+	prioritizer Prioritizer
+}
+
+func (h *prioritizingHandler) queue(obj client.Object, q workqueue.RateLimitingInterface) workqueue.RateLimitingInterface {
+	if obj == nil {
+		return q
+	}
+	return &prioritizingQueue{RateLimitingInterface: q, handler: h, priority: h.controller.Priority(obj)}
+}
+
+// Create implements handler.EventHandler.
+func (h *prioritizingHandler) Create(evt event.CreateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Create(evt, h.queue(evt.Object, q))
+}
+
+// Update implements handler.EventHandler.
+func (h *prioritizingHandler) Update(evt event.UpdateEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Update(evt, h.queue(evt.ObjectNew, q))
+}
+
+// Delete implements handler.EventHandler.
+func (h *prioritizingHandler) Delete(evt event.DeleteEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Delete(evt, h.queue(evt.Object, q))
+}
+
+// Generic implements handler.EventHandler.
+func (h *prioritizingHandler) Generic(evt event.GenericEvent, q workqueue.RateLimitingInterface) {
+	h.EventHandler.Generic(evt, h.queue(evt.Object, q))
+}
+
+// prioritizingQueue sets the priority of the items added to it.
+type prioritizingQueue struct {
+	workqueue.RateLimitingInterface
+	handler  *prioritizingHandler
+	priority int
+}
+
+func (q *prioritizingQueue) setPriority(item interface{}) {
+	// the requests of other shards are dropped, they must not hold a priority
+	if req, ok := item.(reconcile.Request); ok && q.handler.controller.ShardFilter != nil && !q.handler.controller.ShardFilter(req) {
+		return
+	}
+	q.handler.prioritizer.SetPriority(item, q.priority)
+}
+
+// Add implements workqueue.Interface.
+func (q *prioritizingQueue) Add(item interface{}) {
+	q.setPriority(item)
+	q.RateLimitingInterface.Add(item)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (q *prioritizingQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.setPriority(item)
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface.
+func (q *prioritizingQueue) AddRateLimited(item interface{}) {
+	q.setPriority(item)
+	q.RateLimitingInterface.AddRateLimited(item)
+}
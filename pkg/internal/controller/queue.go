@@ -0,0 +1,206 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/ratelimiter"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// QueueStats describes the backlog of the queue of a controller.
+type QueueStats struct {
+	// Length is the number of requests waiting to be reconciled.
+	Length int
+
+	// OldestItemAge is the time the oldest request waiting to be reconciled has been
+	// waiting since it was due. The requests retried with backoff are due once their
+	// backoff is over, they are only counted with the default queue of controllers.
+	OldestItemAge time.Duration
+}
+
+// QueueStats returns the stats of the queue of the controller, they are empty until
+// the controller is started.
+func (c *Controller) QueueStats() QueueStats {
+	queue, ok := c.inspectedQueue.Load().(*inspectedQueue)
+	if !ok {
+		return QueueStats{}
+	}
+	return QueueStats{Length: queue.Len(), OldestItemAge: queue.oldestItemAge()}
+}
+
+// NumRequeues returns the number of times req has been retried with backoff since it
+// last succeeded.
+func (c *Controller) NumRequeues(req reconcile.Request) int {
+	queue, ok := c.inspectedQueue.Load().(*inspectedQueue)
+	if !ok {
+		return 0
+	}
+	return queue.NumRequeues(req)
+}
+
+// DelayRecorder wraps the rate limiter given to the queue of a controller, and
+// records the backoff of the requests retried with AddRateLimited, so that the
+// queue stats know when they are due.
+type DelayRecorder struct {
+	ratelimiter.RateLimiter
+
+	mu     sync.Mutex
+	delays map[interface{}]time.Duration
+}
+
+// NewDelayRecorder returns a DelayRecorder wrapping limiter.
+func NewDelayRecorder(limiter ratelimiter.RateLimiter) *DelayRecorder {
+	return &DelayRecorder{RateLimiter: limiter, delays: map[interface{}]time.Duration{}}
+}
+
+// When implements ratelimiter.RateLimiter.
+func (r *DelayRecorder) When(item interface{}) time.Duration {
+	delay := r.RateLimiter.When(item)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.delays[item] = delay
+	return delay
+}
+
+// Forget implements ratelimiter.RateLimiter.
+func (r *DelayRecorder) Forget(item interface{}) {
+	r.take(item)
+	r.RateLimiter.Forget(item)
+}
+
+// take returns and forgets the last delay of item.
+func (r *DelayRecorder) take(item interface{}) (time.Duration, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	delay, ok := r.delays[item]
+	delete(r.delays, item)
+	return delay, ok
+}
+
+// inspectedQueue records the times the items added to it are due, until they
+// are taken from it.
+type inspectedQueue struct {
+	workqueue.RateLimitingInterface
+
+	// delays, if set, records the backoff of the items retried with AddRateLimited.
+	delays *DelayRecorder
+
+	mu sync.Mutex
+	// dueAts holds the times the items are due, like the queue holds them.
+	dueAts map[interface{}]dueAt
+	now    func() time.Time
+}
+
+// dueAt holds the times an item is due. Like the delaying queue, which holds an
+// item added with a delay once, with its earliest time, an item is waiting at
+// most once, until it is added to the queue, where it is held at most once too.
+type dueAt struct {
+	// queued is the time the item in the queue was due, zero if it isn't queued.
+	queued time.Time
+	// waiting is the time the item added with a delay is due, zero if none is.
+	waiting time.Time
+}
+
+func newInspectedQueue(queue workqueue.RateLimitingInterface, delays *DelayRecorder) *inspectedQueue {
+	return &inspectedQueue{RateLimitingInterface: queue, delays: delays, dueAts: map[interface{}]dueAt{}, now: time.Now}
+}
+
+// due records that item is due at when. An item already queued keeps the time it
+// was due first, and an item already waiting keeps its earliest time.
+func (q *inspectedQueue) due(item interface{}, when time.Time) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+	d := q.dueAts[item]
+	// the delaying queue has added the waiting item to the queue by now
+	if !d.waiting.IsZero() && !d.waiting.After(now) {
+		if d.queued.IsZero() {
+			d.queued = d.waiting
+		}
+		d.waiting = time.Time{}
+	}
+	switch {
+	case !when.After(now):
+		if d.queued.IsZero() {
+			d.queued = when
+		}
+	case d.waiting.IsZero() || when.Before(d.waiting):
+		d.waiting = when
+	}
+	q.dueAts[item] = d
+}
+
+// Add implements workqueue.Interface.
+func (q *inspectedQueue) Add(item interface{}) {
+	q.due(item, q.now())
+	q.RateLimitingInterface.Add(item)
+}
+
+// AddAfter implements workqueue.DelayingInterface.
+func (q *inspectedQueue) AddAfter(item interface{}, duration time.Duration) {
+	q.due(item, q.now().Add(duration))
+	q.RateLimitingInterface.AddAfter(item, duration)
+}
+
+// AddRateLimited implements workqueue.RateLimitingInterface. The item is only
+// tracked when the rate limiter of the queue records its backoff.
+func (q *inspectedQueue) AddRateLimited(item interface{}) {
+	q.RateLimitingInterface.AddRateLimited(item)
+	if q.delays == nil {
+		return
+	}
+	if delay, ok := q.delays.take(item); ok {
+		q.due(item, q.now().Add(delay))
+	}
+}
+
+// Get implements workqueue.Interface. It forgets the time item was due, but not
+// the time it will be due once added with a delay.
+func (q *inspectedQueue) Get() (interface{}, bool) {
+	item, shutdown := q.RateLimitingInterface.Get()
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	d := q.dueAts[item]
+	if d.waiting.After(q.now()) {
+		q.dueAts[item] = dueAt{waiting: d.waiting}
+	} else {
+		delete(q.dueAts, item)
+	}
+	return item, shutdown
+}
+
+func (q *inspectedQueue) oldestItemAge() time.Duration {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	now := q.now()
+	var oldest time.Duration
+	for _, d := range q.dueAts {
+		for _, when := range []time.Time{d.queued, d.waiting} {
+			if when.IsZero() {
+				continue
+			}
+			if age := now.Sub(when); age > oldest {
+				oldest = age
+			}
+		}
+	}
+	return oldest
+}
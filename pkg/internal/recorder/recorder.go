@@ -35,6 +35,14 @@ import (
 // or not (e.g. if it's shared, it shouldn't be stopped with the Provider).
 type EventBroadcasterProducer func() (caster record.EventBroadcaster, stopWithProvider bool)
 
+// Options configures where a Provider records events.
+type Options struct {
+	// Sink, if set, receives the events instead of the API server.
+	Sink record.EventSink
+	// DisableLogging stops logging the events.
+	DisableLogging bool
+}
+
 // Provider is a recorder.Provider that records events to the k8s API server
 // and to a logr Logger.
 type Provider struct {
@@ -47,6 +55,7 @@ type Provider struct {
 	logger          logr.Logger
 	evtClient       typedcorev1.EventInterface
 	makeBroadcaster EventBroadcasterProducer
+	options         Options
 
 	broadcasterOnce sync.Once
 	broadcaster     record.EventBroadcaster
@@ -67,14 +76,13 @@ func (p *Provider) Stop(shutdownCtx context.Context) {
 	doneCh := make(chan struct{})
 
 	go func() {
-		// technically, this could start the broadcaster, but practically, it's
-		// almost certainly already been started (e.g. by leader election).  We
-		// need to invoke this to ensure that we don't inadvertently race with
-		// an invocation of getBroadcaster.
-		broadcaster := p.getBroadcaster()
-		if p.stopBroadcaster {
+		// Prevent the broadcaster from being created from now on, and wait
+		// for a concurrent creation to finish, so that we don't race with an
+		// invocation of getBroadcaster, nor start a broadcaster just to stop it.
+		p.broadcasterOnce.Do(func() {})
+		if p.broadcaster != nil && p.stopBroadcaster {
 			p.lock.Lock()
-			broadcaster.Shutdown()
+			p.broadcaster.Shutdown()
 			p.stopped = true
 			p.lock.Unlock()
 		}
@@ -88,7 +96,8 @@ func (p *Provider) Stop(shutdownCtx context.Context) {
 }
 
 // getBroadcaster ensures that a broadcaster is started for this
-// provider, and returns it.  It's threadsafe.
+// provider, and returns it, or nil if the provider was stopped before
+// any broadcaster was started.  It's threadsafe.
 func (p *Provider) getBroadcaster() record.EventBroadcaster {
 	// NB(directxman12): this can technically still leak if something calls
 	// "getBroadcaster" (i.e. Emits an Event) but never calls Start, but if we
@@ -98,11 +107,17 @@ func (p *Provider) getBroadcaster() record.EventBroadcaster {
 
 	p.broadcasterOnce.Do(func() {
 		broadcaster, stop := p.makeBroadcaster()
-		broadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: p.evtClient})
-		broadcaster.StartEventWatcher(
-			func(e *corev1.Event) {
-				p.logger.V(1).Info(e.Type, "object", e.InvolvedObject, "reason", e.Reason, "message", e.Message)
-			})
+		var sink record.EventSink = &typedcorev1.EventSinkImpl{Interface: p.evtClient}
+		if p.options.Sink != nil {
+			sink = p.options.Sink
+		}
+		broadcaster.StartRecordingToSink(sink)
+		if !p.options.DisableLogging {
+			broadcaster.StartEventWatcher(
+				func(e *corev1.Event) {
+					p.logger.V(1).Info(e.Type, "object", e.InvolvedObject, "reason", e.Reason, "message", e.Message)
+				})
+		}
 		p.broadcaster = broadcaster
 		p.stopBroadcaster = stop
 	})
@@ -111,13 +126,13 @@ func (p *Provider) getBroadcaster() record.EventBroadcaster {
 }
 
 // NewProvider create a new Provider instance.
-func NewProvider(config *rest.Config, scheme *runtime.Scheme, logger logr.Logger, makeBroadcaster EventBroadcasterProducer) (*Provider, error) {
+func NewProvider(config *rest.Config, scheme *runtime.Scheme, logger logr.Logger, makeBroadcaster EventBroadcasterProducer, options Options) (*Provider, error) {
 	clientSet, err := kubernetes.NewForConfig(config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to init clientSet: %w", err)
 	}
 
-	p := &Provider{scheme: scheme, logger: logger, makeBroadcaster: makeBroadcaster, evtClient: clientSet.CoreV1().Events("")}
+	p := &Provider{scheme: scheme, logger: logger, makeBroadcaster: makeBroadcaster, evtClient: clientSet.CoreV1().Events(""), options: options}
 	return p, nil
 }
 
@@ -143,8 +158,9 @@ type lazyRecorder struct {
 // ensureRecording ensures that a concrete recorder is populated for this recorder.
 func (l *lazyRecorder) ensureRecording() {
 	l.recOnce.Do(func() {
-		broadcaster := l.prov.getBroadcaster()
-		l.rec = broadcaster.NewRecorder(l.prov.scheme, corev1.EventSource{Component: l.name})
+		if broadcaster := l.prov.getBroadcaster(); broadcaster != nil {
+			l.rec = broadcaster.NewRecorder(l.prov.scheme, corev1.EventSource{Component: l.name})
+		}
 	})
 }
 
@@ -152,7 +168,7 @@ func (l *lazyRecorder) Event(object runtime.Object, eventtype, reason, message s
 	l.ensureRecording()
 
 	l.prov.lock.RLock()
-	if !l.prov.stopped {
+	if !l.prov.stopped && l.rec != nil {
 		l.rec.Event(object, eventtype, reason, message)
 	}
 	l.prov.lock.RUnlock()
@@ -161,7 +177,7 @@ func (l *lazyRecorder) Eventf(object runtime.Object, eventtype, reason, messageF
 	l.ensureRecording()
 
 	l.prov.lock.RLock()
-	if !l.prov.stopped {
+	if !l.prov.stopped && l.rec != nil {
 		l.rec.Eventf(object, eventtype, reason, messageFmt, args...)
 	}
 	l.prov.lock.RUnlock()
@@ -170,7 +186,7 @@ func (l *lazyRecorder) AnnotatedEventf(object runtime.Object, annotations map[st
 	l.ensureRecording()
 
 	l.prov.lock.RLock()
-	if !l.prov.stopped {
+	if !l.prov.stopped && l.rec != nil {
 		l.rec.AnnotatedEventf(object, annotations, eventtype, reason, messageFmt, args...)
 	}
 	l.prov.lock.RUnlock()
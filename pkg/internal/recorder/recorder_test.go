@@ -17,9 +17,13 @@ limitations under the License.
 package recorder_test
 
 import (
+	"context"
+
 	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes/scheme"
 	"k8s.io/client-go/tools/record"
 	"sigs.k8s.io/controller-runtime/pkg/internal/recorder"
@@ -29,7 +33,7 @@ var _ = Describe("recorder.Provider", func() {
 	makeBroadcaster := func() (record.EventBroadcaster, bool) { return record.NewBroadcaster(), true }
 	Describe("NewProvider", func() {
 		It("should return a provider instance and a nil error.", func() {
-			provider, err := recorder.NewProvider(cfg, scheme.Scheme, logr.DiscardLogger{}, makeBroadcaster)
+			provider, err := recorder.NewProvider(cfg, scheme.Scheme, logr.DiscardLogger{}, makeBroadcaster, recorder.Options{})
 			Expect(provider).NotTo(BeNil())
 			Expect(err).NotTo(HaveOccurred())
 		})
@@ -38,18 +42,70 @@ var _ = Describe("recorder.Provider", func() {
 			// Invalid the config
 			cfg1 := *cfg
 			cfg1.Host = "invalid host"
-			_, err := recorder.NewProvider(&cfg1, scheme.Scheme, logr.DiscardLogger{}, makeBroadcaster)
+			_, err := recorder.NewProvider(&cfg1, scheme.Scheme, logr.DiscardLogger{}, makeBroadcaster, recorder.Options{})
 			Expect(err).NotTo(BeNil())
 			Expect(err.Error()).To(ContainSubstring("failed to init clientSet"))
 		})
 	})
 	Describe("GetEventRecorder", func() {
 		It("should return a recorder instance.", func() {
-			provider, err := recorder.NewProvider(cfg, scheme.Scheme, logr.DiscardLogger{}, makeBroadcaster)
+			provider, err := recorder.NewProvider(cfg, scheme.Scheme, logr.DiscardLogger{}, makeBroadcaster, recorder.Options{})
 			Expect(err).NotTo(HaveOccurred())
 
 			recorder := provider.GetEventRecorderFor("test")
 			Expect(recorder).NotTo(BeNil())
 		})
+
+		It("should record events to the sink of the options", func() {
+			sink := &fakeSink{events: make(chan *corev1.Event, 1)}
+			provider, err := recorder.NewProvider(cfg, scheme.Scheme, logr.DiscardLogger{}, makeBroadcaster, recorder.Options{
+				Sink:           sink,
+				DisableLogging: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			defer provider.Stop(context.Background())
+
+			pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "test"}}
+			provider.GetEventRecorderFor("test").Event(pod, corev1.EventTypeNormal, "Test", "test event")
+			var event *corev1.Event
+			Eventually(sink.events).Should(Receive(&event))
+			Expect(event.Reason).To(Equal("Test"))
+			Expect(event.InvolvedObject.Name).To(Equal("test"))
+		})
+	})
+	Describe("Stop", func() {
+		It("should not make a broadcaster if no event was recorded", func() {
+			made := false
+			provider, err := recorder.NewProvider(cfg, scheme.Scheme, logr.DiscardLogger{}, func() (record.EventBroadcaster, bool) {
+				made = true
+				return record.NewBroadcaster(), true
+			}, recorder.Options{})
+			Expect(err).NotTo(HaveOccurred())
+
+			provider.Stop(context.Background())
+			Expect(made).To(BeFalse())
+
+			// recording an event after stopping the provider doesn't panic
+			provider.GetEventRecorderFor("test").Event(&corev1.Pod{}, corev1.EventTypeNormal, "Test", "test event")
+			Expect(made).To(BeFalse())
+		})
 	})
 })
+
+// fakeSink is a record.EventSink sending the created events to a channel.
+type fakeSink struct {
+	events chan *corev1.Event
+}
+
+func (s *fakeSink) Create(event *corev1.Event) (*corev1.Event, error) {
+	s.events <- event
+	return event, nil
+}
+
+func (s *fakeSink) Update(event *corev1.Event) (*corev1.Event, error) {
+	return event, nil
+}
+
+func (s *fakeSink) Patch(event *corev1.Event, _ []byte) (*corev1.Event, error) {
+	return event, nil
+}
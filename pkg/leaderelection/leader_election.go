@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"os"
+	"strings"
 
 	"k8s.io/apimachinery/pkg/util/uuid"
 	"k8s.io/client-go/kubernetes"
@@ -31,6 +32,24 @@ import (
 
 const inClusterNamespacePath = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
 
+// NamespaceDetectionError is returned when LeaderElectionNamespace isn't set
+// and the namespace the process runs in can't be detected, e.g. because it
+// doesn't run in a cluster.
+type NamespaceDetectionError struct {
+	// Err is the reason why the namespace couldn't be detected.
+	Err error
+}
+
+// Error implements error.
+func (e *NamespaceDetectionError) Error() string {
+	return fmt.Sprintf("unable to find leader election namespace: %v", e.Err)
+}
+
+// Unwrap returns the reason why the namespace couldn't be detected.
+func (e *NamespaceDetectionError) Unwrap() error {
+	return e.Err
+}
+
 // Options provides the required configuration to create a new resource lock.
 type Options struct {
 	// LeaderElection determines whether or not to use leader election when
@@ -38,7 +57,7 @@ type Options struct {
 	LeaderElection bool
 
 	// LeaderElectionResourceLock determines which resource lock to use for leader election,
-	// defaults to "configmapsleases".
+	// defaults to "leases".
 	LeaderElectionResourceLock string
 
 	// LeaderElectionNamespace determines the namespace in which the leader
@@ -56,11 +75,10 @@ func NewResourceLock(config *rest.Config, recorderProvider recorder.Provider, op
 		return nil, nil
 	}
 
-	// Default resource lock to "configmapsleases". We must keep this default until we are sure all controller-runtime
-	// users have upgraded from the original default ConfigMap lock to a controller-runtime version that has this new
-	// default. Many users of controller-runtime skip versions, so we should be extremely conservative here.
+	// Default resource lock to "leases". The previous default "configmapsleases" already acquired a Lease as well,
+	// so managers using either of them agree on the leader, which makes the change safe during upgrades.
 	if options.LeaderElectionResourceLock == "" {
-		options.LeaderElectionResourceLock = resourcelock.ConfigMapsLeasesResourceLock
+		options.LeaderElectionResourceLock = resourcelock.LeasesResourceLock
 	}
 
 	// LeaderElectionID must be provided to prevent clashes
@@ -73,7 +91,7 @@ func NewResourceLock(config *rest.Config, recorderProvider recorder.Provider, op
 		var err error
 		options.LeaderElectionNamespace, err = getInClusterNamespace()
 		if err != nil {
-			return nil, fmt.Errorf("unable to find leader election namespace: %w", err)
+			return nil, &NamespaceDetectionError{Err: err}
 		}
 	}
 
@@ -115,5 +133,5 @@ func getInClusterNamespace() (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("error reading namespace file: %w", err)
 	}
-	return string(namespace), nil
+	return strings.TrimSpace(string(namespace)), nil
 }
@@ -0,0 +1,66 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	"github.com/onsi/ginkgo/extensions/table"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("cron schedules", func() {
+	// a Friday
+	now := time.Date(2021, time.July, 16, 10, 30, 15, 0, time.UTC)
+
+	table.DescribeTable("should find the next run",
+		func(expr string, expected time.Time) {
+			schedule, err := parseCron(expr)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(schedule.next(now)).To(Equal(expected))
+		},
+		table.Entry("every minute", "* * * * *", time.Date(2021, time.July, 16, 10, 31, 0, 0, time.UTC)),
+		table.Entry("steps", "*/20 * * * *", time.Date(2021, time.July, 16, 10, 40, 0, 0, time.UTC)),
+		table.Entry("steps from a value", "5/20 * * * *", time.Date(2021, time.July, 16, 10, 45, 0, 0, time.UTC)),
+		table.Entry("lists", "0 9,12 * * *", time.Date(2021, time.July, 16, 12, 0, 0, 0, time.UTC)),
+		table.Entry("ranges of days of week", "0 3 * * 1-5", time.Date(2021, time.July, 19, 3, 0, 0, 0, time.UTC)),
+		table.Entry("sundays as 7", "0 0 * * 7", time.Date(2021, time.July, 18, 0, 0, 0, 0, time.UTC)),
+		table.Entry("days of month or of week", "0 0 1 * 6", time.Date(2021, time.July, 17, 0, 0, 0, 0, time.UTC)),
+		table.Entry("months", "0 0 1 2 *", time.Date(2022, time.February, 1, 0, 0, 0, 0, time.UTC)),
+		table.Entry("leap days", "0 0 29 2 *", time.Date(2024, time.February, 29, 0, 0, 0, 0, time.UTC)),
+		table.Entry("macros", "@daily", time.Date(2021, time.July, 17, 0, 0, 0, 0, time.UTC)),
+	)
+
+	It("should not find runs of impossible schedules", func() {
+		schedule, err := parseCron("0 0 30 2 *")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(schedule.next(now).IsZero()).To(BeTrue())
+	})
+
+	table.DescribeTable("should reject invalid expressions",
+		func(expr, message string) {
+			_, err := parseCron(expr)
+			Expect(err).To(MatchError(ContainSubstring(message)))
+		},
+		table.Entry("missing fields", "0 0 * *", "expected 5 fields"),
+		table.Entry("out of range values", "60 * * * *", "invalid minute"),
+		table.Entry("invalid values", "0 0 * jan *", "invalid month"),
+		table.Entry("inverted ranges", "0 5-3 * * *", "invalid range"),
+		table.Entry("invalid steps", "*/0 * * * *", "invalid step"),
+	)
+})
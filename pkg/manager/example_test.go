@@ -19,9 +19,14 @@ package manager_test
 import (
 	"context"
 	"os"
+	"sync/atomic"
+
+	"k8s.io/client-go/rest"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/client/config"
+	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	conf "sigs.k8s.io/controller-runtime/pkg/config"
 	logf "sigs.k8s.io/controller-runtime/pkg/log"
 	"sigs.k8s.io/controller-runtime/pkg/manager"
@@ -68,6 +73,42 @@ func ExampleNew_multinamespaceCache() {
 	log.Info("created manager", "manager", mgr)
 }
 
+// This example creates a new Manager whose client counts the objects it creates,
+// e.g. to instrument it, by wrapping the default client.
+func ExampleNew_customClient() {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		log.Error(err, "unable to get kubeconfig")
+		os.Exit(1)
+	}
+
+	mgr, err := manager.New(cfg, manager.Options{
+		NewClient: func(cache cache.Cache, config *rest.Config, options client.Options, uncachedObjects ...client.Object) (client.Client, error) {
+			c, err := cluster.DefaultNewClient(cache, config, options, uncachedObjects...)
+			if err != nil {
+				return nil, err
+			}
+			return &countingClient{Client: c}, nil
+		},
+	})
+	if err != nil {
+		log.Error(err, "unable to set up manager")
+		os.Exit(1)
+	}
+	log.Info("created manager", "manager", mgr)
+}
+
+// countingClient is a client counting the objects it creates.
+type countingClient struct {
+	client.Client
+	created int64
+}
+
+func (c *countingClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	atomic.AddInt64(&c.created, 1)
+	return c.Client.Create(ctx, obj, opts...)
+}
+
 // This example adds a Runnable for the Manager to Start.
 func ExampleManager_add() {
 	err := mgr.Add(manager.RunnableFunc(func(context.Context) error {
@@ -80,6 +121,20 @@ func ExampleManager_add() {
 	}
 }
 
+// This example waits for the Manager to be elected leader, e.g. to start
+// work that must only run on a single replica.
+func ExampleManager_elected() {
+	go func() {
+		<-mgr.Elected()
+		log.Info("elected leader")
+	}()
+
+	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
+		log.Error(err, "unable start the manager")
+		os.Exit(1)
+	}
+}
+
 // This example starts a Manager that has had Runnables added.
 func ExampleManager_start() {
 	if err := mgr.Start(signals.SetupSignalHandler()); err != nil {
@@ -18,10 +18,15 @@ package manager
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"net/http/pprof"
+	"reflect"
+	"runtime/debug"
+	"strings"
 	"sync"
 	"time"
 
@@ -36,12 +41,14 @@ import (
 	"k8s.io/client-go/tools/record"
 
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	intrec "sigs.k8s.io/controller-runtime/pkg/internal/recorder"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 )
@@ -53,12 +60,19 @@ const (
 	defaultRetryPeriod            = 2 * time.Second
 	defaultGracefulShutdownPeriod = 30 * time.Second
 
+	minRestartBackoff = 1 * time.Second
+	maxRestartBackoff = 5 * time.Minute
+
 	defaultReadinessEndpoint = "/readyz"
 	defaultLivenessEndpoint  = "/healthz"
 	defaultMetricsEndpoint   = "/metrics"
 )
 
 var _ Runnable = &controllerManager{}
+var _ HookAdder = &controllerManager{}
+var _ RunnableRemover = &controllerManager{}
+var _ HealthProbeExtraHandlerAdder = &controllerManager{}
+var _ PeriodicAdder = &controllerManager{}
 
 type controllerManager struct {
 	// cluster holds a variety of methods to interact with a cluster. Required.
@@ -72,6 +86,9 @@ type controllerManager struct {
 	// These Runnables will not be blocked by lead election.
 	nonLeaderElectionRunnables []Runnable
 
+	// runnableNames holds the names of the UniquelyNamedRunnables added to the manager.
+	runnableNames map[string]struct{}
+
 	// recorderProvider is used to generate event recorders that will be injected into Controllers
 	// (and EventHandlers, Sources and Predicates).
 	recorderProvider *intrec.Provider
@@ -86,12 +103,23 @@ type controllerManager struct {
 	// metricsListener is used to serve prometheus metrics
 	metricsListener net.Listener
 
+	// metricsFilter, if set, filters the requests to the metrics server.
+	metricsFilter filters.Filter
+
+	// metricsTLSConfig, if set, makes the metrics server serve HTTPS, with the
+	// certificate reloaded by metricsCertWatcher, if set.
+	metricsTLSConfig   *tls.Config
+	metricsCertWatcher *certwatcher.CertWatcher
+
 	// metricsExtraHandlers contains extra handlers to register on http server that serves metrics.
 	metricsExtraHandlers map[string]http.Handler
 
 	// healthProbeListener is used to serve liveness probe
 	healthProbeListener net.Listener
 
+	// pprofListener is used to serve pprof profiles
+	pprofListener net.Listener
+
 	// Readiness probe endpoint name
 	readinessEndpointName string
 
@@ -104,6 +132,9 @@ type controllerManager struct {
 	// Healthz probe handler
 	healthzHandler *healthz.Handler
 
+	// healthProbeExtraHandlers contains extra handlers to register on http server that serves health probes.
+	healthProbeExtraHandlers map[string]http.Handler
+
 	mu             sync.Mutex
 	started        bool
 	startedLeader  bool
@@ -134,7 +165,16 @@ type controllerManager struct {
 	// election was configured.
 	elected chan struct{}
 
-	caches []hasCache
+	caches []Runnable
+
+	// hooks are run during the phases of Start, hooksRun records the
+	// phases that have run already.
+	hooks    map[Phase][]Hook
+	hooksRun map[Phase]bool
+
+	// cacheErr is the error of starting the caches, including the hooks
+	// run around it.
+	cacheErr error
 
 	// port is the port that the webhook server serves at.
 	port int
@@ -144,6 +184,13 @@ type controllerManager struct {
 	// if not set, webhook server would look up the server key and certificate in
 	// {TempDir}/k8s-webhook-server/serving-certs
 	certDir string
+	// certName and keyName are the names of the server certificate and key.
+	certName string
+	keyName  string
+	// clientCAName is the name of the CA certificate verifying the certificates of clients.
+	clientCAName string
+	// tlsMinVersion is the minimum version of TLS supported by the webhook server.
+	tlsMinVersion string
 
 	webhookServer *webhook.Server
 	// webhookServerOnce will be called in GetWebhookServer() to optionally initialize
@@ -164,10 +211,21 @@ type controllerManager struct {
 	// we can wait for them to exit before quitting the manager
 	waitForRunnable sync.WaitGroup
 
+	// runningMu guards running, which holds the comparable runnables that
+	// are running, so that they can be stopped when removed.
+	runningMu sync.Mutex
+	running   map[Runnable]*runningRunnable
+
 	// gracefulShutdownTimeout is the duration given to runnable to stop
 	// before the manager actually returns on stop.
 	gracefulShutdownTimeout time.Duration
 
+	// cacheSyncTimeout bounds the wait for the caches to sync at startup, 0 means no bound.
+	cacheSyncTimeout time.Duration
+
+	// panicPolicy decides whether the panics of runnables are recovered.
+	panicPolicy PanicPolicy
+
 	// onStoppedLeading is callled when the leader election lease is lost.
 	// It can be overridden for tests.
 	onStoppedLeading func()
@@ -185,6 +243,15 @@ type controllerManager struct {
 	internalProceduresStop chan struct{}
 }
 
+// runningRunnable is a runnable started by the manager.
+type runningRunnable struct {
+	cancel context.CancelFunc
+	// done is closed once the Start method of the runnable has returned.
+	done chan struct{}
+	// removed is true once the runnable has been removed from the manager.
+	removed bool
+}
+
 type hasCache interface {
 	Runnable
 	GetCache() cache.Cache
@@ -199,9 +266,24 @@ func (cm *controllerManager) Add(r Runnable) error {
 	}
 
 	// Set dependencies on the object
-	if err := cm.SetFields(r); err != nil {
+	injected := unwrapRunnable(r)
+	name := ""
+	if named, ok := injected.(UniquelyNamedRunnable); ok {
+		name = named.UniqueName()
+	}
+	if _, used := cm.runnableNames[name]; name != "" && used {
+		return fmt.Errorf("a runnable named %q was already added to the manager: names must be unique, e.g. so that "+
+			"the metrics of controllers don't get mixed up", name)
+	}
+	if err := cm.SetFields(injected); err != nil {
 		return err
 	}
+	if name != "" {
+		if cm.runnableNames == nil {
+			cm.runnableNames = map[string]struct{}{}
+		}
+		cm.runnableNames[name] = struct{}{}
+	}
 
 	var shouldStart bool
 
@@ -209,8 +291,11 @@ func (cm *controllerManager) Add(r Runnable) error {
 	if leRunnable, ok := r.(LeaderElectionRunnable); ok && !leRunnable.NeedLeaderElection() {
 		shouldStart = cm.started
 		cm.nonLeaderElectionRunnables = append(cm.nonLeaderElectionRunnables, r)
-	} else if hasCache, ok := r.(hasCache); ok {
-		cm.caches = append(cm.caches, hasCache)
+	} else if _, ok := injected.(hasCache); ok {
+		// Caches, e.g. the ones of additional clusters, added once the
+		// caches have been started are started right away.
+		shouldStart = cm.started
+		cm.caches = append(cm.caches, r)
 	} else {
 		shouldStart = cm.startedLeader
 		cm.leaderElectionRunnables = append(cm.leaderElectionRunnables, r)
@@ -224,6 +309,122 @@ func (cm *controllerManager) Add(r Runnable) error {
 	return nil
 }
 
+// Remove stops r and removes it from the manager.
+func (cm *controllerManager) Remove(r Runnable) error {
+	if !reflect.TypeOf(r).Comparable() {
+		return fmt.Errorf("can't remove runnable of type %T as it isn't comparable", r)
+	}
+
+	found := func() bool {
+		cm.mu.Lock()
+		defer cm.mu.Unlock()
+
+		var foundLeaderElection, foundNonLeaderElection, foundCache bool
+		cm.leaderElectionRunnables, foundLeaderElection = removeRunnable(cm.leaderElectionRunnables, r)
+		cm.nonLeaderElectionRunnables, foundNonLeaderElection = removeRunnable(cm.nonLeaderElectionRunnables, r)
+		for i, c := range cm.caches {
+			if c == r {
+				cm.caches = append(cm.caches[:i:i], cm.caches[i+1:]...)
+				foundCache = true
+				break
+			}
+		}
+		found := foundLeaderElection || foundNonLeaderElection || foundCache
+		if found {
+			if named, ok := unwrapRunnable(r).(UniquelyNamedRunnable); ok {
+				delete(cm.runnableNames, named.UniqueName())
+			}
+		}
+		return found
+	}()
+	if !found {
+		return fmt.Errorf("runnable %T hasn't been added to the manager", r)
+	}
+
+	cm.runningMu.Lock()
+	running, isRunning := cm.running[r]
+	if isRunning {
+		running.removed = true
+		delete(cm.running, r)
+	}
+	cm.runningMu.Unlock()
+
+	if isRunning {
+		running.cancel()
+		<-running.done
+	}
+	return nil
+}
+
+// unwrapRunnable returns the Runnable wrapped by WithRestartPolicy, or r itself,
+// so that runnables are told apart by their own type.
+func unwrapRunnable(r Runnable) Runnable {
+	if withPolicy, ok := r.(*restartPolicyRunnable); ok {
+		return withPolicy.Runnable
+	}
+	return r
+}
+
+// removeRunnable returns runnables without r, and whether r was found.
+func removeRunnable(runnables []Runnable, r Runnable) ([]Runnable, bool) {
+	for i, runnable := range runnables {
+		if runnable == r {
+			// don't modify the backing array, which may be iterated over
+			return append(runnables[:i:i], runnables[i+1:]...), true
+		}
+	}
+	return runnables, false
+}
+
+// AddPeriodic adds a PeriodicRunnable running fn at the interval or on the cron schedule.
+func (cm *controllerManager) AddPeriodic(name, schedule string, fn func(ctx context.Context) error) error {
+	p := &PeriodicRunnable{Name: name, Func: fn}
+	if interval, err := time.ParseDuration(schedule); err == nil {
+		p.Interval = interval
+	} else {
+		p.Schedule = schedule
+	}
+	if _, err := p.validate(); err != nil {
+		return err
+	}
+	return cm.Add(p)
+}
+
+// AddHook registers a hook to run during a phase of Start.
+func (cm *controllerManager) AddHook(phase Phase, hook Hook) error {
+	switch phase {
+	case BeforeWebhooks, BeforeCaches, BeforeRunnables, AfterLeaderElection:
+	default:
+		return fmt.Errorf("unknown phase %q", phase)
+	}
+
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+	if cm.hooksRun[phase] {
+		return fmt.Errorf("can't add hook as phase %s has already run", phase)
+	}
+	if cm.hooks == nil {
+		cm.hooks = map[Phase][]Hook{}
+	}
+	cm.hooks[phase] = append(cm.hooks[phase], hook)
+	return nil
+}
+
+// runHooks runs the hooks of phase in order, until one fails. It must be
+// called with the lock held.
+func (cm *controllerManager) runHooks(ctx context.Context, phase Phase) error {
+	if cm.hooksRun == nil {
+		cm.hooksRun = map[Phase]bool{}
+	}
+	cm.hooksRun[phase] = true
+	for _, hook := range cm.hooks[phase] {
+		if err := hook(ctx); err != nil {
+			return fmt.Errorf("%s hook failed: %w", phase, err)
+		}
+	}
+	return nil
+}
+
 // Deprecated: use the equivalent Options field to set a field. This method will be removed in v0.10.
 func (cm *controllerManager) SetFields(i interface{}) error {
 	if err := cm.cluster.SetFields(i); err != nil {
@@ -260,6 +461,33 @@ func (cm *controllerManager) AddMetricsExtraHandler(path string, handler http.Ha
 	return nil
 }
 
+// AddHealthProbeExtraHandler adds extra handler served on path to the http server that serves health probes.
+func (cm *controllerManager) AddHealthProbeExtraHandler(path string, handler http.Handler) error {
+	cm.mu.Lock()
+	defer cm.mu.Unlock()
+
+	for _, endpoint := range []string{cm.readinessEndpointName, cm.livenessEndpointName} {
+		if path == endpoint || strings.HasPrefix(path, endpoint+"/") {
+			return fmt.Errorf("overriding builtin %s endpoint is not allowed", endpoint)
+		}
+	}
+
+	if cm.healthzStarted {
+		return fmt.Errorf("unable to add extra handler because health probe endpoint has already been created")
+	}
+
+	if _, found := cm.healthProbeExtraHandlers[path]; found {
+		return fmt.Errorf("can't register extra handler by duplicate path %q on health probe http server", path)
+	}
+
+	if cm.healthProbeExtraHandlers == nil {
+		cm.healthProbeExtraHandlers = map[string]http.Handler{}
+	}
+	cm.healthProbeExtraHandlers[path] = handler
+	cm.logger.V(2).Info("Registering health probe http server extra handler", "path", path)
+	return nil
+}
+
 // AddHealthzCheck allows you to add Healthz checker.
 func (cm *controllerManager) AddHealthzCheck(name string, check healthz.Checker) error {
 	cm.mu.Lock()
@@ -338,9 +566,13 @@ func (cm *controllerManager) GetWebhookServer() *webhook.Server {
 	cm.webhookServerOnce.Do(func() {
 		if cm.webhookServer == nil {
 			cm.webhookServer = &webhook.Server{
-				Port:    cm.port,
-				Host:    cm.host,
-				CertDir: cm.certDir,
+				Port:          cm.port,
+				Host:          cm.host,
+				CertDir:       cm.certDir,
+				CertName:      cm.certName,
+				KeyName:       cm.keyName,
+				ClientCAName:  cm.clientCAName,
+				TLSMinVersion: cm.tlsMinVersion,
 			}
 		}
 		if err := cm.Add(cm.webhookServer); err != nil {
@@ -375,13 +607,26 @@ func (cm *controllerManager) serveMetrics() {
 		}
 	}()
 
+	var serverHandler http.Handler = mux
+	if cm.metricsFilter != nil {
+		serverHandler = cm.metricsFilter(mux)
+	}
 	server := http.Server{
-		Handler: mux,
+		Handler: serverHandler,
 	}
+
+	listener := cm.metricsListener
+	if cm.metricsTLSConfig != nil {
+		listener = tls.NewListener(listener, cm.metricsTLSConfig)
+		if cm.metricsCertWatcher != nil {
+			cm.startRunnable(cm.metricsCertWatcher)
+		}
+	}
+
 	// Run the server
 	cm.startRunnable(RunnableFunc(func(_ context.Context) error {
-		cm.logger.Info("starting metrics server", "path", defaultMetricsEndpoint)
-		if err := server.Serve(cm.metricsListener); err != nil && err != http.ErrServerClosed {
+		cm.logger.Info("starting metrics server", "path", defaultMetricsEndpoint, "secure", cm.metricsTLSConfig != nil)
+		if err := server.Serve(listener); err != nil && err != http.ErrServerClosed {
 			return err
 		}
 		return nil
@@ -414,6 +659,9 @@ func (cm *controllerManager) serveHealthProbes() {
 			// Append '/' suffix to handle subpaths
 			mux.Handle(cm.livenessEndpointName+"/", http.StripPrefix(cm.livenessEndpointName, cm.healthzHandler))
 		}
+		for path, extraHandler := range cm.healthProbeExtraHandlers {
+			mux.Handle(path, extraHandler)
+		}
 
 		// Run server
 		cm.startRunnable(RunnableFunc(func(_ context.Context) error {
@@ -432,6 +680,33 @@ func (cm *controllerManager) serveHealthProbes() {
 	}
 }
 
+func (cm *controllerManager) servePprof() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/debug/pprof/", pprof.Index)
+	mux.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	mux.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	mux.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	mux.HandleFunc("/debug/pprof/trace", pprof.Trace)
+
+	server := http.Server{
+		Handler: mux,
+	}
+	// Run the server
+	cm.startRunnable(RunnableFunc(func(_ context.Context) error {
+		cm.logger.Info("starting pprof server", "addr", cm.pprofListener.Addr())
+		if err := server.Serve(cm.pprofListener); err != nil && err != http.ErrServerClosed {
+			return err
+		}
+		return nil
+	}))
+
+	// Shutdown the server when stop is closed
+	<-cm.internalProceduresStop
+	if err := server.Shutdown(cm.shutdownCtx); err != nil {
+		cm.errChan <- err
+	}
+}
+
 func (cm *controllerManager) Start(ctx context.Context) (err error) {
 	if err := cm.Add(cm.cluster); err != nil {
 		return fmt.Errorf("failed to add cluster to runnables: %w", err)
@@ -476,6 +751,11 @@ func (cm *controllerManager) Start(ctx context.Context) (err error) {
 		go cm.serveHealthProbes()
 	}
 
+	// Serve pprof profiles
+	if cm.pprofListener != nil {
+		go cm.servePprof()
+	}
+
 	go cm.startNonLeaderElectionRunnables()
 
 	go func() {
@@ -574,55 +854,94 @@ func (cm *controllerManager) waitForRunnableToEnd(shutdownCancel context.CancelF
 }
 
 func (cm *controllerManager) startNonLeaderElectionRunnables() {
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
+	// The error is sent once the lock is released, stopping the manager takes it.
+	if err := func() error {
+		cm.mu.Lock()
+		defer cm.mu.Unlock()
 
-	// First start any webhook servers, which includes conversion, validation, and defaulting
-	// webhooks that are registered.
-	//
-	// WARNING: Webhooks MUST start before any cache is populated, otherwise there is a race condition
-	// between conversion webhooks and the cache sync (usually initial list) which causes the webhooks
-	// to never start because no cache can be populated.
-	for _, c := range cm.nonLeaderElectionRunnables {
-		if _, ok := c.(*webhook.Server); ok {
-			cm.startRunnable(c)
+		if err := cm.runHooks(cm.internalCtx, BeforeWebhooks); err != nil {
+			return err
 		}
-	}
 
-	// Start and wait for caches.
-	cm.waitForCache(cm.internalCtx)
+		// First start any webhook servers, which includes conversion, validation, and defaulting
+		// webhooks that are registered.
+		//
+		// WARNING: Webhooks MUST start before any cache is populated, otherwise there is a race condition
+		// between conversion webhooks and the cache sync (usually initial list) which causes the webhooks
+		// to never start because no cache can be populated.
+		for _, c := range cm.nonLeaderElectionRunnables {
+			if _, ok := unwrapRunnable(c).(*webhook.Server); ok {
+				cm.startRunnable(c)
+			}
+		}
 
-	// Start the non-leaderelection Runnables after the cache has synced
-	for _, c := range cm.nonLeaderElectionRunnables {
-		if _, ok := c.(*webhook.Server); ok {
-			continue
+		// Start and wait for caches.
+		if err := cm.waitForCache(cm.internalCtx); err != nil {
+			return err
+		}
+
+		// Start the non-leaderelection Runnables after the cache has synced
+		for _, c := range cm.nonLeaderElectionRunnables {
+			if _, ok := unwrapRunnable(c).(*webhook.Server); ok {
+				continue
+			}
+
+			// Controllers block, but we want to return an error if any have an error starting.
+			// Write any Start errors to a channel so we can return them
+			cm.startRunnable(c)
 		}
 
-		// Controllers block, but we want to return an error if any have an error starting.
-		// Write any Start errors to a channel so we can return them
-		cm.startRunnable(c)
+		// Warm up the leader election Runnables, they are started once elected.
+		for _, c := range cm.leaderElectionRunnables {
+			if w, ok := unwrapRunnable(c).(WarmupRunnable); ok {
+				if err := w.Warmup(cm.internalCtx); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	}(); err != nil {
+		cm.errChan <- err
 	}
 }
 
 func (cm *controllerManager) startLeaderElectionRunnables() {
-	cm.mu.Lock()
-	defer cm.mu.Unlock()
+	// The error is sent once the lock is released, stopping the manager takes it.
+	if err := func() error {
+		cm.mu.Lock()
+		defer cm.mu.Unlock()
 
-	cm.waitForCache(cm.internalCtx)
+		if err := cm.waitForCache(cm.internalCtx); err != nil {
+			return err
+		}
+		if err := cm.runHooks(cm.internalCtx, AfterLeaderElection); err != nil {
+			return err
+		}
 
-	// Start the leader election Runnables after the cache has synced
-	for _, c := range cm.leaderElectionRunnables {
-		// Controllers block, but we want to return an error if any have an error starting.
-		// Write any Start errors to a channel so we can return them
-		cm.startRunnable(c)
-	}
+		// Start the leader election Runnables after the cache has synced
+		for _, c := range cm.leaderElectionRunnables {
+			// Controllers block, but we want to return an error if any have an error starting.
+			// Write any Start errors to a channel so we can return them
+			cm.startRunnable(c)
+		}
 
-	cm.startedLeader = true
+		cm.startedLeader = true
+		return nil
+	}(); err != nil {
+		cm.errChan <- err
+	}
 }
 
-func (cm *controllerManager) waitForCache(ctx context.Context) {
+func (cm *controllerManager) waitForCache(ctx context.Context) error {
 	if cm.started {
-		return
+		return cm.cacheErr
+	}
+	// whatever happens, caches are only started once
+	cm.started = true
+
+	if err := cm.runHooks(ctx, BeforeCaches); err != nil {
+		cm.cacheErr = err
+		return err
 	}
 
 	for _, cache := range cm.caches {
@@ -630,15 +949,46 @@ func (cm *controllerManager) waitForCache(ctx context.Context) {
 	}
 
 	// Wait for the caches to sync.
-	// TODO(community): Check the return value and write a test
-	for _, cache := range cm.caches {
-		cache.GetCache().WaitForCacheSync(ctx)
+	syncCtx := ctx
+	if cm.cacheSyncTimeout > 0 {
+		var cancel context.CancelFunc
+		syncCtx, cancel = context.WithTimeout(ctx, cm.cacheSyncTimeout)
+		defer cancel()
 	}
-	// TODO: This should be the return value of cm.cache.WaitForCacheSync but we abuse
-	// cm.started as check if we already started the cache so it must always become true.
-	// Making sure that the cache doesn't get started twice is needed to not get a "close
-	// of closed channel" panic
-	cm.started = true
+	for _, c := range cm.caches {
+		cache := unwrapRunnable(c).(hasCache).GetCache()
+		// only fail if the timeout hit, not if the manager is stopping
+		if !cache.WaitForCacheSync(syncCtx) && ctx.Err() == nil && cm.cacheSyncTimeout > 0 {
+			cm.cacheErr = cacheSyncTimeoutError(cache, cm.cacheSyncTimeout)
+			return cm.cacheErr
+		}
+	}
+
+	cm.cacheErr = cm.runHooks(ctx, BeforeRunnables)
+	return cm.cacheErr
+}
+
+// cacheSyncTimeoutError returns the error of a cache that didn't sync within timeout,
+// naming its informers that didn't sync when the cache can tell them.
+func cacheSyncTimeoutError(c cache.Cache, timeout time.Duration) error {
+	var unsynced []string
+	if dumps, err := cache.Dump(c, false); err == nil {
+		for _, d := range dumps {
+			if d.Synced {
+				continue
+			}
+			name := d.GroupVersionKind.String()
+			if d.Namespace != "" {
+				name += " in namespace " + d.Namespace
+			}
+			unsynced = append(unsynced, name)
+		}
+	}
+	if len(unsynced) == 0 {
+		return fmt.Errorf("timed out after %v waiting for caches to sync", timeout)
+	}
+	return fmt.Errorf("timed out after %v waiting for caches to sync, informers not synced: %s "+
+		"(check the permissions to list and watch them)", timeout, strings.Join(unsynced, ", "))
 }
 
 func (cm *controllerManager) startLeaderElection() (err error) {
@@ -690,11 +1040,97 @@ func (cm *controllerManager) Elected() <-chan struct{} {
 }
 
 func (cm *controllerManager) startRunnable(r Runnable) {
+	ctx, cancel := context.WithCancel(cm.internalCtx)
+	running := &runningRunnable{cancel: cancel, done: make(chan struct{})}
+	// only comparable runnables can be removed
+	comparable := reflect.TypeOf(r).Comparable()
+	if comparable {
+		cm.runningMu.Lock()
+		if cm.running == nil {
+			cm.running = map[Runnable]*runningRunnable{}
+		}
+		cm.running[r] = running
+		cm.runningMu.Unlock()
+	}
+
 	cm.waitForRunnable.Add(1)
 	go func() {
 		defer cm.waitForRunnable.Done()
-		if err := r.Start(cm.internalCtx); err != nil {
+		defer close(running.done)
+		defer cancel()
+
+		err := cm.runWithRestartPolicy(ctx, r)
+
+		cm.runningMu.Lock()
+		removed := running.removed
+		if comparable && cm.running[r] == running {
+			delete(cm.running, r)
+		}
+		cm.runningMu.Unlock()
+
+		if err != nil {
+			if removed {
+				cm.logger.Error(err, "error stopping removed runnable")
+				return
+			}
 			cm.errChan <- err
 		}
 	}()
 }
+
+// runRunnable starts r, and turns its panics into errors if the panic policy says so,
+// in which case panicked is true.
+func (cm *controllerManager) runRunnable(ctx context.Context, r Runnable) (panicked bool, err error) {
+	if cm.panicPolicy == PanicPolicyRecover {
+		defer func() {
+			if p := recover(); p != nil {
+				runnable := fmt.Sprintf("%T", r)
+				runnablePanics.WithLabelValues(runnable).Inc()
+				panicked = true
+				err = fmt.Errorf("panic in runnable %s: %v [recovered]", runnable, p)
+				cm.logger.Error(err, "Observed a panic in a runnable", "stack", string(debug.Stack()))
+			}
+		}()
+	}
+	return false, r.Start(ctx)
+}
+
+// runWithRestartPolicy runs r until ctx is done, and handles its errors
+// according to its restart policy and to the panic policy of the manager.
+func (cm *controllerManager) runWithRestartPolicy(ctx context.Context, r Runnable) error {
+	policy := RestartPolicyNever
+	if withPolicy, ok := r.(RestartPolicyRunnable); ok {
+		policy = withPolicy.RestartPolicy()
+	}
+
+	backoff := minRestartBackoff
+	for {
+		started := time.Now()
+		panicked, err := cm.runRunnable(ctx, r)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+		switch {
+		case policy == RestartPolicyIgnore, panicked && policy != RestartPolicyOnFailure:
+			cm.logger.Error(err, "runnable failed, leaving it stopped", "runnable", fmt.Sprintf("%T", r))
+			return nil
+		case policy != RestartPolicyOnFailure:
+			return err
+		}
+
+		// start over once the runnable has been running for a while
+		if time.Since(started) > maxRestartBackoff {
+			backoff = minRestartBackoff
+		}
+		cm.logger.Error(err, "runnable failed, restarting it", "runnable", fmt.Sprintf("%T", r), "backoff", backoff)
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		if backoff > maxRestartBackoff {
+			backoff = maxRestartBackoff
+		}
+	}
+}
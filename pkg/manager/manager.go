@@ -18,10 +18,14 @@ package manager
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"net"
 	"net/http"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
@@ -29,9 +33,12 @@ import (
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/client-go/rest"
+	kleaderelection "k8s.io/client-go/tools/leaderelection"
 	"k8s.io/client-go/tools/leaderelection/resourcelock"
 	"k8s.io/client-go/tools/record"
+	certutil "k8s.io/client-go/util/cert"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
 	"sigs.k8s.io/controller-runtime/pkg/config"
@@ -41,6 +48,7 @@ import (
 	intrec "sigs.k8s.io/controller-runtime/pkg/internal/recorder"
 	"sigs.k8s.io/controller-runtime/pkg/leaderelection"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	"sigs.k8s.io/controller-runtime/pkg/recorder"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
@@ -57,6 +65,9 @@ type Manager interface {
 	// implements the inject interface - e.g. inject.Client.
 	// Depending on if a Runnable implements LeaderElectionRunnable interface, a Runnable can be run in either
 	// non-leaderelection mode (always running) or leader election mode (managed by leader election if enabled).
+	// Additional clusters created with cluster.New can be added too, so that the manager starts them and waits
+	// for their caches to sync before starting the other runnables, and stops them on shutdown. Clusters added
+	// once the manager has started are started right away, without waiting for their caches to sync.
 	Add(Runnable) error
 
 	// Elected is closed when this manager is elected leader of a group of
@@ -95,6 +106,26 @@ type Manager interface {
 	GetControllerOptions() v1alpha1.ControllerConfigurationSpec
 }
 
+// RunnableRemover is implemented by the managers whose runnables can be stopped
+// and removed individually, like the managers created by New.
+type RunnableRemover interface {
+	// Remove stops the given runnable and removes it from the manager, so that it isn't started
+	// anymore. It blocks until the Start method of the runnable has returned, so it must not be
+	// called by the runnable itself. Errors returned by a removed runnable are logged rather than
+	// stopping the manager. Runnables are compared with ==, so they must be comparable, e.g. pointers.
+	// Note that controllers can't be started again once they have been stopped.
+	Remove(Runnable) error
+}
+
+// HealthProbeExtraHandlerAdder is implemented by the managers that can serve extra
+// handlers next to the health probes, like the managers created by New.
+type HealthProbeExtraHandlerAdder interface {
+	// AddHealthProbeExtraHandler adds an extra handler served on path to the http server that serves
+	// the health probes, e.g. to expose the state of a feature next to the probes. The liveness and
+	// readiness endpoints can't be overridden, and handlers can't be added once the server started.
+	AddHealthProbeExtraHandler(path string, handler http.Handler) error
+}
+
 // Options are the arguments for creating a new Manager.
 type Options struct {
 	// Scheme is the scheme used to resolve runtime.Objects to GroupVersionKinds / Resources
@@ -138,25 +169,33 @@ type Options struct {
 
 	// LeaderElection determines whether or not to use leader election when
 	// starting the manager.
+	// The metrics, health probe, pprof and webhook servers, as well as the
+	// runnables which don't need leader election, are started regardless of
+	// leader election, so that replicas waiting for the lease pass their
+	// readiness probes and serve admission requests.
 	LeaderElection bool
 
 	// LeaderElectionResourceLock determines which resource lock to use for leader election,
-	// defaults to "configmapsleases". Change this value only if you know what you are doing.
+	// one of "leases", "configmapsleases", "endpointsleases", "configmaps" or "endpoints".
+	// Defaults to "leases". Change this value only if you know what you are doing.
 	// Otherwise, users of your controller might end up with multiple running instances that
 	// each acquired leadership through different resource locks during upgrades and thus
 	// act on the same resources concurrently.
-	// If you want to migrate to the "leases" resource lock, you might do so by migrating to the
-	// respective multilock first ("configmapsleases" or "endpointsleases"), which will acquire a
-	// leader lock on both resources. After all your users have migrated to the multilock, you can
-	// go ahead and migrate to "leases". Please also keep in mind, that users might skip versions
-	// of your controller.
+	// If you migrate from the "configmaps" or "endpoints" resource locks to "leases", do so
+	// by migrating to the respective multilock first ("configmapsleases" or "endpointsleases"),
+	// which will acquire a leader lock on both resources. After all your users have migrated to
+	// the multilock, you can go ahead and migrate to "leases". Please also keep in mind, that
+	// users might skip versions of your controller.
 	//
-	// Note: before controller-runtime version v0.7, the resource lock was set to "configmaps".
+	// Note: before controller-runtime version v0.7, the resource lock was set to "configmaps",
+	// and it was set to "configmapsleases" afterwards, which is safe to migrate to "leases" from.
 	// Please keep this in mind, when planning a proper migration path for your controller.
 	LeaderElectionResourceLock string
 
 	// LeaderElectionNamespace determines the namespace in which the leader
-	// election resource will be created.
+	// election resource will be created. Defaults to the namespace the manager
+	// runs in when it runs in a cluster; New returns a
+	// *leaderelection.NamespaceDetectionError when it can't be detected.
 	LeaderElectionNamespace string
 
 	// LeaderElectionID determines the name of the resource that leader election
@@ -165,6 +204,8 @@ type Options struct {
 
 	// LeaderElectionConfig can be specified to override the default configuration
 	// that is used to build the leader election client.
+	// Use it to give the leader election traffic its own QPS, burst and timeouts, so that
+	// lease renewals aren't starved by the API requests of the controllers.
 	LeaderElectionConfig *rest.Config
 
 	// LeaderElectionReleaseOnCancel defines if the leader should step down voluntarily
@@ -177,9 +218,12 @@ type Options struct {
 	// LeaseDuration is the duration that non-leader candidates will
 	// wait to force acquire leadership. This is measured against time of
 	// last observed ack. Default is 15 seconds.
+	// Longer durations tolerate flaky networks, shorter ones fail over faster.
+	// It must be greater than RenewDeadline.
 	LeaseDuration *time.Duration
 	// RenewDeadline is the duration that the acting controlplane will retry
 	// refreshing leadership before giving up. Default is 10 seconds.
+	// It must be greater than 1.2 times RetryPeriod.
 	RenewDeadline *time.Duration
 	// RetryPeriod is the duration the LeaderElector clients should wait
 	// between tries of actions. Default is 2 seconds.
@@ -198,16 +242,41 @@ type Options struct {
 	// It can be set to "0" to disable the metrics serving.
 	MetricsBindAddress string
 
+	// MetricsSecureServing makes the metrics server serve HTTPS, using the certificate
+	// tls.crt and the key tls.key of MetricsCertDir, which are reloaded when they change.
+	MetricsSecureServing bool
+
+	// MetricsCertDir is the directory that contains the certificate and key of the metrics
+	// server when MetricsSecureServing is enabled. Defaults to "", which makes the manager
+	// generate a self-signed certificate.
+	MetricsCertDir string
+
+	// MetricsFilterProvider creates a filter of the requests to the metrics server, e.g.
+	// filters.WithAuthenticationAndAuthorization to only serve clients that are allowed to
+	// by RBAC. Filters relying on bearer tokens should be used with MetricsSecureServing.
+	MetricsFilterProvider filters.Provider
+
 	// HealthProbeBindAddress is the TCP address that the controller should bind to
 	// for serving health probes
 	HealthProbeBindAddress string
 
-	// Readiness probe endpoint name, defaults to "readyz"
+	// Readiness probe endpoint name, defaults to "readyz".
+	// The endpoint is served at /<name>, and every check added with AddReadyzCheck
+	// is also served on its own at /<name>/<check name>.
 	ReadinessEndpointName string
 
-	// Liveness probe endpoint name, defaults to "healthz"
+	// Liveness probe endpoint name, defaults to "healthz".
+	// The endpoint is served at /<name>, and every check added with AddHealthzCheck
+	// is also served on its own at /<name>/<check name>.
 	LivenessEndpointName string
 
+	// PprofBindAddress is the TCP address that the controller should bind to
+	// for serving pprof profiles under /debug/pprof/. The endpoints are
+	// sensitive and shouldn't be exposed publicly.
+	// It can be set to "" or "0" to disable the pprof serving.
+	// Defaults to "", which disables it.
+	PprofBindAddress string
+
 	// Port is the port that the webhook server serves at.
 	// It is used to set webhook.Server.Port if WebhookServer is not set.
 	Port int
@@ -222,20 +291,53 @@ type Options struct {
 	// It is used to set webhook.Server.CertDir if WebhookServer is not set.
 	CertDir string
 
+	// CertName is the name of the server certificate in CertDir. Defaults to tls.crt.
+	// It is used to set webhook.Server.CertName if WebhookServer is not set.
+	CertName string
+
+	// KeyName is the name of the server key in CertDir. Defaults to tls.key.
+	// It is used to set webhook.Server.KeyName if WebhookServer is not set.
+	KeyName string
+
+	// ClientCAName is the name of the CA certificate in CertDir which the webhook server
+	// uses to verify the certificates of clients. Defaults to "", which means that the
+	// certificates of clients aren't verified.
+	// It is used to set webhook.Server.ClientCAName if WebhookServer is not set.
+	ClientCAName string
+
+	// TLSMinVersion is the minimum version of TLS supported by the webhook server, one of
+	// "", "1.0", "1.1", "1.2" and "1.3" ("" is equivalent to "1.0").
+	// It is used to set webhook.Server.TLSMinVersion if WebhookServer is not set.
+	TLSMinVersion string
+
 	// WebhookServer is an externally configured webhook.Server. By default,
-	// a Manager will create a default server using Port, Host, and CertDir;
+	// a Manager will create a default server using Port, Host, CertDir, CertName,
+	// KeyName, ClientCAName and TLSMinVersion;
 	// if this is set, the Manager will use this server instead.
 	WebhookServer *webhook.Server
 
+	// WebhookOnly makes the manager run without a connection to a cluster, e.g. for
+	// pure admission webhook deployments or air-gapped validation services.
+	// The config passed to New may then be nil: the manager serves webhooks, health
+	// probes and metrics, but its client and cache fail to map any object, unless
+	// MapperProvider is set, and leader election can't be enabled.
+	WebhookOnly bool
+
 	// Functions to all for a user to customize the values that will be injected.
 
 	// NewCache is the function that will create the cache to be used
 	// by the manager. If not set this will use the default new cache function.
+	// It gets the config of the manager, and cache options with the scheme, the
+	// RESTMapper, the sync period and the namespace of the manager, e.g. to
+	// create a sharded cache.
 	NewCache cache.NewCacheFunc
 
 	// NewClient is the func that creates the client to be used by the manager.
 	// If not set this will create the default DelegatingClient that will
 	// use the cache for reads and the client for writes.
+	// It gets the cache, the config, and client options with the scheme and the
+	// RESTMapper of the manager, e.g. to wrap cluster.DefaultNewClient in an
+	// instrumented client.
 	NewClient cluster.NewClientFunc
 
 	// ClientDisableCacheFor tells the client that, if any cache is used, to bypass it
@@ -250,15 +352,43 @@ type Options struct {
 	// Use this to customize the event correlator and spam filter
 	//
 	// Deprecated: using this may cause goroutine leaks if the lifetime of your manager or controllers
-	// is shorter than the lifetime of your process.
+	// is shorter than the lifetime of your process. Use EventCorrelatorOptions instead.
 	EventBroadcaster record.EventBroadcaster
 
+	// EventCorrelatorOptions configures the spam filter and the aggregation of the Events
+	// emitted by the manager, e.g. to raise the rate limits of controllers emitting many Events,
+	// which are dropped silently otherwise. It is ignored if EventBroadcaster is set.
+	EventCorrelatorOptions record.CorrelatorOptions
+
+	// EventSink, if set, receives the Events emitted by the manager instead of the Kubernetes API.
+	EventSink record.EventSink
+
+	// DisableEventLogging stops logging the Events emitted by the manager.
+	DisableEventLogging bool
+
 	// GracefulShutdownTimeout is the duration given to runnable to stop before the manager actually returns on stop.
+	// Controllers stop once their in-flight reconciles have finished. If runnables are still running after the
+	// timeout, Start returns an error without waiting for them any longer.
+	// Defaults to 30 seconds.
 	// To disable graceful shutdown, set to time.Duration(0)
 	// To use graceful shutdown without timeout, set to a negative duration, e.G. time.Duration(-1)
 	// The graceful shutdown is skipped for safety reasons in case the leader election lease is lost.
 	GracefulShutdownTimeout *time.Duration
 
+	// PanicPolicy decides what happens when a runnable or a reconciler panics, defaults to
+	// PanicPolicyCrash. With PanicPolicyRecover, a single bad object can't crash-loop the
+	// whole manager: the controllers recover the panics of their reconcilers, as with
+	// controller.Options.RecoverPanic, and a runnable whose Start method panics is restarted
+	// or left stopped, see PanicPolicyRecover. The panics of other goroutines started by
+	// runnables aren't covered.
+	PanicPolicy PanicPolicy
+
+	// CacheSyncTimeout is the maximum time the manager waits for its caches to sync
+	// at startup. If they didn't sync in time, e.g. because of missing RBAC permissions
+	// to list or watch a type, Start returns an error naming the informers that didn't sync.
+	// Defaults to 0, which means waiting until the caches sync or the manager is stopped.
+	CacheSyncTimeout time.Duration
+
 	// Controller contains global configuration options for controllers
 	// registered within this manager.
 	// +optional
@@ -271,10 +401,11 @@ type Options struct {
 	makeBroadcaster intrec.EventBroadcasterProducer
 
 	// Dependency injection for testing
-	newRecorderProvider    func(config *rest.Config, scheme *runtime.Scheme, logger logr.Logger, makeBroadcaster intrec.EventBroadcasterProducer) (*intrec.Provider, error)
+	newRecorderProvider    func(config *rest.Config, scheme *runtime.Scheme, logger logr.Logger, makeBroadcaster intrec.EventBroadcasterProducer, options intrec.Options) (*intrec.Provider, error)
 	newResourceLock        func(config *rest.Config, recorderProvider recorder.Provider, options leaderelection.Options) (resourcelock.Interface, error)
 	newMetricsListener     func(addr string) (net.Listener, error)
 	newHealthProbeListener func(addr string) (net.Listener, error)
+	newPprofListener       func(addr string) (net.Listener, error)
 }
 
 // Runnable allows a component to be started.
@@ -297,6 +428,42 @@ func (r RunnableFunc) Start(ctx context.Context) error {
 	return r(ctx)
 }
 
+// HookAdder is implemented by the managers that run hooks during their start,
+// like the managers created by New.
+type HookAdder interface {
+	// AddHook registers a hook to run to completion during a phase of Start,
+	// e.g. to run migrations before the controllers start, or to generate the
+	// certificates of the webhook server during BeforeWebhooks. The hooks of a
+	// phase run one after the other, in the order they were added, and Start
+	// returns the error of the first failing hook. Hooks can't be added once
+	// their phase has run, and must not call Add.
+	AddHook(phase Phase, hook Hook) error
+}
+
+// Phase is a step of the start of a manager, see HookAdder.
+type Phase string
+
+const (
+	// BeforeWebhooks is the phase before the webhook servers are started,
+	// which read their certificates as they start.
+	BeforeWebhooks Phase = "BeforeWebhooks"
+
+	// BeforeCaches is the phase once the webhook servers are started, before
+	// the caches are started.
+	BeforeCaches Phase = "BeforeCaches"
+
+	// BeforeRunnables is the phase once the caches have synced, before the
+	// runnables, e.g. the controllers, are started.
+	BeforeRunnables Phase = "BeforeRunnables"
+
+	// AfterLeaderElection is the phase once the manager has been elected
+	// leader, before the runnables that need leader election are started.
+	AfterLeaderElection Phase = "AfterLeaderElection"
+)
+
+// Hook is run to completion during a phase of the start of a manager.
+type Hook func(context.Context) error
+
 // LeaderElectionRunnable knows if a Runnable needs to be run in the leader election mode.
 type LeaderElectionRunnable interface {
 	// NeedLeaderElection returns true if the Runnable needs to be run in the leader election mode.
@@ -304,11 +471,130 @@ type LeaderElectionRunnable interface {
 	NeedLeaderElection() bool
 }
 
+// UniquelyNamedRunnable is a Runnable whose name is unique among the runnables of a
+// manager, e.g. a controller whose name labels its metrics. Add returns an error if
+// a runnable with the same name was already added to the manager.
+type UniquelyNamedRunnable interface {
+	// UniqueName returns the name of the Runnable. Empty names aren't checked.
+	UniqueName() string
+}
+
+// WarmupRunnable is a leader election Runnable which can prepare to run before the
+// manager is elected, e.g. a controller starting its watches so that a standby
+// replica reconciles right away once elected instead of syncing its caches then.
+// The manager calls Warmup once its caches are synced, whether it is elected or not.
+type WarmupRunnable interface {
+	// Warmup prepares the Runnable to start, it must not block. ctx is done when the
+	// manager stops.
+	Warmup(ctx context.Context) error
+}
+
+// RestartPolicy decides what the manager does when the Start method of a runnable returns an error.
+type RestartPolicy string
+
+const (
+	// RestartPolicyNever stops the manager, which returns the error. This is the default.
+	RestartPolicyNever RestartPolicy = "Never"
+
+	// RestartPolicyOnFailure logs the error and starts the runnable again after an exponential
+	// backoff, from 1 second up to 5 minutes. The runnable must support being started again,
+	// which controllers don't.
+	RestartPolicyOnFailure RestartPolicy = "OnFailure"
+
+	// RestartPolicyIgnore logs the error and leaves the runnable stopped.
+	RestartPolicyIgnore RestartPolicy = "Ignore"
+)
+
+// PanicPolicy decides what the manager does when the Start method of a runnable, or a
+// reconciler, panics.
+type PanicPolicy string
+
+const (
+	// PanicPolicyCrash lets the panic crash the process. This is the default.
+	PanicPolicyCrash PanicPolicy = "Crash"
+
+	// PanicPolicyRecover recovers the panic, logs it and counts it in the
+	// controller_runtime_runnable_panics_total metric, or in the
+	// controller_runtime_reconcile_panics_total metric for reconcilers. The
+	// manager keeps running: a runnable with RestartPolicyOnFailure is started
+	// again, and the others are left stopped.
+	PanicPolicyRecover PanicPolicy = "Recover"
+)
+
+// RestartPolicyRunnable knows what the manager should do when it fails, see RestartPolicy.
+type RestartPolicyRunnable interface {
+	// RestartPolicy returns the restart policy of the Runnable.
+	RestartPolicy() RestartPolicy
+}
+
+// WithRestartPolicy returns a Runnable that runs r with the given restart policy.
+// Dependencies are injected into r when the returned Runnable is added to a manager,
+// and it needs leader election if r does. The manager otherwise handles it like r,
+// e.g. it waits for the cache of r to sync or starts it first if r is a webhook server.
+func WithRestartPolicy(r Runnable, policy RestartPolicy) Runnable {
+	return &restartPolicyRunnable{Runnable: r, policy: policy}
+}
+
+// restartPolicyRunnable sets the restart policy of a Runnable.
+type restartPolicyRunnable struct {
+	Runnable
+	policy RestartPolicy
+}
+
+// RestartPolicy implements RestartPolicyRunnable.
+func (r *restartPolicyRunnable) RestartPolicy() RestartPolicy {
+	return r.policy
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable.
+func (r *restartPolicyRunnable) NeedLeaderElection() bool {
+	if leRunnable, ok := r.Runnable.(LeaderElectionRunnable); ok {
+		return leRunnable.NeedLeaderElection()
+	}
+	return true
+}
+
 // New returns a new Manager for creating Controllers.
 func New(config *rest.Config, options Options) (Manager, error) {
 	// Set default values for options fields
 	options = setOptionsDefaults(options)
 
+	if options.WebhookOnly {
+		if options.LeaderElection {
+			return nil, errors.New("leader election can't be enabled in webhook-only mode")
+		}
+		// Nothing is read from the cluster, so a config of no cluster is good enough.
+		if config == nil {
+			config = &rest.Config{}
+		}
+		if options.MapperProvider == nil {
+			options.MapperProvider = func(*rest.Config) (meta.RESTMapper, error) {
+				return meta.NewDefaultRESTMapper(nil), nil
+			}
+		}
+	}
+
+	if options.ReadinessEndpointName == options.LivenessEndpointName {
+		return nil, fmt.Errorf("the readiness and liveness probes can't both be served at %s", options.ReadinessEndpointName)
+	}
+
+	if options.LeaderElection {
+		if err := validateLeaderElectionTimings(options); err != nil {
+			return nil, err
+		}
+	}
+
+	switch options.PanicPolicy {
+	case "", PanicPolicyCrash:
+	case PanicPolicyRecover:
+		if options.Controller.RecoverPanic == nil {
+			recoverPanic := true
+			options.Controller.RecoverPanic = &recoverPanic
+		}
+	default:
+		return nil, fmt.Errorf("invalid panic policy %q, must be %q or %q", options.PanicPolicy, PanicPolicyCrash, PanicPolicyRecover)
+	}
+
 	cluster, err := cluster.New(config, func(clusterOptions *cluster.Options) {
 		clusterOptions.Scheme = options.Scheme
 		clusterOptions.MapperProvider = options.MapperProvider
@@ -320,6 +606,9 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		clusterOptions.ClientDisableCacheFor = options.ClientDisableCacheFor
 		clusterOptions.DryRunClient = options.DryRunClient
 		clusterOptions.EventBroadcaster = options.EventBroadcaster //nolint:staticcheck
+		clusterOptions.EventCorrelatorOptions = options.EventCorrelatorOptions
+		clusterOptions.EventSink = options.EventSink
+		clusterOptions.DisableEventLogging = options.DisableEventLogging
 	})
 	if err != nil {
 		return nil, err
@@ -328,7 +617,10 @@ func New(config *rest.Config, options Options) (Manager, error) {
 	// Create the recorder provider to inject event recorders for the components.
 	// TODO(directxman12): the log for the event provider should have a context (name, tags, etc) specific
 	// to the particular controller that it's being injected into, rather than a generic one like is here.
-	recorderProvider, err := options.newRecorderProvider(config, cluster.GetScheme(), options.Logger.WithName("events"), options.makeBroadcaster)
+	recorderProvider, err := options.newRecorderProvider(config, cluster.GetScheme(), options.Logger.WithName("events"), options.makeBroadcaster, intrec.Options{
+		Sink:           options.EventSink,
+		DisableLogging: options.DisableEventLogging,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -355,6 +647,21 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		return nil, err
 	}
 
+	var metricsFilter filters.Filter
+	if options.MetricsFilterProvider != nil {
+		if metricsFilter, err = options.MetricsFilterProvider(config); err != nil {
+			return nil, err
+		}
+	}
+
+	var metricsTLSConfig *tls.Config
+	var metricsCertWatcher *certwatcher.CertWatcher
+	if options.MetricsSecureServing {
+		if metricsTLSConfig, metricsCertWatcher, err = newMetricsTLSConfig(options.MetricsCertDir); err != nil {
+			return nil, err
+		}
+	}
+
 	// By default we have no extra endpoints to expose on metrics http server.
 	metricsExtraHandlers := make(map[string]http.Handler)
 
@@ -365,26 +672,43 @@ func New(config *rest.Config, options Options) (Manager, error) {
 		return nil, err
 	}
 
+	// Create pprof listener. This will throw an error if the bind
+	// address is invalid or already in use.
+	pprofListener, err := options.newPprofListener(options.PprofBindAddress)
+	if err != nil {
+		return nil, err
+	}
+
 	return &controllerManager{
 		cluster:                       cluster,
 		recorderProvider:              recorderProvider,
 		resourceLock:                  resourceLock,
 		metricsListener:               metricsListener,
 		metricsExtraHandlers:          metricsExtraHandlers,
+		metricsFilter:                 metricsFilter,
+		metricsTLSConfig:              metricsTLSConfig,
+		metricsCertWatcher:            metricsCertWatcher,
 		controllerOptions:             options.Controller,
 		logger:                        options.Logger,
 		elected:                       make(chan struct{}),
 		port:                          options.Port,
 		host:                          options.Host,
 		certDir:                       options.CertDir,
+		certName:                      options.CertName,
+		keyName:                       options.KeyName,
+		clientCAName:                  options.ClientCAName,
+		tlsMinVersion:                 options.TLSMinVersion,
 		webhookServer:                 options.WebhookServer,
 		leaseDuration:                 *options.LeaseDuration,
 		renewDeadline:                 *options.RenewDeadline,
 		retryPeriod:                   *options.RetryPeriod,
 		healthProbeListener:           healthProbeListener,
+		pprofListener:                 pprofListener,
 		readinessEndpointName:         options.ReadinessEndpointName,
 		livenessEndpointName:          options.LivenessEndpointName,
 		gracefulShutdownTimeout:       *options.GracefulShutdownTimeout,
+		cacheSyncTimeout:              options.CacheSyncTimeout,
+		panicPolicy:                   options.PanicPolicy,
 		internalProceduresStop:        make(chan struct{}),
 		leaderElectionStopped:         make(chan struct{}),
 		leaderElectionReleaseOnCancel: options.LeaderElectionReleaseOnCancel,
@@ -417,6 +741,10 @@ func (o Options) AndFrom(loader config.ControllerManagerConfiguration) (Options,
 		o.Namespace = newObj.CacheNamespace
 	}
 
+	if o.GracefulShutdownTimeout == nil && newObj.GracefulShutdownTimeout != nil {
+		o.GracefulShutdownTimeout = &newObj.GracefulShutdownTimeout.Duration
+	}
+
 	if o.MetricsBindAddress == "" && newObj.Metrics.BindAddress != "" {
 		o.MetricsBindAddress = newObj.Metrics.BindAddress
 	}
@@ -499,6 +827,51 @@ func (o Options) setLeaderElectionConfig(obj v1alpha1.ControllerManagerConfigura
 	return o
 }
 
+// newMetricsTLSConfig returns the TLS config of the metrics server, using the
+// certificate of certDir, or a self-signed certificate if certDir is empty.
+// The returned CertWatcher, if any, must be started to reload the certificate.
+func newMetricsTLSConfig(certDir string) (*tls.Config, *certwatcher.CertWatcher, error) {
+	if certDir == "" {
+		certPEM, keyPEM, err := certutil.GenerateSelfSignedCertKey("localhost", nil, nil)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to generate the certificate of the metrics server: %w", err)
+		}
+		cert, err := tls.X509KeyPair(certPEM, keyPEM)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to load the certificate of the metrics server: %w", err)
+		}
+		return &tls.Config{
+			Certificates: []tls.Certificate{cert},
+			MinVersion:   tls.VersionTLS12,
+		}, nil, nil
+	}
+
+	watcher, err := certwatcher.New(filepath.Join(certDir, "tls.crt"), filepath.Join(certDir, "tls.key"))
+	if err != nil {
+		return nil, nil, err
+	}
+	return &tls.Config{
+		GetCertificate: watcher.GetCertificate,
+		MinVersion:     tls.VersionTLS12,
+	}, watcher, nil
+}
+
+// validateLeaderElectionTimings checks the timings of the leader election the
+// same way as client-go does, so that invalid timings fail before Start.
+func validateLeaderElectionTimings(options Options) error {
+	leaseDuration, renewDeadline, retryPeriod := *options.LeaseDuration, *options.RenewDeadline, *options.RetryPeriod
+	if retryPeriod <= 0 {
+		return fmt.Errorf("RetryPeriod must be greater than zero, got %s", retryPeriod)
+	}
+	if leaseDuration <= renewDeadline {
+		return fmt.Errorf("LeaseDuration (%s) must be greater than RenewDeadline (%s)", leaseDuration, renewDeadline)
+	}
+	if float64(renewDeadline) <= kleaderelection.JitterFactor*float64(retryPeriod) {
+		return fmt.Errorf("RenewDeadline (%s) must be greater than %.1f times RetryPeriod (%s)", renewDeadline, kleaderelection.JitterFactor, retryPeriod)
+	}
+	return nil
+}
+
 // defaultHealthProbeListener creates the default health probes listener bound to the given address.
 func defaultHealthProbeListener(addr string) (net.Listener, error) {
 	if addr == "" || addr == "0" {
@@ -512,6 +885,19 @@ func defaultHealthProbeListener(addr string) (net.Listener, error) {
 	return ln, nil
 }
 
+// defaultPprofListener creates the default pprof listener bound to the given address.
+func defaultPprofListener(addr string) (net.Listener, error) {
+	if addr == "" || addr == "0" {
+		return nil, nil
+	}
+
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("error listening on %s: %v", addr, err)
+	}
+	return ln, nil
+}
+
 // setOptionsDefaults set default values for Options fields.
 func setOptionsDefaults(options Options) Options {
 	// Allow newResourceLock to be mocked
@@ -530,7 +916,7 @@ func setOptionsDefaults(options Options) Options {
 	if options.EventBroadcaster == nil {
 		// defer initialization to avoid leaking by default
 		options.makeBroadcaster = func() (record.EventBroadcaster, bool) {
-			return record.NewBroadcaster(), true
+			return record.NewBroadcasterWithCorrelatorOptions(options.EventCorrelatorOptions), true
 		}
 	} else {
 		options.makeBroadcaster = func() (record.EventBroadcaster, bool) {
@@ -557,15 +943,22 @@ func setOptionsDefaults(options Options) Options {
 	if options.ReadinessEndpointName == "" {
 		options.ReadinessEndpointName = defaultReadinessEndpoint
 	}
+	// endpoint names are paths of the health probe server
+	options.ReadinessEndpointName = "/" + strings.Trim(options.ReadinessEndpointName, "/")
 
 	if options.LivenessEndpointName == "" {
 		options.LivenessEndpointName = defaultLivenessEndpoint
 	}
+	options.LivenessEndpointName = "/" + strings.Trim(options.LivenessEndpointName, "/")
 
 	if options.newHealthProbeListener == nil {
 		options.newHealthProbeListener = defaultHealthProbeListener
 	}
 
+	if options.newPprofListener == nil {
+		options.newPprofListener = defaultPprofListener
+	}
+
 	if options.GracefulShutdownTimeout == nil {
 		gracefulShutdownTimeout := defaultGracefulShutdownPeriod
 		options.GracefulShutdownTimeout = &gracefulShutdownTimeout
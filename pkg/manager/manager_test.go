@@ -18,6 +18,7 @@ package manager
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -44,12 +45,14 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/cluster"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/config/v1alpha1"
 	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
 	intrec "sigs.k8s.io/controller-runtime/pkg/internal/recorder"
 	"sigs.k8s.io/controller-runtime/pkg/leaderelection"
 	fakeleaderelection "sigs.k8s.io/controller-runtime/pkg/leaderelection/fake"
 	"sigs.k8s.io/controller-runtime/pkg/metrics"
+	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 	"sigs.k8s.io/controller-runtime/pkg/recorder"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
@@ -75,6 +78,56 @@ var _ = Describe("manger.Manager", func() {
 
 		})
 
+		It("should create a webhook-only manager without a config", func() {
+			m, err := New(nil, Options{WebhookOnly: true, MetricsBindAddress: "0"})
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			errChan := make(chan error, 1)
+			go func() {
+				errChan <- m.Start(ctx)
+			}()
+			Eventually(m.Elected()).Should(BeClosed())
+
+			By("failing to read objects from the cluster")
+			err = m.GetClient().Get(ctx, client.ObjectKey{Namespace: "default", Name: "foo"}, &corev1.Pod{})
+			Expect(meta.IsNoMatchError(err)).To(BeTrue())
+
+			cancel()
+			Eventually(errChan).Should(Receive(BeNil()))
+		})
+
+		It("should return an error if the readiness and liveness endpoints are the same", func() {
+			m, err := New(cfg, Options{ReadinessEndpointName: "probe", LivenessEndpointName: "/probe"})
+			Expect(m).To(BeNil())
+			Expect(err).To(MatchError(ContainSubstring("can't both be served at /probe")))
+		})
+
+		It("should return an error if the panic policy is invalid", func() {
+			m, err := New(cfg, Options{PanicPolicy: "Ignore"})
+			Expect(m).To(BeNil())
+			Expect(err).To(MatchError(ContainSubstring(`invalid panic policy "Ignore"`)))
+		})
+
+		It("should make the controllers recover panics with the Recover panic policy", func() {
+			m, err := New(cfg, Options{PanicPolicy: PanicPolicyRecover})
+			Expect(err).NotTo(HaveOccurred())
+			recoverPanic := m.GetControllerOptions().RecoverPanic
+			Expect(recoverPanic).NotTo(BeNil())
+			Expect(*recoverPanic).To(BeTrue())
+		})
+
+		It("should return an error if leader election is enabled in webhook-only mode", func() {
+			m, err := New(nil, Options{
+				WebhookOnly:      true,
+				LeaderElection:   true,
+				LeaderElectionID: "test-leader-election-id",
+			})
+			Expect(m).To(BeNil())
+			Expect(err).To(MatchError(ContainSubstring("webhook-only")))
+		})
+
 		It("should return an error it can't create a client.Client", func(done Done) {
 			m, err := New(cfg, Options{
 				NewClient: func(cache cache.Cache, config *rest.Config, options client.Options, uncachedObjects ...client.Object) (client.Client, error) {
@@ -116,7 +169,7 @@ var _ = Describe("manger.Manager", func() {
 
 		It("should return an error it can't create a recorder.Provider", func(done Done) {
 			m, err := New(cfg, Options{
-				newRecorderProvider: func(_ *rest.Config, _ *runtime.Scheme, _ logr.Logger, _ intrec.EventBroadcasterProducer) (*intrec.Provider, error) {
+				newRecorderProvider: func(_ *rest.Config, _ *runtime.Scheme, _ logr.Logger, _ intrec.EventBroadcasterProducer, _ intrec.Options) (*intrec.Provider, error) {
 					return nil, fmt.Errorf("expected error")
 				},
 			})
@@ -144,7 +197,8 @@ var _ = Describe("manger.Manager", func() {
 						RenewDeadline:     duration,
 						RetryPeriod:       duration,
 					},
-					CacheNamespace: "default",
+					CacheNamespace:          "default",
+					GracefulShutdownTimeout: &duration,
 					Metrics: v1alpha1.ControllerMetrics{
 						BindAddress: ":6000",
 					},
@@ -173,6 +227,7 @@ var _ = Describe("manger.Manager", func() {
 			Expect(m.RenewDeadline.String()).To(Equal(duration.Duration.String()))
 			Expect(m.RetryPeriod.String()).To(Equal(duration.Duration.String()))
 			Expect(m.Namespace).To(Equal("default"))
+			Expect(*m.GracefulShutdownTimeout).To(Equal(duration.Duration))
 			Expect(m.MetricsBindAddress).To(Equal(":6000"))
 			Expect(m.HealthProbeBindAddress).To(Equal("6060"))
 			Expect(m.ReadinessEndpointName).To(Equal("/readyz"))
@@ -202,7 +257,8 @@ var _ = Describe("manger.Manager", func() {
 						RenewDeadline:     duration,
 						RetryPeriod:       duration,
 					},
-					CacheNamespace: "default",
+					CacheNamespace:          "default",
+					GracefulShutdownTimeout: &duration,
 					Metrics: v1alpha1.ControllerMetrics{
 						BindAddress: ":6000",
 					},
@@ -229,6 +285,7 @@ var _ = Describe("manger.Manager", func() {
 				RenewDeadline:              &optDuration,
 				RetryPeriod:                &optDuration,
 				Namespace:                  "ctrl",
+				GracefulShutdownTimeout:    &optDuration,
 				MetricsBindAddress:         ":7000",
 				HealthProbeBindAddress:     "5000",
 				ReadinessEndpointName:      "/readiness",
@@ -248,6 +305,7 @@ var _ = Describe("manger.Manager", func() {
 			Expect(m.RenewDeadline.String()).To(Equal(optDuration.String()))
 			Expect(m.RetryPeriod.String()).To(Equal(optDuration.String()))
 			Expect(m.Namespace).To(Equal("ctrl"))
+			Expect(m.GracefulShutdownTimeout.String()).To(Equal(optDuration.String()))
 			Expect(m.MetricsBindAddress).To(Equal(":7000"))
 			Expect(m.HealthProbeBindAddress).To(Equal("5000"))
 			Expect(m.ReadinessEndpointName).To(Equal("/readiness"))
@@ -261,7 +319,15 @@ var _ = Describe("manger.Manager", func() {
 
 		It("should lazily initialize a webhook server if needed", func(done Done) {
 			By("creating a manager with options")
-			m, err := New(cfg, Options{Port: 9440, Host: "foo.com"})
+			m, err := New(cfg, Options{
+				Port:          9440,
+				Host:          "foo.com",
+				CertDir:       "/certs",
+				CertName:      "server.crt",
+				KeyName:       "server.key",
+				ClientCAName:  "ca.crt",
+				TLSMinVersion: "1.2",
+			})
 			Expect(err).NotTo(HaveOccurred())
 			Expect(m).NotTo(BeNil())
 
@@ -270,6 +336,11 @@ var _ = Describe("manger.Manager", func() {
 			Expect(svr).NotTo(BeNil())
 			Expect(svr.Port).To(Equal(9440))
 			Expect(svr.Host).To(Equal("foo.com"))
+			Expect(svr.CertDir).To(Equal("/certs"))
+			Expect(svr.CertName).To(Equal("server.crt"))
+			Expect(svr.KeyName).To(Equal("server.key"))
+			Expect(svr.ClientCAName).To(Equal("ca.crt"))
+			Expect(svr.TLSMinVersion).To(Equal("1.2"))
 
 			close(done)
 		})
@@ -451,29 +522,66 @@ var _ = Describe("manger.Manager", func() {
 				Expect(m).To(BeNil())
 				Expect(err).To(MatchError(ContainSubstring("expected error")))
 			})
+			It("should return an error if the leader election timings are invalid", func() {
+				leaseDuration, renewDeadline, retryPeriod := 10*time.Second, 10*time.Second, 2*time.Second
+				m, err := New(cfg, Options{
+					LeaderElection:          true,
+					LeaderElectionID:        "controller-runtime",
+					LeaderElectionNamespace: "my-ns",
+					LeaseDuration:           &leaseDuration,
+					RenewDeadline:           &renewDeadline,
+					RetryPeriod:             &retryPeriod,
+				})
+				Expect(m).To(BeNil())
+				Expect(err).To(MatchError(ContainSubstring("LeaseDuration (10s) must be greater than RenewDeadline (10s)")))
+
+				leaseDuration, renewDeadline, retryPeriod = 15*time.Second, 2*time.Second, 2*time.Second
+				m, err = New(cfg, Options{
+					LeaderElection:          true,
+					LeaderElectionID:        "controller-runtime",
+					LeaderElectionNamespace: "my-ns",
+					LeaseDuration:           &leaseDuration,
+					RenewDeadline:           &renewDeadline,
+					RetryPeriod:             &retryPeriod,
+				})
+				Expect(m).To(BeNil())
+				Expect(err).To(MatchError(ContainSubstring("RenewDeadline (2s) must be greater than 1.2 times RetryPeriod (2s)")))
+			})
 			It("should return an error if namespace not set and not running in cluster", func() {
 				m, err := New(cfg, Options{LeaderElection: true, LeaderElectionID: "controller-runtime"})
 				Expect(m).To(BeNil())
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("unable to find leader election namespace: not running in-cluster, please specify LeaderElectionNamespace"))
+				var detectionErr *leaderelection.NamespaceDetectionError
+				Expect(errors.As(err, &detectionErr)).To(BeTrue())
 			})
 
-			// We must keep this default until we are sure all controller-runtime users have upgraded from the original default
-			// ConfigMap lock to a controller-runtime version that has this new default. Many users of controller-runtime skip
-			// versions, so we should be extremely conservative here.
-			It("should default to ConfigMapsLeasesResourceLock", func() {
+			It("should default to LeasesResourceLock", func() {
 				m, err := New(cfg, Options{LeaderElection: true, LeaderElectionID: "controller-runtime", LeaderElectionNamespace: "my-ns"})
 				Expect(m).ToNot(BeNil())
 				Expect(err).ToNot(HaveOccurred())
 				cm, ok := m.(*controllerManager)
 				Expect(ok).To(BeTrue())
+				_, isLeaseLock := cm.resourceLock.(*resourcelock.LeaseLock)
+				Expect(isLeaseLock).To(BeTrue())
+			})
+			It("should support the ConfigMapsLeasesResourceLock migration lock", func() {
+				m, err := New(cfg, Options{
+					LeaderElection:             true,
+					LeaderElectionResourceLock: resourcelock.ConfigMapsLeasesResourceLock,
+					LeaderElectionID:           "controller-runtime",
+					LeaderElectionNamespace:    "my-ns",
+				})
+				Expect(m).ToNot(BeNil())
+				Expect(err).ToNot(HaveOccurred())
+				cm, ok := m.(*controllerManager)
+				Expect(ok).To(BeTrue())
 				multilock, isMultiLock := cm.resourceLock.(*resourcelock.MultiLock)
 				Expect(isMultiLock).To(BeTrue())
 				_, primaryIsConfigMapLock := multilock.Primary.(*resourcelock.ConfigMapLock)
 				Expect(primaryIsConfigMapLock).To(BeTrue())
 				_, secondaryIsLeaseLock := multilock.Secondary.(*resourcelock.LeaseLock)
 				Expect(secondaryIsLeaseLock).To(BeTrue())
-
 			})
 			It("should use the specified ResourceLock", func() {
 				m, err := New(cfg, Options{
@@ -672,7 +780,7 @@ var _ = Describe("manger.Manager", func() {
 				}
 				mgr, ok := m.(*controllerManager)
 				Expect(ok).To(BeTrue())
-				mgr.caches = []hasCache{&cacheProvider{cache: &informertest.FakeInformers{Error: fmt.Errorf("expected error")}}}
+				mgr.caches = []Runnable{&cacheProvider{cache: &informertest.FakeInformers{Error: fmt.Errorf("expected error")}}}
 
 				ctx, cancel := context.WithCancel(context.Background())
 				defer cancel()
@@ -681,6 +789,26 @@ var _ = Describe("manger.Manager", func() {
 				close(done)
 			})
 
+			It("should return an error if the cache doesn't sync within the timeout", func(done Done) {
+				opts := options
+				opts.CacheSyncTimeout = 10 * time.Millisecond
+				m, err := New(cfg, opts)
+				Expect(err).NotTo(HaveOccurred())
+				for _, cb := range callbacks {
+					cb(m)
+				}
+				mgr, ok := m.(*controllerManager)
+				Expect(ok).To(BeTrue())
+				synced := false
+				mgr.caches = []Runnable{&cacheProvider{cache: &informertest.FakeInformers{Synced: &synced}}}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				Expect(m.Start(ctx)).To(MatchError(ContainSubstring("timed out after 10ms waiting for caches to sync")))
+
+				close(done)
+			})
+
 			It("should start the cache before starting anything else", func(done Done) {
 				fakeCache := &startSignalingInformer{Cache: &informertest.FakeInformers{}}
 				options.NewCache = func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
@@ -713,6 +841,90 @@ var _ = Describe("manger.Manager", func() {
 				close(done)
 			})
 
+			It("should run the hooks of each phase in order", func(done Done) {
+				fakeCache := &startSignalingInformer{Cache: &informertest.FakeInformers{}}
+				options.NewCache = func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
+					return fakeCache, nil
+				}
+				m, err := New(cfg, options)
+				Expect(err).NotTo(HaveOccurred())
+				for _, cb := range callbacks {
+					cb(m)
+				}
+
+				hooks, ok := m.(HookAdder)
+				Expect(ok).To(BeTrue())
+
+				var mu sync.Mutex
+				var calls []string
+				record := func(call string) Hook {
+					return func(context.Context) error {
+						mu.Lock()
+						defer mu.Unlock()
+						calls = append(calls, call)
+						return nil
+					}
+				}
+				Expect(hooks.AddHook(AfterLeaderElection, record("after-leader-election"))).To(Succeed())
+				Expect(hooks.AddHook(BeforeRunnables, func(ctx context.Context) error {
+					if !fakeCache.wasSynced {
+						return errors.New("hook got run before cache was synced")
+					}
+					return record("before-runnables")(ctx)
+				})).To(Succeed())
+				Expect(hooks.AddHook(BeforeCaches, func(ctx context.Context) error {
+					if fakeCache.started() {
+						return errors.New("hook got run after cache was started")
+					}
+					return record("before-caches-1")(ctx)
+				})).To(Succeed())
+				Expect(hooks.AddHook(BeforeCaches, record("before-caches-2"))).To(Succeed())
+				Expect(hooks.AddHook(BeforeWebhooks, record("before-webhooks"))).To(Succeed())
+				Expect(hooks.AddHook(Phase("Unknown"), record("unknown"))).NotTo(Succeed())
+
+				runnableWasStarted := make(chan struct{})
+				Expect(m.Add(RunnableFunc(func(ctx context.Context) error {
+					close(runnableWasStarted)
+					return nil
+				}))).To(Succeed())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(m.Start(ctx)).ToNot(HaveOccurred())
+				}()
+
+				<-runnableWasStarted
+				mu.Lock()
+				defer mu.Unlock()
+				Expect(calls).To(Equal([]string{"before-webhooks", "before-caches-1", "before-caches-2", "before-runnables", "after-leader-election"}))
+				Expect(hooks.AddHook(BeforeCaches, record("late"))).NotTo(Succeed())
+				close(done)
+			})
+
+			It("should return an error if a hook fails", func(done Done) {
+				m, err := New(cfg, options)
+				Expect(err).NotTo(HaveOccurred())
+				for _, cb := range callbacks {
+					cb(m)
+				}
+				Expect(m.(HookAdder).AddHook(BeforeRunnables, func(context.Context) error {
+					return errors.New("expected error")
+				})).To(Succeed())
+				Expect(m.Add(RunnableFunc(func(ctx context.Context) error {
+					defer GinkgoRecover()
+					Fail("runnable got started after a failing hook")
+					return nil
+				}))).To(Succeed())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				Expect(m.Start(ctx)).To(MatchError(ContainSubstring("expected error")))
+
+				close(done)
+			})
+
 			It("should start additional clusters before anything else", func(done Done) {
 				fakeCache := &startSignalingInformer{Cache: &informertest.FakeInformers{}}
 				options.NewCache = func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
@@ -757,6 +969,68 @@ var _ = Describe("manger.Manager", func() {
 				close(done)
 			})
 
+			It("should start additional clusters added after Start", func(done Done) {
+				m, err := New(cfg, options)
+				Expect(err).NotTo(HaveOccurred())
+				for _, cb := range callbacks {
+					cb(m)
+				}
+
+				runnableWasStarted := make(chan struct{})
+				Expect(m.Add(RunnableFunc(func(ctx context.Context) error {
+					close(runnableWasStarted)
+					return nil
+				}))).To(Succeed())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(m.Start(ctx)).ToNot(HaveOccurred())
+				}()
+				<-runnableWasStarted
+
+				additionalClusterCache := &startSignalingInformer{Cache: &informertest.FakeInformers{}}
+				additionalCluster, err := cluster.New(cfg, func(o *cluster.Options) {
+					o.NewCache = func(_ *rest.Config, _ cache.Options) (cache.Cache, error) {
+						return additionalClusterCache, nil
+					}
+				})
+				Expect(err).NotTo(HaveOccurred())
+				Expect(m.Add(additionalCluster)).NotTo(HaveOccurred())
+
+				Eventually(additionalClusterCache.started).Should(BeTrue())
+				close(done)
+			})
+
+			It("should stop and remove runnables", func(done Done) {
+				m, err := New(cfg, options)
+				Expect(err).NotTo(HaveOccurred())
+				for _, cb := range callbacks {
+					cb(m)
+				}
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				managerStopped := make(chan struct{})
+				go func() {
+					defer GinkgoRecover()
+					Expect(m.Start(ctx)).ToNot(HaveOccurred())
+					close(managerStopped)
+				}()
+
+				r := &removableRunnable{started: make(chan struct{})}
+				Expect(m.Add(r)).To(Succeed())
+				<-r.started
+
+				Expect(m.(RunnableRemover).Remove(r)).To(Succeed())
+				Expect(r.stopped).To(BeTrue())
+				Expect(m.(RunnableRemover).Remove(r)).NotTo(Succeed())
+				Expect(m.(RunnableRemover).Remove(RunnableFunc(func(context.Context) error { return nil }))).NotTo(Succeed())
+				Consistently(managerStopped).ShouldNot(BeClosed())
+				close(done)
+			})
+
 			It("should return an error if any Components fail to Start", func(done Done) {
 				m, err := New(cfg, options)
 				Expect(err).NotTo(HaveOccurred())
@@ -1216,6 +1490,122 @@ var _ = Describe("manger.Manager", func() {
 				Expect(err).NotTo(HaveOccurred())
 				Expect(string(body)).To(Equal("Some debug info"))
 			})
+
+			It("should serve metrics over HTTPS when serving securely", func(done Done) {
+				opts.MetricsBindAddress = ":0"
+				opts.MetricsSecureServing = true
+				m, err := New(cfg, opts)
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(m.Start(ctx)).NotTo(HaveOccurred())
+					close(done)
+				}()
+
+				client := &http.Client{Transport: &http.Transport{
+					// the certificate is self-signed
+					TLSClientConfig: &tls.Config{InsecureSkipVerify: true}, //nolint:gosec
+				}}
+				metricsEndpoint := fmt.Sprintf("https://%s/metrics", listener.Addr().String())
+				resp, err := client.Get(metricsEndpoint)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusOK))
+			})
+
+			It("should filter the requests with the filter of the provider", func(done Done) {
+				opts.MetricsBindAddress = ":0"
+				opts.MetricsFilterProvider = func(*rest.Config) (filters.Filter, error) {
+					return func(http.Handler) http.Handler {
+						return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+							http.Error(w, "Forbidden", http.StatusForbidden)
+						})
+					}, nil
+				}
+				m, err := New(cfg, opts)
+				Expect(err).NotTo(HaveOccurred())
+
+				ctx, cancel := context.WithCancel(context.Background())
+				defer cancel()
+				go func() {
+					defer GinkgoRecover()
+					Expect(m.Start(ctx)).NotTo(HaveOccurred())
+					close(done)
+				}()
+
+				metricsEndpoint := fmt.Sprintf("http://%s/metrics", listener.Addr().String())
+				resp, err := http.Get(metricsEndpoint)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(http.StatusForbidden))
+			})
+
+			It("should return an error if the filter provider fails", func() {
+				opts.MetricsBindAddress = ":0"
+				opts.MetricsFilterProvider = func(*rest.Config) (filters.Filter, error) {
+					return nil, fmt.Errorf("expected error")
+				}
+				_, err := New(cfg, opts)
+				Expect(err).To(MatchError(ContainSubstring("expected error")))
+			})
+		})
+	})
+
+	Context("should start serving pprof", func() {
+		var listener net.Listener
+		var opts Options
+
+		BeforeEach(func() {
+			listener = nil
+			opts = Options{
+				newPprofListener: func(addr string) (net.Listener, error) {
+					var err error
+					listener, err = defaultPprofListener(addr)
+					return listener, err
+				},
+			}
+		})
+
+		AfterEach(func() {
+			if listener != nil {
+				listener.Close()
+			}
+		})
+
+		It("should not serve pprof by default", func() {
+			_, err := New(cfg, opts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(listener).To(BeNil())
+		})
+
+		It("should serve pprof endpoints", func(done Done) {
+			opts.PprofBindAddress = ":0"
+			m, err := New(cfg, opts)
+			Expect(err).NotTo(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).NotTo(HaveOccurred())
+				close(done)
+			}()
+
+			endpoint := fmt.Sprintf("http://%s/debug/pprof/", listener.Addr().String())
+			Eventually(func() int {
+				resp, err := http.Get(endpoint)
+				if err != nil {
+					return 0
+				}
+				defer resp.Body.Close()
+				return resp.StatusCode
+			}).Should(Equal(http.StatusOK))
+
+			resp, err := http.Get(fmt.Sprintf("http://%s/debug/pprof/cmdline", listener.Addr().String()))
+			Expect(err).NotTo(HaveOccurred())
+			defer resp.Body.Close()
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
 		})
 	})
 
@@ -1368,6 +1758,122 @@ var _ = Describe("manger.Manager", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(resp.StatusCode).To(Equal(http.StatusOK))
 		})
+
+		It("should serve extra endpoints", func(done Done) {
+			opts.HealthProbeBindAddress = ":0"
+			m, err := New(cfg, opts)
+			Expect(err).NotTo(HaveOccurred())
+			probes, ok := m.(HealthProbeExtraHandlerAdder)
+			Expect(ok).To(BeTrue())
+
+			err = probes.AddHealthProbeExtraHandler("/features", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte("Some feature state"))
+			}))
+			Expect(err).NotTo(HaveOccurred())
+
+			// Should error when we add another extra endpoint on the already registered path.
+			err = probes.AddHealthProbeExtraHandler("/features", http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+				_, _ = w.Write([]byte("Another feature state"))
+			}))
+			Expect(err).To(HaveOccurred())
+
+			// Should error when we override the builtin endpoints.
+			err = probes.AddHealthProbeExtraHandler(defaultLivenessEndpoint, http.NotFoundHandler())
+			Expect(err).To(HaveOccurred())
+			err = probes.AddHealthProbeExtraHandler(defaultReadinessEndpoint+"/check", http.NotFoundHandler())
+			Expect(err).To(HaveOccurred())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).NotTo(HaveOccurred())
+				close(done)
+			}()
+
+			endpoint := fmt.Sprintf("http://%s/features", listener.Addr().String())
+			resp, err := http.Get(endpoint)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(resp.StatusCode).To(Equal(http.StatusOK))
+
+			body, err := ioutil.ReadAll(resp.Body)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(body)).To(Equal("Some feature state"))
+		})
+
+		It("should serve health probes while not elected", func(done Done) {
+			opts.HealthProbeBindAddress = ":0"
+			opts.LeaderElection = true
+			opts.LeaderElectionNamespace = "default"
+			opts.LeaderElectionID = "test-leader-election-id"
+			opts.newResourceLock = func(config *rest.Config, recorderProvider recorder.Provider, options leaderelection.Options) (resourcelock.Interface, error) {
+				rl, err := fakeleaderelection.NewResourceLock(config, recorderProvider, options)
+				if err != nil {
+					return nil, err
+				}
+				// the lock is held by another manager
+				return rl, rl.Update(context.Background(), resourcelock.LeaderElectionRecord{
+					HolderIdentity:       "other-manager",
+					LeaseDurationSeconds: 3600,
+					AcquireTime:          metav1.Now(),
+					RenewTime:            metav1.Now(),
+				})
+			}
+			m, err := New(cfg, opts)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(m.AddReadyzCheck("ping", healthz.Ping)).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).NotTo(HaveOccurred())
+				close(done)
+			}()
+
+			readinessEndpoint := fmt.Sprint("http://", listener.Addr().String(), defaultReadinessEndpoint)
+			Eventually(func() (int, error) {
+				resp, err := http.Get(readinessEndpoint)
+				if err != nil {
+					return 0, err
+				}
+				return resp.StatusCode, nil
+			}).Should(Equal(http.StatusOK))
+			Consistently(m.Elected()).ShouldNot(BeClosed())
+		})
+
+		It("should serve the probes at custom endpoint names", func(done Done) {
+			opts.HealthProbeBindAddress = ":0"
+			opts.ReadinessEndpointName = "ready"
+			opts.LivenessEndpointName = "/live/"
+			m, err := New(cfg, opts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(m.AddReadyzCheck("first", healthz.Ping)).To(Succeed())
+			Expect(m.AddReadyzCheck("second", func(_ *http.Request) error { return fmt.Errorf("not ready") })).To(Succeed())
+			Expect(m.AddHealthzCheck("ping", healthz.Ping)).To(Succeed())
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+			go func() {
+				defer GinkgoRecover()
+				Expect(m.Start(ctx)).NotTo(HaveOccurred())
+				close(done)
+			}()
+
+			endpoint := fmt.Sprint("http://", listener.Addr().String())
+			for path, status := range map[string]int{
+				"/ready":        http.StatusInternalServerError,
+				"/ready/first":  http.StatusOK,
+				"/ready/second": http.StatusInternalServerError,
+				"/live":         http.StatusOK,
+				"/live/ping":    http.StatusOK,
+			} {
+				resp, err := http.Get(endpoint + path)
+				Expect(err).NotTo(HaveOccurred())
+				Expect(resp.StatusCode).To(Equal(status), path)
+			}
+		})
 	})
 
 	Describe("Add", func() {
@@ -1449,6 +1955,23 @@ var _ = Describe("manger.Manager", func() {
 			Expect(err).NotTo(HaveOccurred())
 			Expect(m.Add(&failRec{})).To(HaveOccurred())
 		})
+
+		It("should fail if a runnable with the same unique name was added", func() {
+			m, err := New(cfg, Options{})
+			Expect(err).NotTo(HaveOccurred())
+			first := &namedRunnable{name: "foo"}
+			Expect(m.Add(first)).To(Succeed())
+			Expect(m.Add(&namedRunnable{name: "foo"})).To(MatchError(ContainSubstring(`a runnable named "foo" was already added`)))
+			Expect(m.Add(&namedRunnable{name: "bar"})).To(Succeed())
+
+			By("accepting runnables without a name")
+			Expect(m.Add(&namedRunnable{})).To(Succeed())
+			Expect(m.Add(&namedRunnable{})).To(Succeed())
+
+			By("releasing the name of removed runnables")
+			Expect(m.(RunnableRemover).Remove(first)).To(Succeed())
+			Expect(m.Add(&namedRunnable{name: "foo"})).To(Succeed())
+		})
 	})
 	Describe("SetFields", func() {
 		It("should inject field values", func(done Done) {
@@ -1633,6 +2156,76 @@ var _ = Describe("manger.Manager", func() {
 		Expect(m.GetScheme()).To(Equal(mgr.cluster.GetScheme()))
 	})
 
+	Describe("restart policies", func() {
+		var cm *controllerManager
+		var calls int
+		failOnce := RunnableFunc(func(context.Context) error {
+			calls++
+			if calls == 1 {
+				return errors.New("expected error")
+			}
+			return nil
+		})
+
+		BeforeEach(func() {
+			cm = &controllerManager{logger: logf.RuntimeLog}
+			calls = 0
+		})
+
+		It("should return the error of runnables by default", func() {
+			Expect(cm.runWithRestartPolicy(context.Background(), failOnce)).To(MatchError("expected error"))
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should ignore the error of runnables with the Ignore policy", func() {
+			r := WithRestartPolicy(failOnce, RestartPolicyIgnore)
+			Expect(cm.runWithRestartPolicy(context.Background(), r)).To(Succeed())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should restart runnables with the OnFailure policy", func() {
+			r := WithRestartPolicy(failOnce, RestartPolicyOnFailure)
+			Expect(cm.runWithRestartPolicy(context.Background(), r)).To(Succeed())
+			Expect(calls).To(Equal(2))
+		})
+
+		It("should leave panicking runnables stopped with the Recover panic policy", func() {
+			cm.panicPolicy = PanicPolicyRecover
+			panicking := RunnableFunc(func(context.Context) error {
+				calls++
+				panic("expected panic")
+			})
+			Expect(cm.runWithRestartPolicy(context.Background(), panicking)).To(Succeed())
+			Expect(calls).To(Equal(1))
+		})
+
+		It("should restart panicking runnables with the Recover panic policy and the OnFailure policy", func() {
+			cm.panicPolicy = PanicPolicyRecover
+			r := WithRestartPolicy(RunnableFunc(func(context.Context) error {
+				calls++
+				if calls == 1 {
+					panic("expected panic")
+				}
+				return nil
+			}), RestartPolicyOnFailure)
+			Expect(cm.runWithRestartPolicy(context.Background(), r)).To(Succeed())
+			Expect(calls).To(Equal(2))
+		})
+
+		It("should forward NeedLeaderElection", func() {
+			Expect(WithRestartPolicy(failOnce, RestartPolicyOnFailure).(LeaderElectionRunnable).NeedLeaderElection()).To(BeTrue())
+			Expect(WithRestartPolicy(&webhook.Server{}, RestartPolicyOnFailure).(LeaderElectionRunnable).NeedLeaderElection()).To(BeFalse())
+		})
+
+		It("should classify wrapped runnables by their own type", func() {
+			c := &cacheProvider{cache: &informertest.FakeInformers{}}
+			Expect(unwrapRunnable(WithRestartPolicy(c, RestartPolicyOnFailure))).To(BeIdenticalTo(c))
+			server := &webhook.Server{}
+			Expect(unwrapRunnable(WithRestartPolicy(server, RestartPolicyOnFailure))).To(BeIdenticalTo(server))
+			Expect(unwrapRunnable(server)).To(BeIdenticalTo(server))
+		})
+	})
+
 	It("should provide a function to get the FieldIndexer", func() {
 		m, err := New(cfg, Options{})
 		Expect(err).NotTo(HaveOccurred())
@@ -1651,8 +2244,70 @@ var _ = Describe("manger.Manager", func() {
 		Expect(err).NotTo(HaveOccurred())
 		Expect(m.GetAPIReader()).NotTo(BeNil())
 	})
+
+	It("should warm up the leader election runnables while not elected", func() {
+		m, err := New(cfg, Options{
+			LeaderElection:          true,
+			LeaderElectionNamespace: "default",
+			LeaderElectionID:        "test-leader-election-id",
+			newResourceLock: func(config *rest.Config, recorderProvider recorder.Provider, options leaderelection.Options) (resourcelock.Interface, error) {
+				rl, err := fakeleaderelection.NewResourceLock(config, recorderProvider, options)
+				if err != nil {
+					return nil, err
+				}
+				// the lock is held by another manager
+				return rl, rl.Update(context.Background(), resourcelock.LeaderElectionRecord{
+					HolderIdentity:       "other-manager",
+					LeaseDurationSeconds: 3600,
+					AcquireTime:          metav1.Now(),
+					RenewTime:            metav1.Now(),
+				})
+			},
+		})
+		Expect(err).NotTo(HaveOccurred())
+		r := &warmupRunnable{warmedUp: make(chan struct{})}
+		Expect(m.Add(r)).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go func() {
+			defer GinkgoRecover()
+			Expect(m.Start(ctx)).NotTo(HaveOccurred())
+		}()
+
+		Eventually(r.warmedUp).Should(BeClosed())
+		Consistently(m.Elected()).ShouldNot(BeClosed())
+	})
 })
 
+type namedRunnable struct {
+	name string
+}
+
+func (r *namedRunnable) UniqueName() string {
+	return r.name
+}
+
+func (r *namedRunnable) Start(ctx context.Context) error {
+	<-ctx.Done()
+	return nil
+}
+
+type warmupRunnable struct {
+	warmedUp chan struct{}
+}
+
+func (r *warmupRunnable) Warmup(context.Context) error {
+	close(r.warmedUp)
+	return nil
+}
+
+func (r *warmupRunnable) Start(ctx context.Context) error {
+	defer GinkgoRecover()
+	Fail("the runnable should not be started")
+	return nil
+}
+
 var _ reconcile.Reconciler = &failRec{}
 var _ inject.Client = &failRec{}
 
@@ -1762,6 +2417,20 @@ func (f *fakeDeferredLoader) InjectScheme(scheme *runtime.Scheme) error {
 
 var _ Runnable = &cacheProvider{}
 
+// removableRunnable returns an error once stopped, which must not stop the
+// manager when the runnable is removed.
+type removableRunnable struct {
+	started chan struct{}
+	stopped bool
+}
+
+func (r *removableRunnable) Start(ctx context.Context) error {
+	close(r.started)
+	<-ctx.Done()
+	r.stopped = true
+	return errors.New("stopped")
+}
+
 type cacheProvider struct {
 	cache cache.Cache
 }
@@ -0,0 +1,36 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// runnablePanics counts the panics of runnables recovered by the manager,
+	// per type of runnable.
+	runnablePanics = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "controller_runtime_runnable_panics_total",
+		Help: "Total number of panics recovered per type of runnable",
+	}, []string{"runnable"})
+)
+
+func init() {
+	metrics.Registry.MustRegister(runnablePanics)
+}
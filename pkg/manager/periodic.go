@@ -0,0 +1,158 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/util/wait"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+)
+
+// PeriodicAdder is implemented by the managers that can add a PeriodicRunnable
+// from a schedule, like the managers created by New.
+type PeriodicAdder interface {
+	// AddPeriodic adds a PeriodicRunnable running fn on the leader until the manager
+	// stops, either at an interval, e.g. "1h", or on a cron schedule, e.g. "0 3 * * *".
+	// Add a PeriodicRunnable directly to set its other fields, e.g. its jitter.
+	AddPeriodic(name, schedule string, fn func(ctx context.Context) error) error
+}
+
+// PeriodicRunnable is a Runnable running a housekeeping task periodically, e.g.
+// a garbage collection or the generation of a report, until the manager stops:
+//
+//  err := mgr.Add(&manager.PeriodicRunnable{
+//    Name:     "garbage-collector",
+//    Interval: time.Hour,
+//    Func:     collectGarbage,
+//  })
+//
+// With an Interval, the task runs once right after the runnable starts, then
+// Interval after the end of each run. With a cron Schedule instead, e.g.
+// "0 3 * * *", it runs at the times of the schedule, in the local time zone.
+// PeriodicAdder.AddPeriodic is a shorthand for either.
+//
+// The task only runs on the leader, unless SkipLeaderElection is set.
+// Errors of the task are logged, and don't prevent the next runs.
+type PeriodicRunnable struct {
+	// Name identifies the task in the logs.
+	Name string
+
+	// Func is the task, it must return once ctx is done.
+	Func func(ctx context.Context) error
+
+	// Interval is the time between the end of a run and the start of the next one.
+	Interval time.Duration
+
+	// Schedule is a cron expression of five fields (minute, hour, day of month,
+	// month and day of week) or a macro like @daily, used instead of Interval.
+	// Runs which would start while the previous one is still running are skipped.
+	Schedule string
+
+	// JitterFactor, if positive, adds a random duration of up to JitterFactor times
+	// the interval, or the time between two runs of the schedule, to the wait for
+	// every run, so that the tasks of several managers don't run at the same time.
+	JitterFactor float64
+
+	// SkipLeaderElection makes the task run on all the managers, not only on the leader.
+	SkipLeaderElection bool
+
+	log logr.Logger
+}
+
+var _ LeaderElectionRunnable = &PeriodicRunnable{}
+
+// Start implements Runnable.
+func (p *PeriodicRunnable) Start(ctx context.Context) error {
+	schedule, err := p.validate()
+	if err != nil {
+		return err
+	}
+
+	log := p.log
+	if log == nil {
+		log = logf.RuntimeLog.WithName("periodic")
+	}
+	log = log.WithValues("task", p.Name)
+
+	run := func(ctx context.Context) {
+		log.V(1).Info("Running periodic task")
+		if err := p.Func(ctx); err != nil {
+			log.Error(err, "Periodic task failed")
+		}
+	}
+	if schedule == nil {
+		wait.JitterUntilWithContext(ctx, run, p.Interval, p.JitterFactor, true)
+		return nil
+	}
+
+	for {
+		next := schedule.next(time.Now())
+		if next.IsZero() {
+			return fmt.Errorf("the schedule %q of the periodic runnable never runs", p.Schedule)
+		}
+		delay := time.Until(next)
+		if p.JitterFactor > 0 {
+			if following := schedule.next(next); !following.IsZero() {
+				delay += time.Duration(rand.Float64() * p.JitterFactor * float64(following.Sub(next))) //nolint:gosec
+			}
+		}
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil
+		case <-timer.C:
+		}
+		run(ctx)
+	}
+}
+
+// validate checks the runnable, and returns its parsed Schedule if any.
+func (p *PeriodicRunnable) validate() (*cronSchedule, error) {
+	if p.Func == nil {
+		return nil, errors.New("must specify Func of the periodic runnable")
+	}
+	if p.Schedule == "" {
+		if p.Interval <= 0 {
+			return nil, errors.New("the Interval of the periodic runnable must be positive")
+		}
+		return nil, nil
+	}
+	if p.Interval != 0 {
+		return nil, errors.New("only one of the Interval and the Schedule of the periodic runnable can be set")
+	}
+	return parseCron(p.Schedule)
+}
+
+// NeedLeaderElection implements LeaderElectionRunnable.
+func (p *PeriodicRunnable) NeedLeaderElection() bool {
+	return !p.SkipLeaderElection
+}
+
+// InjectLogger implements inject.Logger, the manager injects its logger.
+func (p *PeriodicRunnable) InjectLogger(l logr.Logger) error {
+	p.log = l.WithName("periodic")
+	return nil
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package manager
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+)
+
+var _ = Describe("PeriodicRunnable", func() {
+	It("should run the task periodically until the context is done", func() {
+		var runs int32
+		p := &PeriodicRunnable{
+			Name:     "test",
+			Interval: 10 * time.Millisecond,
+			Func: func(context.Context) error {
+				atomic.AddInt32(&runs, 1)
+				return fmt.Errorf("expected error")
+			},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- p.Start(ctx)
+		}()
+
+		By("running again after a failure")
+		Eventually(func() int32 { return atomic.LoadInt32(&runs) }).Should(BeNumerically(">=", 3))
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+		stoppedRuns := atomic.LoadInt32(&runs)
+		Consistently(func() int32 { return atomic.LoadInt32(&runs) }).Should(Equal(stoppedRuns))
+	})
+
+	It("should need leader election unless skipped", func() {
+		Expect((&PeriodicRunnable{}).NeedLeaderElection()).To(BeTrue())
+		Expect((&PeriodicRunnable{SkipLeaderElection: true}).NeedLeaderElection()).To(BeFalse())
+	})
+
+	It("should return an error if the interval isn't positive", func() {
+		p := &PeriodicRunnable{Func: func(context.Context) error { return nil }}
+		Expect(p.Start(context.Background())).To(MatchError(ContainSubstring("must be positive")))
+	})
+
+	It("should run the task on its cron schedule", func() {
+		runs := make(chan struct{}, 1)
+		p := &PeriodicRunnable{
+			Name:     "test",
+			Schedule: "* * * * *",
+			Func: func(context.Context) error {
+				runs <- struct{}{}
+				return nil
+			},
+		}
+
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error)
+		go func() {
+			done <- p.Start(ctx)
+		}()
+
+		By("not running right away")
+		Consistently(runs, "100ms").ShouldNot(Receive())
+
+		cancel()
+		Eventually(done).Should(Receive(BeNil()))
+	})
+
+	It("should return an error if the schedule is invalid", func() {
+		p := &PeriodicRunnable{Schedule: "* * *", Func: func(context.Context) error { return nil }}
+		Expect(p.Start(context.Background())).To(MatchError(ContainSubstring("expected 5 fields")))
+	})
+
+	It("should return an error if both the interval and the schedule are set", func() {
+		p := &PeriodicRunnable{Interval: time.Hour, Schedule: "@daily", Func: func(context.Context) error { return nil }}
+		Expect(p.Start(context.Background())).To(MatchError(ContainSubstring("only one of")))
+	})
+
+	It("should validate the schedules of AddPeriodic", func() {
+		cm := &controllerManager{}
+		fn := func(context.Context) error { return nil }
+		Expect(cm.AddPeriodic("test", "0 25 * * *", fn)).To(MatchError(ContainSubstring("invalid hour")))
+		Expect(cm.AddPeriodic("test", "-1h", fn)).To(MatchError(ContainSubstring("must be positive")))
+	})
+})
+
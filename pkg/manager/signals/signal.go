@@ -20,22 +20,73 @@ import (
 	"context"
 	"os"
 	"os/signal"
+	"sync"
 )
 
 var onlyOneSignalHandler = make(chan struct{})
 
+// Options configures the shutdown triggered by SetupSignalHandlerWithOptions.
+type Options struct {
+	// Signals are the signals which trigger the shutdown.
+	// Defaults to SIGTERM and SIGINT.
+	Signals []os.Signal
+
+	// ShutdownSources trigger the shutdown when they are closed or receive a value,
+	// like a signal would, e.g. on platforms notifying of an upcoming termination
+	// through their own API, like spot instances.
+	ShutdownSources []<-chan struct{}
+
+	// PreStop is called once the shutdown is triggered, before the returned
+	// context is cancelled, e.g. to fail the readiness probe first.
+	PreStop func()
+}
+
 // SetupSignalHandler registers for SIGTERM and SIGINT. A stop channel is returned
 // which is closed on one of these signals. If a second signal is caught, the program
 // is terminated with exit code 1.
 func SetupSignalHandler() context.Context {
+	return SetupSignalHandlerWithOptions(Options{})
+}
+
+// SetupSignalHandlerWithOptions is like SetupSignalHandler, with custom signals,
+// additional shutdown sources and a pre-stop callback. Only one of them can be
+// called per process.
+func SetupSignalHandlerWithOptions(opts Options) context.Context {
 	close(onlyOneSignalHandler) // panics when called twice
 
+	if len(opts.Signals) == 0 {
+		opts.Signals = shutdownSignals
+	}
+	c := make(chan os.Signal, 2)
+	signal.Notify(c, opts.Signals...)
+	return notifyShutdown(c, opts)
+}
+
+// notifyShutdown returns a context which is cancelled on the first signal of c
+// or shutdown source, and exits the program on the second signal.
+func notifyShutdown(c <-chan os.Signal, opts Options) context.Context {
 	ctx, cancel := context.WithCancel(context.Background())
 
-	c := make(chan os.Signal, 2)
-	signal.Notify(c, shutdownSignals...)
+	sourceTriggered := make(chan struct{})
+	var once sync.Once
+	for _, source := range opts.ShutdownSources {
+		go func(source <-chan struct{}) {
+			select {
+			case <-source:
+				once.Do(func() { close(sourceTriggered) })
+			case <-ctx.Done():
+			}
+		}(source)
+	}
+
 	go func() {
-		<-c
+		select {
+		case <-c:
+		case <-sourceTriggered:
+		}
+		if opts.PreStop != nil {
+			opts.PreStop()
+		}
 		cancel()
 		<-c
 		os.Exit(1) // second signal. Exit directly.
@@ -17,6 +17,7 @@ limitations under the License.
 package signals
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"os/signal"
@@ -54,6 +55,33 @@ var _ = Describe("runtime signal", func() {
 
 	})
 
+	Context("Shutdown sources", func() {
+
+		It("should cancel the context when a shutdown source triggers", func() {
+			c := make(chan os.Signal, 2)
+			source := make(chan struct{})
+			ctx := notifyShutdown(c, Options{ShutdownSources: []<-chan struct{}{make(chan struct{}), source}})
+			Consistently(ctx.Done()).ShouldNot(BeClosed())
+
+			close(source)
+			Eventually(ctx.Done()).Should(BeClosed())
+		})
+
+		It("should call the pre-stop callback before cancelling the context", func() {
+			c := make(chan os.Signal, 2)
+			preStopped := make(chan bool, 1)
+			var ctx context.Context
+			ctx = notifyShutdown(c, Options{PreStop: func() {
+				preStopped <- ctx.Err() == nil
+			}})
+
+			c <- os.Interrupt
+			Eventually(ctx.Done()).Should(BeClosed())
+			Expect(preStopped).To(Receive(BeTrue()))
+		})
+
+	})
+
 })
 
 type Task struct {
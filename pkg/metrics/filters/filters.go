@@ -0,0 +1,208 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/cache"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+
+	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
+)
+
+var log = logf.RuntimeLog.WithName("metrics").WithName("filters")
+
+// Filter wraps the handler of the metrics server, e.g. to authenticate and
+// authorize requests.
+type Filter func(http.Handler) http.Handler
+
+// Provider creates a Filter from the config of the manager.
+type Provider func(config *rest.Config) (Filter, error)
+
+// WithAuthenticationAndAuthorization is a Provider of a Filter which only
+// serves requests with a bearer token that authenticates against the API
+// server through a TokenReview, and whose user is authorized through a
+// SubjectAccessReview to use the verb of the request, e.g. get, on the path
+// of the request, e.g. /metrics. It's the equivalent of kube-rbac-proxy.
+//
+// The results of the reviews are cached for a couple of minutes, or a few
+// seconds for rejected tokens and denied requests, so that scrapes don't
+// create reviews each time.
+//
+// The manager needs RBAC permissions to create TokenReviews and
+// SubjectAccessReviews, and the clients, e.g. Prometheus, need a ClusterRole
+// allowing them to get the nonResourceURLs:
+//
+//  rules:
+//  - nonResourceURLs: ["/metrics"]
+//    verbs: ["get"]
+func WithAuthenticationAndAuthorization(config *rest.Config) (Filter, error) {
+	clientset, err := kubernetes.NewForConfig(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create the client of the authentication filter: %w", err)
+	}
+	return func(handler http.Handler) http.Handler {
+		return newAuthenticatingHandler(handler, clientset)
+	}, nil
+}
+
+const (
+	// reviewCacheSize bounds the number of cached results of each kind of review.
+	reviewCacheSize = 1024
+
+	// allowedTTL is how long authenticated tokens and allowed requests are cached.
+	allowedTTL = 2 * time.Minute
+
+	// deniedTTL is how long rejected tokens and denied requests are cached.
+	deniedTTL = 10 * time.Second
+)
+
+// authenticatingHandler serves the requests authenticated and authorized by the API server.
+type authenticatingHandler struct {
+	handler   http.Handler
+	clientset kubernetes.Interface
+
+	// tokenReviews caches the status of the TokenReviews by the hash of their token.
+	tokenReviews *cache.LRUExpireCache
+
+	// accessReviews caches whether the SubjectAccessReviews are allowed by
+	// the hash of the token, the verb and the path of their request.
+	accessReviews *cache.LRUExpireCache
+}
+
+func newAuthenticatingHandler(handler http.Handler, clientset kubernetes.Interface) *authenticatingHandler {
+	return &authenticatingHandler{
+		handler:       handler,
+		clientset:     clientset,
+		tokenReviews:  cache.NewLRUExpireCache(reviewCacheSize),
+		accessReviews: cache.NewLRUExpireCache(reviewCacheSize),
+	}
+}
+
+func (h *authenticatingHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	token := bearerToken(req)
+	if token == "" {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	tokenHash := sha256.Sum256([]byte(token))
+
+	status, err := h.authenticate(req.Context(), token, tokenHash)
+	if err != nil {
+		log.Error(err, "failed to authenticate request")
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if !status.Authenticated {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	allowed, err := h.authorize(req, status.User, tokenHash)
+	if err != nil {
+		log.Error(err, "failed to authorize request", "user", status.User.Username)
+		http.Error(w, "Internal Server Error", http.StatusInternalServerError)
+		return
+	}
+	if !allowed {
+		http.Error(w, "Forbidden", http.StatusForbidden)
+		return
+	}
+
+	h.handler.ServeHTTP(w, req)
+}
+
+// authenticate returns the status of the TokenReview of token, from the cache if possible.
+func (h *authenticatingHandler) authenticate(ctx context.Context, token string, tokenHash [sha256.Size]byte) (authenticationv1.TokenReviewStatus, error) {
+	if status, ok := h.tokenReviews.Get(tokenHash); ok {
+		return status.(authenticationv1.TokenReviewStatus), nil
+	}
+
+	tokenReview, err := h.clientset.AuthenticationV1().TokenReviews().Create(ctx, &authenticationv1.TokenReview{
+		Spec: authenticationv1.TokenReviewSpec{Token: token},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return authenticationv1.TokenReviewStatus{}, err
+	}
+	ttl := deniedTTL
+	if tokenReview.Status.Authenticated {
+		ttl = allowedTTL
+	}
+	h.tokenReviews.Add(tokenHash, tokenReview.Status, ttl)
+	return tokenReview.Status, nil
+}
+
+// accessReviewKey identifies the cached SubjectAccessReviews, the user is
+// the one of the token.
+type accessReviewKey struct {
+	tokenHash [sha256.Size]byte
+	verb      string
+	path      string
+}
+
+// authorize returns whether user may use the verb of req on its path, from the cache if possible.
+func (h *authenticatingHandler) authorize(req *http.Request, user authenticationv1.UserInfo, tokenHash [sha256.Size]byte) (bool, error) {
+	key := accessReviewKey{tokenHash: tokenHash, verb: strings.ToLower(req.Method), path: req.URL.Path}
+	if allowed, ok := h.accessReviews.Get(key); ok {
+		return allowed.(bool), nil
+	}
+
+	extra := make(map[string]authorizationv1.ExtraValue, len(user.Extra))
+	for key, value := range user.Extra {
+		extra[key] = authorizationv1.ExtraValue(value)
+	}
+	accessReview, err := h.clientset.AuthorizationV1().SubjectAccessReviews().Create(req.Context(), &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User:   user.Username,
+			Groups: user.Groups,
+			UID:    user.UID,
+			Extra:  extra,
+			NonResourceAttributes: &authorizationv1.NonResourceAttributes{
+				Path: key.path,
+				Verb: key.verb,
+			},
+		},
+	}, metav1.CreateOptions{})
+	if err != nil {
+		return false, err
+	}
+	ttl := deniedTTL
+	if accessReview.Status.Allowed {
+		ttl = allowedTTL
+	}
+	h.accessReviews.Add(key, accessReview.Status.Allowed, ttl)
+	return accessReview.Status.Allowed, nil
+}
+
+// bearerToken returns the bearer token of the Authorization header of req, if any.
+func bearerToken(req *http.Request) string {
+	parts := strings.SplitN(req.Header.Get("Authorization"), " ", 2)
+	if len(parts) != 2 || !strings.EqualFold(parts[0], "bearer") {
+		return ""
+	}
+	return strings.TrimSpace(parts[1])
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package filters
+
+import (
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	clienttesting "k8s.io/client-go/testing"
+)
+
+var _ = Describe("WithAuthenticationAndAuthorization", func() {
+	var clientset *fake.Clientset
+	var handler http.Handler
+	var accessReview *authorizationv1.SubjectAccessReview
+
+	BeforeEach(func() {
+		accessReview = nil
+		clientset = fake.NewSimpleClientset()
+		clientset.PrependReactor("create", "tokenreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			review := action.(clienttesting.CreateAction).GetObject().(*authenticationv1.TokenReview)
+			if review.Spec.Token == "valid" {
+				review.Status.Authenticated = true
+				review.Status.User = authenticationv1.UserInfo{Username: "prometheus", Groups: []string{"monitoring"}}
+			}
+			return true, review, nil
+		})
+		clientset.PrependReactor("create", "subjectaccessreviews", func(action clienttesting.Action) (bool, runtime.Object, error) {
+			accessReview = action.(clienttesting.CreateAction).GetObject().(*authorizationv1.SubjectAccessReview)
+			accessReview.Status.Allowed = accessReview.Spec.User == "prometheus" && accessReview.Spec.NonResourceAttributes.Path == "/metrics"
+			return true, accessReview, nil
+		})
+		handler = newAuthenticatingHandler(http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+			_, _ = w.Write([]byte("metrics"))
+		}), clientset)
+	})
+
+	serve := func(path, token string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	It("should reject requests without a token", func() {
+		Expect(serve("/metrics", "").Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject requests with an invalid token", func() {
+		Expect(serve("/metrics", "invalid").Code).To(Equal(http.StatusUnauthorized))
+	})
+
+	It("should reject requests that aren't authorized", func() {
+		Expect(serve("/debug", "valid").Code).To(Equal(http.StatusForbidden))
+	})
+
+	It("should serve requests that are authenticated and authorized", func() {
+		rec := serve("/metrics", "valid")
+		Expect(rec.Code).To(Equal(http.StatusOK))
+		Expect(rec.Body.String()).To(Equal("metrics"))
+
+		Expect(accessReview).NotTo(BeNil())
+		Expect(accessReview.Spec.Groups).To(Equal([]string{"monitoring"}))
+		Expect(accessReview.Spec.NonResourceAttributes.Verb).To(Equal("get"))
+	})
+
+	It("should cache the results of the reviews", func() {
+		Expect(serve("/metrics", "valid").Code).To(Equal(http.StatusOK))
+		Expect(serve("/metrics", "valid").Code).To(Equal(http.StatusOK))
+		Expect(serve("/debug", "valid").Code).To(Equal(http.StatusForbidden))
+		Expect(serve("/debug", "valid").Code).To(Equal(http.StatusForbidden))
+		Expect(serve("/metrics", "invalid").Code).To(Equal(http.StatusUnauthorized))
+		Expect(serve("/metrics", "invalid").Code).To(Equal(http.StatusUnauthorized))
+
+		reviews := map[string]int{}
+		for _, action := range clientset.Actions() {
+			reviews[action.GetResource().Resource]++
+		}
+		Expect(reviews).To(Equal(map[string]int{"tokenreviews": 2, "subjectaccessreviews": 2}))
+	})
+})
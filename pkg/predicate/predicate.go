@@ -18,9 +18,13 @@ package predicate
 
 import (
 	"reflect"
+	"strings"
 
+	"k8s.io/apimachinery/pkg/api/equality"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	logf "sigs.k8s.io/controller-runtime/pkg/internal/log"
@@ -170,6 +174,66 @@ func (GenerationChangedPredicate) Update(e event.UpdateEvent) bool {
 	return e.ObjectNew.GetGeneration() != e.ObjectOld.GetGeneration()
 }
 
+// SpecChangedPredicate implements a default update predicate function on spec change.
+//
+// This predicate will skip update events that have no semantic change in the object's spec field, e.g. resyncs
+// and writes to the metadata (like managedFields) or to the status.  Unlike GenerationChangedPredicate, it
+// doesn't rely on the API server incrementing the Generation of the object, and the compared fields can be
+// set to other fields than the spec, e.g. for objects without spec:
+//
+//  predicate.SpecChangedPredicate{Paths: []string{"data", "binaryData"}}
+type SpecChangedPredicate struct {
+	Funcs
+
+	// Paths are the dot-separated paths of the fields compared, e.g. "spec.replicas".  Defaults to "spec".
+	Paths []string
+}
+
+// Update implements default UpdateEvent filter for validating spec change.
+func (p SpecChangedPredicate) Update(e event.UpdateEvent) bool {
+	if e.ObjectOld == nil {
+		log.Error(nil, "Update event has no old object to update", "event", e)
+		return false
+	}
+	if e.ObjectNew == nil {
+		log.Error(nil, "Update event has no new object for update", "event", e)
+		return false
+	}
+
+	oldContent, err := toUnstructured(e.ObjectOld)
+	if err != nil {
+		log.Error(err, "Could not convert the old object of the update event", "event", e)
+		return true
+	}
+	newContent, err := toUnstructured(e.ObjectNew)
+	if err != nil {
+		log.Error(err, "Could not convert the new object of the update event", "event", e)
+		return true
+	}
+
+	paths := p.Paths
+	if len(paths) == 0 {
+		paths = []string{"spec"}
+	}
+	for _, path := range paths {
+		fields := strings.Split(path, ".")
+		oldValue, _, _ := unstructured.NestedFieldNoCopy(oldContent, fields...)
+		newValue, _, _ := unstructured.NestedFieldNoCopy(newContent, fields...)
+		if !equality.Semantic.DeepEqual(oldValue, newValue) {
+			return true
+		}
+	}
+	return false
+}
+
+// toUnstructured returns the content of obj, without copying it if obj is unstructured.
+func toUnstructured(obj client.Object) (map[string]interface{}, error) {
+	if u, ok := obj.(runtime.Unstructured); ok {
+		return u.UnstructuredContent(), nil
+	}
+	return runtime.DefaultUnstructuredConverter.ToUnstructured(obj)
+}
+
 // AnnotationChangedPredicate implements a default update predicate function on annotation change.
 //
 // This predicate will skip update events that have no change in the object's annotation.
@@ -182,12 +246,20 @@ func (GenerationChangedPredicate) Update(e event.UpdateEvent) bool {
 //
 // This is mostly useful for controllers that needs to trigger both when the resource's generation is incremented
 // (i.e., when the resource' .spec changes), or an annotation changes (e.g., for a staging/alpha API).
+//
+// The annotations compared can be restricted to some keys, so that changes to other annotations, e.g. ones
+// written by other controllers, are skipped:
+//
+//  predicate.AnnotationChangedPredicate{Keys: []string{"example.com/paused"}}
 type AnnotationChangedPredicate struct {
 	Funcs
+
+	// Keys, if not empty, are the keys of the annotations compared.  All the annotations are compared otherwise.
+	Keys []string
 }
 
 // Update implements default UpdateEvent filter for validating annotation change.
-func (AnnotationChangedPredicate) Update(e event.UpdateEvent) bool {
+func (p AnnotationChangedPredicate) Update(e event.UpdateEvent) bool {
 	if e.ObjectOld == nil {
 		log.Error(nil, "Update event has no old object to update", "event", e)
 		return false
@@ -197,7 +269,18 @@ func (AnnotationChangedPredicate) Update(e event.UpdateEvent) bool {
 		return false
 	}
 
-	return !reflect.DeepEqual(e.ObjectNew.GetAnnotations(), e.ObjectOld.GetAnnotations())
+	if len(p.Keys) == 0 {
+		return !reflect.DeepEqual(e.ObjectNew.GetAnnotations(), e.ObjectOld.GetAnnotations())
+	}
+	oldAnnotations, newAnnotations := e.ObjectOld.GetAnnotations(), e.ObjectNew.GetAnnotations()
+	for _, key := range p.Keys {
+		oldValue, oldOk := oldAnnotations[key]
+		newValue, newOk := newAnnotations[key]
+		if oldOk != newOk || oldValue != newValue {
+			return true
+		}
+	}
+	return false
 }
 
 // LabelChangedPredicate implements a default update predicate function on label change.
@@ -320,8 +403,34 @@ func (o or) Generic(e event.GenericEvent) bool {
 	return false
 }
 
+// Not returns a predicate that implements a logical NOT of the predicate passed to it.
+func Not(predicate Predicate) Predicate {
+	return not{predicate}
+}
+
+type not struct {
+	predicate Predicate
+}
+
+func (n not) Create(e event.CreateEvent) bool {
+	return !n.predicate.Create(e)
+}
+
+func (n not) Update(e event.UpdateEvent) bool {
+	return !n.predicate.Update(e)
+}
+
+func (n not) Delete(e event.DeleteEvent) bool {
+	return !n.predicate.Delete(e)
+}
+
+func (n not) Generic(e event.GenericEvent) bool {
+	return !n.predicate.Generic(e)
+}
+
 // LabelSelectorPredicate constructs a Predicate from a LabelSelector.
-// Only objects matching the LabelSelector will be admitted.
+// Only objects matching the LabelSelector will be admitted, for all the event types: the new
+// object of update events must match it.  An error is returned if the LabelSelector is invalid.
 func LabelSelectorPredicate(s metav1.LabelSelector) (Predicate, error) {
 	selector, err := metav1.LabelSelectorAsSelector(&s)
 	if err != nil {
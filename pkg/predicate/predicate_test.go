@@ -19,8 +19,10 @@ package predicate_test
 import (
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
+	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -414,6 +416,69 @@ var _ = Describe("Predicate", func() {
 
 	})
 
+	Describe("When checking a SpecChangedPredicate", func() {
+		instance := predicate.SpecChangedPredicate{}
+		one, two := int32(1), int32(2)
+		old := &appsv1.Deployment{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:            "baz",
+				Namespace:       "biz",
+				ResourceVersion: "1",
+			},
+			Spec: appsv1.DeploymentSpec{Replicas: &one},
+		}
+
+		It("should return false if only the metadata and the status change", func() {
+			new := old.DeepCopy()
+			new.ResourceVersion = "2"
+			new.ManagedFields = []metav1.ManagedFieldsEntry{{Manager: "test"}}
+			new.Status.Replicas = 1
+			Expect(instance.Create(event.CreateEvent{})).To(BeTrue())
+			Expect(instance.Delete(event.DeleteEvent{})).To(BeTrue())
+			Expect(instance.Generic(event.GenericEvent{})).To(BeTrue())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: new})).To(BeFalse())
+		})
+
+		It("should return true if the spec changes", func() {
+			new := old.DeepCopy()
+			new.Spec.Replicas = &two
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: new})).To(BeTrue())
+		})
+
+		It("should return false if the old or the new object is missing", func() {
+			Expect(instance.Update(event.UpdateEvent{ObjectNew: old})).To(BeFalse())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old})).To(BeFalse())
+		})
+
+		It("should compare unstructured objects", func() {
+			oldU := &unstructured.Unstructured{}
+			oldU.SetUnstructuredContent(map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": int64(1)},
+				"status": map[string]interface{}{"replicas": int64(0)},
+			})
+			newU := oldU.DeepCopy()
+			Expect(unstructured.SetNestedField(newU.Object, int64(1), "status", "replicas")).To(Succeed())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: oldU, ObjectNew: newU})).To(BeFalse())
+
+			Expect(unstructured.SetNestedField(newU.Object, int64(2), "spec", "replicas")).To(Succeed())
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: oldU, ObjectNew: newU})).To(BeTrue())
+		})
+
+		It("should only compare the fields of the Paths", func() {
+			instance := predicate.SpecChangedPredicate{Paths: []string{"data"}}
+			oldCM := &corev1.ConfigMap{
+				ObjectMeta: metav1.ObjectMeta{Name: "baz", Namespace: "biz"},
+				Data:       map[string]string{"foo": "bar"},
+			}
+			new := oldCM.DeepCopy()
+			new.BinaryData = map[string][]byte{"foo": []byte("bar")}
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: oldCM, ObjectNew: new})).To(BeFalse())
+
+			new.Data["foo"] = "changed"
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: oldCM, ObjectNew: new})).To(BeTrue())
+		})
+	})
+
 	// AnnotationChangedPredicate has almost identical test cases as LabelChangedPredicates,
 	// so the duplication linter should be muted on both two test suites.
 	// nolint:dupl
@@ -613,6 +678,42 @@ var _ = Describe("Predicate", func() {
 		})
 	})
 
+	Describe("When checking an AnnotationChangedPredicate restricted to some keys", func() {
+		instance := predicate.AnnotationChangedPredicate{Keys: []string{"booz", "zooz"}}
+		old := &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "baz",
+				Namespace: "biz",
+				Annotations: map[string]string{
+					"booz":  "wooz",
+					"other": "value",
+				},
+			}}
+
+		It("should return false if only other annotations change", func() {
+			new := old.DeepCopy()
+			new.Annotations["other"] = "changed"
+			new.Annotations["another"] = "added"
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: new})).To(BeFalse())
+		})
+
+		It("should return true if an annotation of the keys changes", func() {
+			new := old.DeepCopy()
+			new.Annotations["booz"] = "changed"
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: new})).To(BeTrue())
+		})
+
+		It("should return true if an annotation of the keys is added or removed", func() {
+			added := old.DeepCopy()
+			added.Annotations["zooz"] = ""
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: added})).To(BeTrue())
+
+			removed := old.DeepCopy()
+			delete(removed.Annotations, "booz")
+			Expect(instance.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: removed})).To(BeTrue())
+		})
+	})
+
 	// LabelChangedPredicates has almost identical test cases as AnnotationChangedPredicates,
 	// so the duplication linter should be muted on both two test suites.
 	// nolint:dupl
@@ -863,6 +964,22 @@ var _ = Describe("Predicate", func() {
 				Expect(o.Generic(event.GenericEvent{})).To(BeFalse())
 			})
 		})
+		Describe("When checking a Not predicate", func() {
+			It("should return false when its predicate returns true", func() {
+				n := predicate.Not(passFuncs)
+				Expect(n.Create(event.CreateEvent{})).To(BeFalse())
+				Expect(n.Update(event.UpdateEvent{})).To(BeFalse())
+				Expect(n.Delete(event.DeleteEvent{})).To(BeFalse())
+				Expect(n.Generic(event.GenericEvent{})).To(BeFalse())
+			})
+			It("should return true when its predicate returns false", func() {
+				n := predicate.Not(failFuncs)
+				Expect(n.Create(event.CreateEvent{})).To(BeTrue())
+				Expect(n.Update(event.UpdateEvent{})).To(BeTrue())
+				Expect(n.Delete(event.DeleteEvent{})).To(BeTrue())
+				Expect(n.Generic(event.GenericEvent{})).To(BeTrue())
+			})
+		})
 	})
 
 	Describe("NewPredicateFuncs with a namespace filter function", func() {
@@ -944,5 +1061,14 @@ var _ = Describe("Predicate", func() {
 				Expect(instance.Update(event.UpdateEvent{ObjectNew: successMatch})).To(BeTrue())
 			})
 		})
+
+		Context("When the Selector is invalid", func() {
+			It("should return an error", func() {
+				_, err := predicate.LabelSelectorPredicate(metav1.LabelSelector{
+					MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "foo", Operator: "Bogus"}},
+				})
+				Expect(err).To(HaveOccurred())
+			})
+		})
 	})
 })
@@ -16,7 +16,12 @@ limitations under the License.
 
 package ratelimiter
 
-import "time"
+import (
+	"time"
+
+	"golang.org/x/time/rate"
+	"k8s.io/client-go/util/workqueue"
+)
 
 // RateLimiter is an identical interface of client-go workqueue RateLimiter.
 type RateLimiter interface {
@@ -28,3 +33,45 @@ type RateLimiter interface {
 	// NumRequeues returns back how many failures the item has had
 	NumRequeues(item interface{}) int
 }
+
+// Options configures the rate limiter returned by New. Zero values are
+// replaced by the defaults of controllers.
+type Options struct {
+	// BaseDelay is the delay before the first retry of a failing item, which
+	// doubles on every further failure. Defaults to 5 milliseconds.
+	BaseDelay time.Duration
+
+	// MaxDelay caps the delay before the retry of a failing item.
+	// Defaults to 1000 seconds.
+	MaxDelay time.Duration
+
+	// QPS is the overall rate at which items may be retried, shared by all the
+	// items. Defaults to 10.
+	QPS float64
+
+	// Burst is the number of items that may be retried at once, above QPS.
+	// Defaults to 100.
+	Burst int
+}
+
+// New returns a rate limiter working like the default one of controllers, with
+// the given options: an item waits the longest of its exponential backoff and
+// of the overall token bucket.
+func New(opts Options) RateLimiter {
+	if opts.BaseDelay <= 0 {
+		opts.BaseDelay = 5 * time.Millisecond
+	}
+	if opts.MaxDelay <= 0 {
+		opts.MaxDelay = 1000 * time.Second
+	}
+	if opts.QPS <= 0 {
+		opts.QPS = 10
+	}
+	if opts.Burst <= 0 {
+		opts.Burst = 100
+	}
+	return workqueue.NewMaxOfRateLimiter(
+		workqueue.NewItemExponentialFailureRateLimiter(opts.BaseDelay, opts.MaxDelay),
+		&workqueue.BucketRateLimiter{Limiter: rate.NewLimiter(rate.Limit(opts.QPS), opts.Burst)},
+	)
+}
@@ -17,5 +17,10 @@ limitations under the License.
 /*
 Package reconcile defines the Reconciler interface  to implement Kubernetes APIs.  Reconciler is provided
 to Controllers at creation time as the API implementation.
+
+Requests identify objects by namespace and name. Typed reconcilers with custom request types need
+generics, which aren't available with the version of Go this module supports. Meanwhile, controllers
+driven by keys that aren't Kubernetes objects, e.g. the IDs of external resources, can encode them
+in the Name of requests, see the example of Func with a custom key.
 */
 package reconcile
@@ -40,3 +40,17 @@ func ExampleFunc() {
 
 	// Output: Name: test, Namespace: default
 }
+
+// This example implements a Reconciler of resources outside of Kubernetes, identified by
+// keys encoded in the Name of requests.
+func ExampleFunc_customKey() {
+	r := reconcile.Func(func(_ context.Context, o reconcile.Request) (reconcile.Result, error) {
+		// The namespace is left empty, the name is the key of the external resource.
+		fmt.Printf("Bucket: %s", o.Name)
+		return reconcile.Result{}, nil
+	})
+
+	_, _ = r.Reconcile(context.Background(), reconcile.Request{NamespacedName: types.NamespacedName{Name: "eu-west-1/backups"}})
+
+	// Output: Bucket: eu-west-1/backups
+}
@@ -18,6 +18,7 @@ package reconcile
 
 import (
 	"context"
+	"errors"
 	"time"
 
 	"k8s.io/apimachinery/pkg/types"
@@ -90,6 +91,7 @@ type Reconciler interface {
 	// Reconciler performs a full reconciliation for the object referred to by the Request.
 	// The Controller will requeue the Request to be processed again if an error is non-nil or
 	// Result.Requeue is true, otherwise upon completion it will remove the work from the queue.
+	// Errors wrapped with TerminalError are not requeued.
 	Reconcile(context.Context, Request) (Result, error)
 }
 
@@ -100,3 +102,35 @@ var _ Reconciler = Func(nil)
 
 // Reconcile implements Reconciler.
 func (r Func) Reconcile(ctx context.Context, o Request) (Result, error) { return r(ctx, o) }
+
+// TerminalError wraps an error the Reconciler can't recover from by retrying, e.g. when
+// the spec of the object is invalid. The Controller logs terminal errors and counts
+// them in its metrics, but doesn't requeue the Request; it is reconciled again when
+// an event is received for it. Whether an error is terminal can be checked with:
+//
+//  errors.Is(err, reconcile.TerminalError(nil))
+func TerminalError(wrapped error) error {
+	return &terminalError{err: wrapped}
+}
+
+type terminalError struct {
+	err error
+}
+
+// Unwrap returns the wrapped error.
+func (te *terminalError) Unwrap() error {
+	return te.err
+}
+
+func (te *terminalError) Error() string {
+	if te.err == nil {
+		return "terminal error"
+	}
+	return "terminal error: " + te.err.Error()
+}
+
+// Is returns true if target is a terminal error, whatever the error it wraps.
+func (te *terminalError) Is(target error) bool {
+	var tp *terminalError
+	return errors.As(target, &tp)
+}
@@ -18,6 +18,7 @@ package reconcile_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -89,4 +90,18 @@ var _ = Describe("reconcile", func() {
 			Expect(actualErr).To(Equal(err))
 		})
 	})
+
+	Describe("TerminalError", func() {
+		It("should be recognized as a terminal error when wrapped", func() {
+			inner := fmt.Errorf("invalid spec")
+			err := fmt.Errorf("reconciling: %w", reconcile.TerminalError(inner))
+			Expect(errors.Is(err, reconcile.TerminalError(nil))).To(BeTrue())
+			Expect(errors.Is(err, inner)).To(BeTrue())
+			Expect(err.Error()).To(Equal("reconciling: terminal error: invalid spec"))
+		})
+
+		It("should not recognize other errors as terminal errors", func() {
+			Expect(errors.Is(fmt.Errorf("transient"), reconcile.TerminalError(nil))).To(BeFalse())
+		})
+	})
 })
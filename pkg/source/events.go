@@ -0,0 +1,214 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/watch"
+	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+	"k8s.io/client-go/rest"
+	toolscache "k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
+)
+
+// KubernetesEvents returns a Source of GenericEvents for the objects involved in Kubernetes Events
+// (e.g. a Pod which failed to be scheduled), so controllers can react to the Events reported about
+// objects without watching the objects themselves.
+//
+// Only the Events about objects of the involvedKind, or about any object if its Kind is empty, and
+// with one of the reasons, or with any reason if none is given, are observed.  Each creation or update
+// (e.g. a repetition) of such an Event emits a GenericEvent whose Object is a *metav1.PartialObjectMetadata
+// with the apiVersion, kind, namespace, name and uid of the involved object, so that e.g.
+// EnqueueRequestForObject enqueues the involved object:
+//
+//  Watches(source.KubernetesEvents(schema.GroupKind{Kind: "Pod"}, "FailedScheduling"), &handler.EnqueueRequestForObject{})
+//
+// The Events are watched with informers of their own, rather than with the cache of the manager, with a
+// field selector on the kind of the involved object and on the reason, so that only the Events observed are
+// held in memory.  There is an informer for each reason.  The events.k8s.io API serves the same Events as
+// the core API, so they are observed as well.
+//
+// The Events of all namespaces are observed, use KubernetesEventsInNamespace for the managers restricted
+// to a namespace.
+func KubernetesEvents(involvedKind schema.GroupKind, reasons ...string) SyncingSource {
+	return KubernetesEventsInNamespace(metav1.NamespaceAll, involvedKind, reasons...)
+}
+
+// KubernetesEventsInNamespace returns a Source of GenericEvents for the objects involved in the Kubernetes
+// Events of the given namespace, like KubernetesEvents, e.g. for managers whose Namespace option is set and
+// which are only allowed to list and watch the Events of their namespace.
+func KubernetesEventsInNamespace(namespace string, involvedKind schema.GroupKind, reasons ...string) SyncingSource {
+	return &kubernetesEvents{
+		namespace:    namespace,
+		involvedKind: involvedKind,
+		reasons:      sets.NewString(reasons...),
+	}
+}
+
+type kubernetesEvents struct {
+	// namespace is the namespace of the Events observed, or empty for all namespaces
+	namespace string
+
+	// involvedKind is the kind of the objects involved in the Events observed, or empty for any kind
+	involvedKind schema.GroupKind
+
+	// reasons are the reasons of the Events observed, or empty for any reason
+	reasons sets.String
+
+	// config is the config of the clients of the informers, injected by the Controller
+	config *rest.Config
+
+	// informers watch the Events, one for each reason
+	informers []toolscache.SharedIndexInformer
+}
+
+var _ inject.Config = &kubernetesEvents{}
+
+// Start implements Source and should only be called by the Controller.
+func (ke *kubernetesEvents) Start(ctx context.Context, h handler.EventHandler, queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if ke.config == nil {
+		return fmt.Errorf("must call InjectConfig on KubernetesEvents before calling Start")
+	}
+	client, err := typedcorev1.NewForConfig(ke.config)
+	if err != nil {
+		return err
+	}
+
+	emit := func(obj interface{}) {
+		evt, ok := obj.(*corev1.Event)
+		if !ok || !ke.observes(evt) {
+			return
+		}
+		genericEvt := event.GenericEvent{Object: involvedObject(evt)}
+		for _, p := range prct {
+			if !p.Generic(genericEvt) {
+				return
+			}
+		}
+		h.Generic(genericEvt, queue)
+	}
+
+	for _, selector := range ke.fieldSelectors() {
+		selector := selector
+		informer := toolscache.NewSharedIndexInformer(&toolscache.ListWatch{
+			ListFunc: func(opts metav1.ListOptions) (runtime.Object, error) {
+				opts.FieldSelector = selector
+				return client.Events(ke.namespace).List(ctx, opts)
+			},
+			WatchFunc: func(opts metav1.ListOptions) (watch.Interface, error) {
+				opts.FieldSelector = selector
+				return client.Events(ke.namespace).Watch(ctx, opts)
+			},
+		}, &corev1.Event{}, 0, toolscache.Indexers{})
+		informer.AddEventHandler(toolscache.ResourceEventHandlerFuncs{
+			AddFunc: emit,
+			UpdateFunc: func(_, newObj interface{}) {
+				emit(newObj)
+			},
+		})
+		go informer.Run(ctx.Done())
+		ke.informers = append(ke.informers, informer)
+	}
+	return nil
+}
+
+// fieldSelectors returns the field selectors of the informers, one for each reason.
+func (ke *kubernetesEvents) fieldSelectors() []string {
+	reasons := ke.reasons.List()
+	if len(reasons) == 0 {
+		reasons = []string{""}
+	}
+	selectors := make([]string, 0, len(reasons))
+	for _, reason := range reasons {
+		set := fields.Set{}
+		if ke.involvedKind.Kind != "" {
+			set["involvedObject.kind"] = ke.involvedKind.Kind
+		}
+		if reason != "" {
+			set["reason"] = reason
+		}
+		selectors = append(selectors, fields.SelectorFromSet(set).String())
+	}
+	return selectors
+}
+
+// observes returns true if the Event is about an object of the involved kind, with one of the reasons.
+// The field selectors can't select the group of the involved object, since its apiVersion has a version.
+func (ke *kubernetesEvents) observes(evt *corev1.Event) bool {
+	if ke.involvedKind.Kind != "" {
+		gv, err := schema.ParseGroupVersion(evt.InvolvedObject.APIVersion)
+		if err != nil || (schema.GroupKind{Group: gv.Group, Kind: evt.InvolvedObject.Kind}) != ke.involvedKind {
+			return false
+		}
+	}
+	return ke.reasons.Len() == 0 || ke.reasons.Has(evt.Reason)
+}
+
+// involvedObject returns the metadata of the object involved in an Event.
+func involvedObject(evt *corev1.Event) *metav1.PartialObjectMetadata {
+	return &metav1.PartialObjectMetadata{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: evt.InvolvedObject.APIVersion,
+			Kind:       evt.InvolvedObject.Kind,
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Namespace: evt.InvolvedObject.Namespace,
+			Name:      evt.InvolvedObject.Name,
+			UID:       evt.InvolvedObject.UID,
+		},
+	}
+}
+
+// WaitForSync implements SyncingSource.
+func (ke *kubernetesEvents) WaitForSync(ctx context.Context) error {
+	hasSynced := make([]toolscache.InformerSynced, 0, len(ke.informers))
+	for _, informer := range ke.informers {
+		hasSynced = append(hasSynced, informer.HasSynced)
+	}
+	if !toolscache.WaitForCacheSync(ctx.Done(), hasSynced...) {
+		return fmt.Errorf("timed out waiting for the Kubernetes Events to be synced, " +
+			"check that the manager is allowed to list and watch them")
+	}
+	return nil
+}
+
+// InjectConfig is internal should be called only by the Controller.  The Events are watched with
+// clients of the injected config.
+func (ke *kubernetesEvents) InjectConfig(config *rest.Config) error {
+	ke.config = config
+	return nil
+}
+
+func (ke *kubernetesEvents) String() string {
+	if ke.namespace != metav1.NamespaceAll {
+		return fmt.Sprintf("kubernetes events source: %q %v in %q", ke.involvedKind.String(), ke.reasons.List(), ke.namespace)
+	}
+	return fmt.Sprintf("kubernetes events source: %q %v", ke.involvedKind.String(), ke.reasons.List())
+}
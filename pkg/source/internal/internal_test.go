@@ -17,6 +17,10 @@ limitations under the License.
 package internal_test
 
 import (
+	"context"
+	"sync"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"k8s.io/client-go/tools/cache"
@@ -326,6 +330,41 @@ var _ = Describe("Internal", func() {
 	})
 })
 
+var _ = Describe("Resync", func() {
+	It("should periodically resync the objects of the store", func() {
+		var mu sync.Mutex
+		var resynced []string
+		handler := cache.ResourceEventHandlerFuncs{
+			UpdateFunc: func(oldObj, newObj interface{}) {
+				defer GinkgoRecover()
+				Expect(oldObj).To(BeIdenticalTo(newObj))
+				mu.Lock()
+				defer mu.Unlock()
+				resynced = append(resynced, newObj.(*corev1.Pod).Name)
+			},
+		}
+		store := cache.NewStore(cache.MetaNamespaceKeyFunc)
+		Expect(store.Add(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "kept"}})).To(Succeed())
+		deleted := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "deleted"}}
+		Expect(store.Add(deleted)).To(Succeed())
+		Expect(store.Delete(deleted)).To(Succeed())
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go internal.Resync(ctx, handler, store, 10*time.Millisecond)
+
+		Eventually(func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(resynced)
+		}).Should(BeNumerically(">=", 2))
+		mu.Lock()
+		defer mu.Unlock()
+		Expect(resynced).To(ContainElement("kept"))
+		Expect(resynced).NotTo(ContainElement("deleted"))
+	})
+})
+
 type Foo struct{}
 
 var _ runtime.Object = FooRuntimeObject{}
@@ -0,0 +1,50 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package internal
+
+import (
+	"context"
+	"time"
+
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/cache"
+)
+
+// resyncJitterFactor spreads the resyncs of the sources sharing a period, so
+// that they don't all flood their queues at once.
+const resyncJitterFactor = 0.1
+
+// Resync calls OnUpdate on handler with every object of store, the store of the
+// informer of handler, every period until ctx is done, like the resyncs of an
+// informer. Unlike the resync period of an informer, period doesn't depend on the
+// other handlers of the informer, nor on whether the informer is already started.
+// Up to 10 percent of jitter is added to every period.
+func Resync(ctx context.Context, handler cache.ResourceEventHandler, store cache.Store, period time.Duration) {
+	for {
+		timer := time.NewTimer(wait.Jitter(period, resyncJitterFactor))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		for _, obj := range store.List() {
+			handler.OnUpdate(obj, obj)
+		}
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package source
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/util/workqueue"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/source/internal"
+)
+
+// ListFunc lists the objects of a system outside the cluster (e.g. the instances of a cloud API)
+// for a Poller.
+type ListFunc func(context.Context) ([]client.Object, error)
+
+// Poller returns a Source of events for objects originating outside the cluster, which runs list every
+// interval and compares the objects it lists with the objects of the previous run:
+//
+// * objects which weren't listed before are Created.
+//
+// * objects which are listed again but aren't semantically equal to their previous version are Updated.
+//
+// * objects which aren't listed anymore are Deleted.
+//
+// Objects are identified by their type, namespace and name.  When list fails, the error is logged and
+// the objects of the previous run are kept until the next run.
+func Poller(interval time.Duration, list ListFunc) Source {
+	return &poller{interval: interval, list: list}
+}
+
+var _ Source = &poller{}
+
+type poller struct {
+	// interval is the time between two runs of list
+	interval time.Duration
+
+	// list lists the current objects
+	list ListFunc
+}
+
+func (p *poller) String() string {
+	return fmt.Sprintf("poller source: %p", p)
+}
+
+// Start implements Source and should only be called by the Controller.
+func (p *poller) Start(ctx context.Context, handler handler.EventHandler, queue workqueue.RateLimitingInterface,
+	prct ...predicate.Predicate) error {
+	if p.list == nil {
+		return fmt.Errorf("must specify the list function of the Poller")
+	}
+	if p.interval <= 0 {
+		return fmt.Errorf("must specify a positive interval for the Poller, got %v", p.interval)
+	}
+
+	eventHandler := internal.EventHandler{Queue: queue, EventHandler: handler, Predicates: prct}
+	previous := map[objectKey]client.Object{}
+	go wait.UntilWithContext(ctx, func(ctx context.Context) {
+		previous = p.poll(ctx, eventHandler, previous)
+	}, p.interval)
+
+	return nil
+}
+
+// poll lists the current objects, emits the events of the differences with the previous
+// objects, and returns the current objects.
+func (p *poller) poll(ctx context.Context, eventHandler internal.EventHandler, previous map[objectKey]client.Object) map[objectKey]client.Object {
+	objs, err := p.list(ctx)
+	if err != nil {
+		log.Error(err, "Failed to list the objects of the poller source", "source", p)
+		return previous
+	}
+
+	current := make(map[objectKey]client.Object, len(objs))
+	for _, obj := range objs {
+		key := objectKeyOf(obj)
+		current[key] = obj
+
+		old, ok := previous[key]
+		switch {
+		case !ok:
+			eventHandler.OnAdd(obj)
+		case !equality.Semantic.DeepEqual(old, obj):
+			eventHandler.OnUpdate(old, obj)
+		}
+	}
+	for key, old := range previous {
+		if _, ok := current[key]; !ok {
+			eventHandler.OnDelete(old)
+		}
+	}
+	return current
+}
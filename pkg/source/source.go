@@ -21,8 +21,10 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"time"
 
 	"k8s.io/apimachinery/pkg/api/meta"
+	toolscache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
@@ -89,6 +91,18 @@ type Kind struct {
 	// Type is the type of object to watch.  e.g. &v1.Pod{}
 	Type client.Object
 
+	// ResyncPeriod, if positive, is the period at which update events are sent for all
+	// the objects of the source, whatever the sync period of the cache, with up to
+	// 10 percent of jitter so that sources don't resync all at once. The old and new
+	// objects of these events are the same, so predicates filtering updates without
+	// changes, like GenerationChangedPredicate, filter them as well.
+	ResyncPeriod time.Duration
+
+	// SyncTimeout, if positive, bounds the time WaitForSync waits for the informer of the
+	// source to sync, whatever the deadline of its context.  The informer never syncs when
+	// the CRD of the type isn't installed or the manager isn't allowed to list and watch it.
+	SyncTimeout time.Duration
+
 	// cache used to watch APIs
 	cache cache.Cache
 
@@ -127,13 +141,23 @@ func (ks *Kind) Start(ctx context.Context, handler handler.EventHandler, queue w
 				log.Error(err, "if kind is a CRD, it should be installed before calling Start",
 					"kind", kindMatchErr.GroupKind)
 			}
-			ks.started <- err
+			ks.started <- fmt.Errorf("failed to get the informer for Kind %T: %w", ks.Type, err)
 			return
 		}
-		i.AddEventHandler(internal.EventHandler{Queue: queue, EventHandler: handler, Predicates: prct})
+		h := internal.EventHandler{Queue: queue, EventHandler: handler, Predicates: prct}
+		withStore, hasStore := i.(interface{ GetStore() toolscache.Store })
+		switch {
+		case ks.ResyncPeriod > 0 && hasStore:
+			i.AddEventHandler(h)
+			go internal.Resync(ctx, h, withStore.GetStore(), ks.ResyncPeriod)
+		case ks.ResyncPeriod > 0:
+			// the informer resyncs the handler itself, at most as often as its other handlers
+			i.AddEventHandlerWithResyncPeriod(h, ks.ResyncPeriod)
+		default:
+			i.AddEventHandler(h)
+		}
 		if !ks.cache.WaitForCacheSync(ctx) {
-			// Would be great to return something more informative here
-			ks.started <- errors.New("cache did not sync")
+			ks.started <- fmt.Errorf("cache did not sync for Kind %T", ks.Type)
 		}
 		close(ks.started)
 	}()
@@ -151,12 +175,19 @@ func (ks *Kind) String() string {
 // WaitForSync implements SyncingSource to allow controllers to wait with starting
 // workers until the cache is synced.
 func (ks *Kind) WaitForSync(ctx context.Context) error {
+	if ks.SyncTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, ks.SyncTimeout)
+		defer cancel()
+	}
+
 	select {
 	case err := <-ks.started:
 		return err
 	case <-ctx.Done():
 		ks.startCancel()
-		return errors.New("timed out waiting for cache to be synced")
+		return fmt.Errorf("timed out waiting for cache to be synced for Kind %T, "+
+			"check that its CRD is installed and that the manager is allowed to list and watch it", ks.Type)
 	}
 }
 
@@ -187,12 +218,21 @@ type Channel struct {
 	stop <-chan struct{}
 
 	// dest is the destination channels of the added event handlers
-	dest []chan event.GenericEvent
+	dest []*channelDest
 
 	// DestBufferSize is the specified buffer size of dest channels.
 	// Default to 1024 if not specified.
 	DestBufferSize int
 
+	// DropWhenFull drops the events which don't fit in the dest channel of an event handler
+	// which is too slow to handle them.  By default, the distribution of the events to all the
+	// event handlers blocks until the slow event handler catches up.
+	DropWhenFull bool
+
+	// Deduplicate skips the events whose object is already in the dest channel of an event
+	// handler, waiting to be handled.  Objects are identified by their type, namespace and name.
+	Deduplicate bool
+
 	// destLock is to ensure the destination channels are safely added/removed
 	destLock sync.Mutex
 }
@@ -234,7 +274,10 @@ func (cs *Channel) Start(
 		cs.DestBufferSize = defaultBufferSize
 	}
 
-	dst := make(chan event.GenericEvent, cs.DestBufferSize)
+	dst := &channelDest{events: make(chan event.GenericEvent, cs.DestBufferSize)}
+	if cs.Deduplicate {
+		dst.pending = map[objectKey]struct{}{}
+	}
 
 	cs.destLock.Lock()
 	cs.dest = append(cs.dest, dst)
@@ -246,7 +289,9 @@ func (cs *Channel) Start(
 	})
 
 	go func() {
-		for evt := range dst {
+		for evt := range dst.events {
+			dst.release(evt)
+
 			shouldHandle := true
 			for _, p := range prct {
 				if !p.Generic(evt) {
@@ -269,7 +314,7 @@ func (cs *Channel) doStop() {
 	defer cs.destLock.Unlock()
 
 	for _, dst := range cs.dest {
-		close(dst)
+		close(dst.events)
 	}
 }
 
@@ -278,15 +323,79 @@ func (cs *Channel) distribute(evt event.GenericEvent) {
 	defer cs.destLock.Unlock()
 
 	for _, dst := range cs.dest {
+		if !dst.reserve(evt) {
+			// the object of the event is already waiting to be handled
+			continue
+		}
+
+		if cs.DropWhenFull {
+			select {
+			case dst.events <- evt:
+			default:
+				dst.release(evt)
+				log.V(1).Info("Dropping an event for a slow event handler", "source", cs)
+			}
+			continue
+		}
+
 		// We cannot make it under goroutine here, or we'll meet the
 		// race condition of writing message to closed channels.
 		// To avoid blocking, the dest channels are expected to be of
 		// proper buffer size. If we still see it blocked, then
 		// the controller is thought to be in an abnormal state.
-		dst <- evt
+		dst.events <- evt
 	}
 }
 
+// objectKey identifies an object by its type, namespace and name.
+type objectKey struct {
+	objectType string
+	client.ObjectKey
+}
+
+func objectKeyOf(obj client.Object) objectKey {
+	return objectKey{objectType: fmt.Sprintf("%T", obj), ObjectKey: client.ObjectKeyFromObject(obj)}
+}
+
+// channelDest is the destination channel of an event handler added to a Channel.
+type channelDest struct {
+	events chan event.GenericEvent
+
+	// pending holds the objects of the events in the channel when deduplicating them
+	pending map[objectKey]struct{}
+
+	// pendingLock guards pending, which is updated by the distribution and the handling
+	pendingLock sync.Mutex
+}
+
+// reserve records the object of evt as pending, and returns false if it is already pending.
+func (d *channelDest) reserve(evt event.GenericEvent) bool {
+	if d.pending == nil || evt.Object == nil {
+		return true
+	}
+	key := objectKeyOf(evt.Object)
+
+	d.pendingLock.Lock()
+	defer d.pendingLock.Unlock()
+	if _, ok := d.pending[key]; ok {
+		return false
+	}
+	d.pending[key] = struct{}{}
+	return true
+}
+
+// release records the object of evt as handled, so later events for the object are distributed again.
+func (d *channelDest) release(evt event.GenericEvent) {
+	if d.pending == nil || evt.Object == nil {
+		return
+	}
+	key := objectKeyOf(evt.Object)
+
+	d.pendingLock.Lock()
+	defer d.pendingLock.Unlock()
+	delete(d.pending, key)
+}
+
 func (cs *Channel) syncLoop(ctx context.Context) {
 	for {
 		select {
@@ -23,6 +23,7 @@ import (
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 	"sigs.k8s.io/controller-runtime/pkg/runtime/inject"
 	"sigs.k8s.io/controller-runtime/pkg/source"
 
@@ -31,6 +32,7 @@ import (
 	appsv1 "k8s.io/api/apps/v1"
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
 	kubeinformers "k8s.io/client-go/informers"
 	toolscache "k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/util/workqueue"
@@ -358,4 +360,94 @@ var _ = Describe("Source", func() {
 			})
 		})
 	})
+
+	Describe("KubernetesEvents", func() {
+		It("should provide GenericEvents for the objects involved in the matching Events", func(done Done) {
+			received := make(chan client.Object, 10)
+			instance := source.KubernetesEvents(schema.GroupKind{Kind: "Pod"}, "FailedScheduling")
+			Expect(inject.ConfigInto(config, instance)).To(BeTrue())
+			err := instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(evt event.GenericEvent, q2 workqueue.RateLimitingInterface) {
+					defer GinkgoRecover()
+					Expect(q2).To(Equal(q))
+					received <- evt.Object
+				},
+			}, q, predicate.NewPredicateFuncs(func(obj client.Object) bool {
+				return obj.GetName() != "filtered"
+			}))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance.WaitForSync(ctx)).To(Succeed())
+
+			newEvent := func(apiVersion, kind, name, reason string) *corev1.Event {
+				return &corev1.Event{
+					ObjectMeta: metav1.ObjectMeta{Namespace: ns, GenerateName: name + "."},
+					InvolvedObject: corev1.ObjectReference{
+						APIVersion: apiVersion,
+						Kind:       kind,
+						Namespace:  ns,
+						Name:       name,
+						UID:        "uid",
+					},
+					Reason: reason,
+				}
+			}
+			events := clientset.CoreV1().Events(ns)
+			for _, evt := range []*corev1.Event{
+				newEvent("v1", "Node", "foo", "FailedScheduling"),
+				newEvent("example.com/v1", "Pod", "foo", "FailedScheduling"),
+				newEvent("v1", "Pod", "foo", "Scheduled"),
+				newEvent("v1", "Pod", "filtered", "FailedScheduling"),
+			} {
+				_, err := events.Create(ctx, evt, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+			failed, err := events.Create(ctx, newEvent("v1", "Pod", "foo", "FailedScheduling"), metav1.CreateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+			failed.Count = 2
+			_, err = events.Update(ctx, failed, metav1.UpdateOptions{})
+			Expect(err).NotTo(HaveOccurred())
+
+			expected := &metav1.PartialObjectMetadata{
+				TypeMeta:   metav1.TypeMeta{APIVersion: "v1", Kind: "Pod"},
+				ObjectMeta: metav1.ObjectMeta{Namespace: ns, Name: "foo", UID: "uid"},
+			}
+			Eventually(received).Should(Receive(Equal(expected)))
+			Eventually(received).Should(Receive(Equal(expected)))
+			Consistently(received).ShouldNot(Receive())
+			close(done)
+		}, 30)
+
+		It("should only provide GenericEvents for the Events of its namespace", func(done Done) {
+			received := make(chan client.Object, 10)
+			instance := source.KubernetesEventsInNamespace(ns, schema.GroupKind{Kind: "Pod"})
+			Expect(inject.ConfigInto(config, instance)).To(BeTrue())
+			err := instance.Start(ctx, handler.Funcs{
+				GenericFunc: func(evt event.GenericEvent, _ workqueue.RateLimitingInterface) {
+					received <- evt.Object
+				},
+			}, q)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(instance.WaitForSync(ctx)).To(Succeed())
+
+			for _, namespace := range []string{"default", ns} {
+				_, err := clientset.CoreV1().Events(namespace).Create(ctx, &corev1.Event{
+					ObjectMeta: metav1.ObjectMeta{Namespace: namespace, GenerateName: "foo."},
+					InvolvedObject: corev1.ObjectReference{
+						APIVersion: "v1",
+						Kind:       "Pod",
+						Namespace:  namespace,
+						Name:       "foo",
+					},
+					Reason: "Scheduled",
+				}, metav1.CreateOptions{})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			var obj client.Object
+			Eventually(received).Should(Receive(&obj))
+			Expect(obj.GetNamespace()).To(Equal(ns))
+			Consistently(received).ShouldNot(Receive())
+			close(done)
+		}, 30)
+	})
 })
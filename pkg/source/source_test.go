@@ -19,10 +19,12 @@ package source_test
 import (
 	"context"
 	"fmt"
+	"time"
 
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"sigs.k8s.io/controller-runtime/pkg/cache/informertest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/predicate"
@@ -217,12 +219,21 @@ var _ = Describe("Source", func() {
 			Expect(instance.Start(context.Background(), nil, nil)).NotTo(HaveOccurred())
 			err := instance.WaitForSync(context.Background())
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("cache did not sync"))
+			Expect(err.Error()).To(Equal("cache did not sync for Kind *v1.Pod"))
 
 			close(done)
 
 		})
 
+		It("should time out waiting for the cache to sync after SyncTimeout", func() {
+			instance := source.Kind{Type: &corev1.Pod{}, SyncTimeout: 10 * time.Millisecond}
+			Expect(instance.InjectCache(&unsyncedCache{FakeInformers: &informertest.FakeInformers{}})).To(Succeed())
+			Expect(instance.Start(context.Background(), nil, nil)).NotTo(HaveOccurred())
+			err := instance.WaitForSync(context.Background())
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("timed out waiting for cache to be synced for Kind *v1.Pod"))
+		})
+
 		Context("for a Kind not in the cache", func() {
 			It("should return an error when WaitForSync is called", func(done Done) {
 				ic.Error = fmt.Errorf("test error")
@@ -234,7 +245,9 @@ var _ = Describe("Source", func() {
 				Expect(instance.InjectCache(ic)).To(Succeed())
 				err := instance.Start(ctx, handler.Funcs{}, q)
 				Expect(err).NotTo(HaveOccurred())
-				Expect(instance.WaitForSync(context.Background())).To(HaveOccurred())
+				err = instance.WaitForSync(context.Background())
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("failed to get the informer for Kind *v1.Pod: test error"))
 
 				close(done)
 			})
@@ -255,7 +268,7 @@ var _ = Describe("Source", func() {
 			Expect(instance.Start(context.Background(), nil, nil)).NotTo(HaveOccurred())
 			err := instance.WaitForSync(context.Background())
 			Expect(err).To(HaveOccurred())
-			Expect(err.Error()).To(Equal("cache did not sync"))
+			Expect(err.Error()).To(Equal("cache did not sync for Kind *v1.Pod"))
 
 			close(done)
 
@@ -288,6 +301,84 @@ var _ = Describe("Source", func() {
 		})
 	})
 
+	Describe("Poller", func() {
+		var ctx context.Context
+		var cancel context.CancelFunc
+		var results chan []client.Object
+		var list source.ListFunc
+
+		BeforeEach(func() {
+			ctx, cancel = context.WithCancel(context.Background())
+			results = make(chan []client.Object)
+			var last []client.Object
+			list = func(ctx context.Context) ([]client.Object, error) {
+				select {
+				case last = <-results:
+				case <-ctx.Done():
+				}
+				return last, nil
+			}
+		})
+
+		AfterEach(func() {
+			cancel()
+		})
+
+		It("should emit events for the differences between the listed objects", func() {
+			events := make(chan string, 10)
+			q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+			instance := source.Poller(10*time.Millisecond, list)
+			err := instance.Start(ctx, handler.Funcs{
+				CreateFunc: func(evt event.CreateEvent, _ workqueue.RateLimitingInterface) {
+					events <- "create " + evt.Object.GetName()
+				},
+				UpdateFunc: func(evt event.UpdateEvent, _ workqueue.RateLimitingInterface) {
+					events <- "update " + evt.ObjectOld.GetName() + " " + evt.ObjectNew.GetResourceVersion()
+				},
+				DeleteFunc: func(evt event.DeleteEvent, _ workqueue.RateLimitingInterface) {
+					events <- "delete " + evt.Object.GetName()
+				},
+			}, q)
+			Expect(err).NotTo(HaveOccurred())
+
+			foo := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "default", ResourceVersion: "1"}}
+			bar := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "bar", Namespace: "default"}}
+			baz := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "baz", Namespace: "default"}}
+
+			results <- []client.Object{foo, bar}
+			Eventually(events).Should(Receive(Equal("create foo")))
+			Eventually(events).Should(Receive(Equal("create bar")))
+
+			newFoo := foo.DeepCopy()
+			newFoo.ResourceVersion = "2"
+			results <- []client.Object{newFoo, baz}
+			var received []string
+			for i := 0; i < 3; i++ {
+				var evt string
+				Eventually(events).Should(Receive(&evt))
+				received = append(received, evt)
+			}
+			Expect(received).To(ConsistOf("update foo 2", "create baz", "delete bar"))
+
+			results <- []client.Object{newFoo.DeepCopy(), baz.DeepCopy()}
+			Consistently(events).ShouldNot(Receive())
+		})
+
+		It("should get error without a list function", func() {
+			q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+			instance := source.Poller(time.Second, nil)
+			err := instance.Start(ctx, handler.Funcs{}, q)
+			Expect(err).To(MatchError("must specify the list function of the Poller"))
+		})
+
+		It("should get error without a positive interval", func() {
+			q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+			instance := source.Poller(0, list)
+			err := instance.Start(ctx, handler.Funcs{}, q)
+			Expect(err).To(HaveOccurred())
+		})
+	})
+
 	Describe("Channel", func() {
 		var ctx context.Context
 		var cancel context.CancelFunc
@@ -415,6 +506,85 @@ var _ = Describe("Source", func() {
 
 				close(done)
 			})
+			It("should drop the events which don't fit in the buffer with DropWhenFull", func() {
+				ch := make(chan event.GenericEvent)
+				started := make(chan struct{})
+				unblock := make(chan struct{})
+				handled := make(chan event.GenericEvent, 5)
+				evt := event.GenericEvent{}
+
+				q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+				instance := &source.Channel{Source: ch, DestBufferSize: 1, DropWhenFull: true}
+				Expect(inject.StopChannelInto(ctx.Done(), instance)).To(BeTrue())
+				err := instance.Start(ctx, handler.Funcs{
+					GenericFunc: func(evt event.GenericEvent, q2 workqueue.RateLimitingInterface) {
+						// Block for the first time
+						if len(handled) == 0 {
+							close(started)
+							<-unblock
+						}
+						handled <- evt
+					},
+				}, q)
+				Expect(err).NotTo(HaveOccurred())
+
+				// The 1st event blocks the handler, the 2nd fills the buffer, and the
+				// 3rd and 4th are dropped without blocking the distribution.
+				ch <- evt
+				<-started
+				ch <- evt
+				ch <- evt
+				ch <- evt
+				ch <- evt
+				close(unblock)
+
+				Eventually(func() int { return len(handled) }).Should(BeNumerically(">=", 2))
+				Consistently(func() int { return len(handled) }).Should(BeNumerically("<=", 3))
+			})
+			It("should skip the events whose object is waiting to be handled with Deduplicate", func() {
+				ch := make(chan event.GenericEvent)
+				started := make(chan struct{})
+				unblock := make(chan struct{})
+				handled := make(chan string, 10)
+				foo := event.GenericEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "foo", Namespace: "bar"}}}
+				baz := event.GenericEvent{Object: &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "baz", Namespace: "bar"}}}
+
+				q := workqueue.NewNamedRateLimitingQueue(workqueue.DefaultControllerRateLimiter(), "test")
+				instance := &source.Channel{Source: ch, Deduplicate: true}
+				Expect(inject.StopChannelInto(ctx.Done(), instance)).To(BeTrue())
+				err := instance.Start(ctx, handler.Funcs{
+					GenericFunc: func(evt event.GenericEvent, q2 workqueue.RateLimitingInterface) {
+						// Block for the first time
+						if len(handled) == 0 {
+							close(started)
+							<-unblock
+						}
+						if evt.Object == nil {
+							handled <- ""
+							return
+						}
+						handled <- evt.Object.GetName()
+					},
+				}, q)
+				Expect(err).NotTo(HaveOccurred())
+
+				ch <- foo
+				<-started
+				ch <- foo
+				ch <- foo
+				ch <- baz
+				ch <- foo
+				// Events without an object are never deduplicated, and receiving this one
+				// ensures the previous events have been distributed.
+				ch <- event.GenericEvent{}
+				close(unblock)
+
+				Eventually(handled).Should(Receive(Equal("foo")))
+				Eventually(handled).Should(Receive(Equal("foo")))
+				Eventually(handled).Should(Receive(Equal("baz")))
+				Eventually(handled).Should(Receive(Equal("")))
+				Consistently(handled).ShouldNot(Receive())
+			})
 			It("should be able to cope with events in the channel before the source is started", func(done Done) {
 				ch := make(chan event.GenericEvent, 1)
 				processed := make(chan struct{})
@@ -586,3 +756,13 @@ var _ = Describe("Source", func() {
 		})
 	})
 })
+
+// unsyncedCache never syncs, like a cache watching a type without its CRD.
+type unsyncedCache struct {
+	*informertest.FakeInformers
+}
+
+func (c *unsyncedCache) WaitForCacheSync(ctx context.Context) bool {
+	<-ctx.Done()
+	return false
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admission
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"gomodules.xyz/jsonpatch/v2"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("defaulterForType", func() {
+	webhook := WithCustomDefaulter(&corev1.Pod{}, &podDefaulter{})
+	Expect(webhook.InjectScheme(scheme.Scheme)).To(Succeed())
+
+	It("should patch the object with its defaults", func() {
+		response := webhook.Handle(context.TODO(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"pod"}}`)},
+		}})
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Patches).To(ContainElement(jsonpatch.JsonPatchOperation{
+			Operation: "add",
+			Path:      "/metadata/labels",
+			Value:     map[string]interface{}{"defaulted": "true"},
+		}))
+	})
+
+	It("should deny the object if defaulting fails", func() {
+		response := webhook.Handle(context.TODO(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"invalid"}}`)},
+		}})
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Code).To(Equal(int32(http.StatusForbidden)))
+	})
+})
+
+// podDefaulter labels the pods it defaults.
+type podDefaulter struct{}
+
+func (*podDefaulter) Default(_ context.Context, obj runtime.Object) error {
+	pod := obj.(*corev1.Pod)
+	if pod.Name == "invalid" {
+		return errors.New("invalid name")
+	}
+	pod.Labels = map[string]string{"defaulted": "true"}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2021 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+package admission
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+
+	admissionv1 "k8s.io/api/admission/v1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/kubernetes/scheme"
+)
+
+var _ = Describe("validatorForType", func() {
+	decoder, _ := NewDecoder(scheme.Scheme)
+	validator := &podValidator{}
+	webhook := WithCustomValidator(&corev1.Pod{}, validator)
+	Expect(webhook.InjectScheme(scheme.Scheme)).To(Succeed())
+
+	podRaw := func(name string) runtime.RawExtension {
+		return runtime.RawExtension{Raw: []byte(`{"apiVersion":"v1","kind":"Pod","metadata":{"name":"` + name + `"}}`)}
+	}
+
+	It("should validate the objects of a create", func() {
+		response := webhook.Handle(context.TODO(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    podRaw("valid"),
+		}})
+		Expect(response.Allowed).To(BeTrue())
+		Expect(response.Result.Code).To(Equal(int32(http.StatusOK)))
+		Expect(validator.validated).To(Equal([]string{"create valid"}))
+
+		response = webhook.Handle(context.TODO(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    podRaw("invalid"),
+		}})
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Code).To(Equal(int32(http.StatusForbidden)))
+		Expect(string(response.Result.Reason)).To(Equal("invalid name"))
+	})
+
+	It("should validate the old and new objects of an update", func() {
+		validator.validated = nil
+		response := webhook.Handle(context.TODO(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Update,
+			Object:    podRaw("new"),
+			OldObject: podRaw("old"),
+		}})
+		Expect(response.Allowed).To(BeTrue())
+		Expect(validator.validated).To(Equal([]string{"update old new"}))
+	})
+
+	It("should propagate the Status of the error of a delete", func() {
+		validator.validated = nil
+		response := webhook.Handle(context.TODO(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Delete,
+			OldObject: podRaw("protected"),
+		}})
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Code).To(Equal(int32(http.StatusConflict)))
+		Expect(validator.validated).To(Equal([]string{"delete protected"}))
+	})
+
+	It("should not decode the objects of another type", func() {
+		handler := &validatorForType{object: &corev1.Pod{}, validator: validator, decoder: decoder}
+		response := handler.Handle(context.TODO(), Request{AdmissionRequest: admissionv1.AdmissionRequest{
+			Operation: admissionv1.Create,
+			Object:    runtime.RawExtension{Raw: []byte("{")},
+		}})
+		Expect(response.Allowed).To(BeFalse())
+		Expect(response.Result.Code).To(Equal(int32(http.StatusBadRequest)))
+	})
+})
+
+// podValidator records the names of the pods it validates.
+type podValidator struct {
+	validated []string
+}
+
+func (v *podValidator) ValidateCreate(_ context.Context, obj runtime.Object) error {
+	name := obj.(*corev1.Pod).Name
+	v.validated = append(v.validated, "create "+name)
+	if name == "invalid" {
+		return errors.New("invalid name")
+	}
+	return nil
+}
+
+func (v *podValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) error {
+	v.validated = append(v.validated, "update "+oldObj.(*corev1.Pod).Name+" "+newObj.(*corev1.Pod).Name)
+	return nil
+}
+
+func (v *podValidator) ValidateDelete(_ context.Context, obj runtime.Object) error {
+	name := obj.(*corev1.Pod).Name
+	v.validated = append(v.validated, "delete "+name)
+	return apierrors.NewConflict(schema.GroupResource{Resource: "pods"}, name, errors.New("protected"))
+}